@@ -15,9 +15,9 @@ const docTemplate = `{
     "host": "{{.Host}}",
     "basePath": "{{.BasePath}}",
     "paths": {
-        "/users": {
+        "/admin/attributes/schema": {
             "get": {
-                "description": "Retrieve a paginated list of user profiles",
+                "description": "Retrieve the JSON Schema that custom profile attributes are validated against",
                 "consumes": [
                     "application/json"
                 ],
@@ -25,40 +25,32 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Get a paginated list of users",
-                "parameters": [
-                    {
-                        "type": "integer",
-                        "description": "Page number",
-                        "name": "page",
-                        "in": "query"
-                    },
-                    {
-                        "type": "integer",
-                        "description": "Items per page",
-                        "name": "limit",
-                        "in": "query"
-                    }
+                    "attributes"
                 ],
+                "summary": "Get attributes schema",
                 "responses": {
                     "200": {
-                        "description": "Paginated list of user profiles",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.GetUserListResp"
+                            "type": "object"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
                     "500": {
-                        "description": "Failed to get users list",
+                        "description": "Failed to get attributes schema",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     }
                 }
             },
-            "post": {
-                "description": "Create a new user profile with the provided information",
+            "put": {
+                "description": "Replace the JSON Schema that custom profile attributes are validated against",
                 "consumes": [
                     "application/json"
                 ],
@@ -66,35 +58,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "attributes"
                 ],
-                "summary": "Create a user profile",
+                "summary": "Set attributes schema",
                 "parameters": [
                     {
-                        "description": "User profile details",
-                        "name": "user",
+                        "description": "JSON Schema",
+                        "name": "schema",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.CreateUserReq"
+                            "type": "object"
                         }
                     }
                 ],
                 "responses": {
-                    "201": {
-                        "description": "Created",
+                    "200": {
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.CreateUserResp"
+                            "$ref": "#/definitions/domain.MessageResp"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Invalid schema",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
                     "500": {
-                        "description": "Failed to create user profile",
+                        "description": "Failed to update attributes schema",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
@@ -102,9 +94,9 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/login": {
-            "post": {
-                "description": "Log in with the provided credentials and generate a JWT token",
+        "/admin/audit": {
+            "get": {
+                "description": "Retrieve a paginated list of changes made to profiles, groups and invites of the organization, most recent first. Only admins are permitted to view the audit log.",
                 "consumes": [
                     "application/json"
                 ],
@@ -112,35 +104,68 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Log in and generate JWT token",
+                "summary": "Get audit log",
                 "parameters": [
                     {
-                        "description": "User credentials",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.LoginReq"
-                        }
+                        "type": "string",
+                        "description": "ID of the user that made the change",
+                        "name": "actor",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "ID of the changed user or group, invites are audited under the user that created them",
+                        "name": "target",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Action, e.g. update, password, delete, set_role, group_member_add",
+                        "name": "action",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, RFC 3339",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, exclusive, RFC 3339",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.LoginResp"
+                            "$ref": "#/definitions/domain.AuditPage"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
-                    "401": {
-                        "description": "Failed to log in",
+                    "500": {
+                        "description": "Failed to get audit log",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
@@ -148,51 +173,42 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}": {
+        "/admin/organizations": {
             "get": {
-                "description": "Retrieve user details by the provided user ID",
-                "consumes": [
-                    "application/json"
-                ],
+                "description": "List all organizations. Only admins of the default organization are permitted to list organizations.",
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "users"
-                ],
-                "summary": "Get user by ID",
-                "parameters": [
-                    {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    }
+                    "admin"
                 ],
+                "summary": "Get organizations",
                 "responses": {
                     "200": {
-                        "description": "User profile details",
+                        "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.GetUserResp"
+                            "type": "array",
+                            "items": {
+                                "$ref": "#/definitions/domain.Organization"
+                            }
                         }
                     },
                     "400": {
-                        "description": "Wrong UserId",
+                        "description": "Bad Request",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
                     "500": {
-                        "description": "Failed to get user profile",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     }
                 }
             },
-            "put": {
-                "description": "Update an existing user profile with the provided information",
+            "post": {
+                "description": "Create an organization along with its first admin. Only admins of the default organization are permitted to create organizations.",
                 "consumes": [
                     "application/json"
                 ],
@@ -200,50 +216,51 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Update user profile",
+                "summary": "Create organization",
                 "parameters": [
                     {
-                        "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
-                    },
-                    {
-                        "description": "User credentials",
-                        "name": "user",
+                        "description": "Organization and its admin",
+                        "name": "organization",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.UpdateUserReq"
+                            "$ref": "#/definitions/domain.CreateOrganizationReq"
                         }
                     }
                 ],
                 "responses": {
-                    "200": {
-                        "description": "OK",
+                    "201": {
+                        "description": "Created",
                         "schema": {
-                            "$ref": "#/definitions/domain.MessageResp"
+                            "$ref": "#/definitions/domain.Organization"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Organization already exists",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
                     "500": {
-                        "description": "Failed to update user profile",
+                        "description": "Internal Server Error",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     }
                 }
-            },
-            "delete": {
-                "description": "Delete a user profile by the provided user ID",
+            }
+        },
+        "/admin/users/batch": {
+            "post": {
+                "description": "Ban, unban, delete or set role of many users at once, listed by oid or matched by a filter.\nModerators can ban, unban and delete users with user role only, setting roles is permitted to admins only.\nThe batch runs in a single transaction, chunked batches run in a transaction per chunk and report progress per chunk.",
                 "consumes": [
                     "application/json"
                 ],
@@ -251,24 +268,35 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
+                ],
+                "summary": "Batch action on users",
+                "parameters": [
+                    {
+                        "description": "Action and its targets",
+                        "name": "batch",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.BatchReq"
+                        }
+                    }
                 ],
-                "summary": "Delete user by ID",
                 "responses": {
                     "200": {
-                        "description": "Profile successfully deleted",
+                        "description": "OK",
                         "schema": {
-                            "type": "string"
+                            "$ref": "#/definitions/domain.BatchResultDTO"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
+                        "description": "Invalid request payload",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
                     "500": {
-                        "description": "Internal Server Error",
+                        "description": "Failed to apply batch action",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
@@ -276,52 +304,108 @@ const docTemplate = `{
                 }
             }
         },
-        "/users/{id}/password": {
-            "put": {
-                "description": "Update the password for the authenticated user or admin",
+        "/admin/users/export": {
+            "get": {
+                "description": "Stream all users matching the list filters in CSV, NDJSON or Parquet along with their ratings.\nUsers are read chunk by chunk in the order of creation, so the export isn't loaded into memory.",
                 "consumes": [
                     "application/json"
                 ],
                 "produces": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson",
+                    "application/vnd.apache.parquet",
+                    "application/gzip"
                 ],
                 "tags": [
-                    "users"
+                    "admin"
                 ],
-                "summary": "Update user password",
+                "summary": "Export users",
                 "parameters": [
                     {
+                        "enum": [
+                            "csv",
+                            "ndjson",
+                            "parquet"
+                        ],
                         "type": "string",
-                        "description": "User ID",
-                        "name": "id",
-                        "in": "path",
-                        "required": true
+                        "description": "Export format, csv by default",
+                        "name": "format",
+                        "in": "query"
                     },
                     {
-                        "description": "User credentials",
-                        "name": "user",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.UpdatePasswordReq"
-                        }
+                        "type": "boolean",
+                        "description": "Compress the export with gzip",
+                        "name": "gzip",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by public custom attribute, e.g. attr.location=Berlin",
+                        "name": "attr.name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or after, RFC3339",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated before, RFC3339",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Order by creation time",
+                        "name": "order",
+                        "in": "query"
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Users export",
                         "schema": {
-                            "$ref": "#/definitions/domain.MessageResp"
+                            "type": "file"
                         }
                     },
                     "400": {
-                        "description": "Invalid request payload",
+                        "description": "Wrong filter or format",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
                     "500": {
-                        "description": "Failed to update user password",
+                        "description": "Failed to export users",
                         "schema": {
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
@@ -329,53 +413,66 @@ const docTemplate = `{
                 }
             }
         },
-        "/vote": {
-            "put": {
-                "description": "Change Vote for a user by id",
+        "/admin/users/import": {
+            "post": {
+                "description": "Create users in bulk from CSV with a header row or from newline delimited JSON.\nColumns and fields are nickname, first_name, last_name, password or pre-hashed bcrypt password_hash, and optional user_role.\nRows are validated like single profiles, users with existing nicknames are skipped.",
                 "consumes": [
-                    "application/json"
+                    "text/csv",
+                    "application/x-ndjson"
                 ],
                 "produces": [
                     "application/json"
                 ],
                 "tags": [
-                    "vote"
+                    "admin"
                 ],
-                "summary": "Change Vote",
+                "summary": "Import users",
                 "parameters": [
                     {
-                        "description": "Vote credentials",
-                        "name": "vote",
+                        "enum": [
+                            "csv",
+                            "ndjson"
+                        ],
+                        "type": "string",
+                        "description": "Format of the body, detected by Content-Type by default",
+                        "name": "format",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Only validate rows without creating users",
+                        "name": "dry_run",
+                        "in": "query"
+                    },
+                    {
+                        "description": "CSV or NDJSON rows",
+                        "name": "users",
                         "in": "body",
                         "required": true,
                         "schema": {
-                            "$ref": "#/definitions/domain.VoteReq"
+                            "type": "string"
                         }
                     }
                 ],
                 "responses": {
                     "200": {
-                        "description": "OK",
+                        "description": "Per-row report",
                         "schema": {
-                            "$ref": "#/definitions/domain.MessageResp"
+                            "$ref": "#/definitions/domain.ImportReport"
                         }
                     },
                     "400": {
-                        "description": "Bad Request",
-                        "schema": {
-                            "$ref": "#/definitions/domain.ErrorResp"
-                        }
-                    },
-                    "500": {
-                        "description": "Internal Server Error",
+                        "description": "Per-row report of rows read before the input turned out unreadable, or only the error",
                         "schema": {
-                            "$ref": "#/definitions/domain.ErrorResp"
+                            "$ref": "#/definitions/domain.ImportReport"
                         }
                     }
                 }
-            },
+            }
+        },
+        "/admin/users/{id}/restore": {
             "post": {
-                "description": "Vote for a user by id",
+                "description": "Restore a deleted user profile that wasn't purged yet",
                 "consumes": [
                     "application/json"
                 ],
@@ -383,25 +480,202 @@ const docTemplate = `{
                     "application/json"
                 ],
                 "tags": [
-                    "vote"
+                    "admin"
                 ],
-                "summary": "Vote",
+                "summary": "Restore deleted user",
                 "parameters": [
                     {
-                        "description": "Vote credentials",
-                        "name": "vote",
-                        "in": "body",
-                        "required": true,
-                        "schema": {
-                            "$ref": "#/definitions/domain.VoteReq"
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RestoreUserResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
                         }
                     }
+                }
+            }
+        },
+        "/exports/{id}": {
+            "get": {
+                "description": "Get the status of a data export and a time-limited download link once it's ready",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Get data export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
                 ],
                 "responses": {
                     "200": {
                         "description": "OK",
                         "schema": {
-                            "$ref": "#/definitions/domain.MessageResp"
+                            "$ref": "#/definitions/domain.ExportDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/exports/{id}/download": {
+            "get": {
+                "description": "Download a data export archive using the link from the export status",
+                "produces": [
+                    "application/zip"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Download data export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Export ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Download token",
+                        "name": "token",
+                        "in": "query",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "type": "file"
+                        }
+                    },
+                    "401": {
+                        "description": "Unauthorized",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Not Found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups": {
+            "get": {
+                "description": "Retrieve a paginated list of groups of the organization ordered by name",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get groups",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupsPage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get groups",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a group of users, the user creating it becomes its owner",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Create group",
+                "parameters": [
+                    {
+                        "description": "Group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupDTO"
                         }
                     },
                     "400": {
@@ -410,6 +684,12 @@ const docTemplate = `{
                             "$ref": "#/definitions/domain.ErrorResp"
                         }
                     },
+                    "409": {
+                        "description": "Group already exists",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
                     "500": {
                         "description": "Internal Server Error",
                         "schema": {
@@ -418,18 +698,2865 @@ const docTemplate = `{
                     }
                 }
             }
-        }
-    },
-    "definitions": {
-        "domain.CreateUserReq": {
+        },
+        "/groups/{id}": {
+            "get": {
+                "description": "Retrieve a group by its ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get group by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong GroupId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get group",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Change the name and the description of the group. Only owners of the group, moderators and admins are permitted to change it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Update group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Group",
+                        "name": "group",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Group already exists",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update group",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Delete the group, its members are kept. Only owners of the group, moderators and admins are permitted to delete it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Delete group",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to delete group",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/accept": {
+            "post": {
+                "description": "Accept the invitation to the group, the user becomes its member and owners of the group become permitted to update their profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Accept group invitation",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong GroupId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Group invitation not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to accept group invitation",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/members": {
+            "get": {
+                "description": "Retrieve a paginated list of members of the group, owners first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get group members",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupMembersPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong GroupId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get group members",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Add the user to the group or change their role in it. Only owners of the group, moderators and admins are permitted to manage members.\nUsers added by owners of the group are invited and become members once they accept, users added by moderators and admins become members at once.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Add group member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Member and their role, member by default",
+                        "name": "member",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupMemberReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Member added or invited",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Group or user not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Group should have an owner",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to add group member",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/groups/{id}/members/{user_id}": {
+            "delete": {
+                "description": "Remove the user from the group or withdraw their invitation. Members can leave groups and decline invitations, only owners of the group, moderators and admins are permitted to remove others.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Remove group member",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Group ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "user_id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "409": {
+                        "description": "Group should have an owner",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to remove group member",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/invites": {
+            "get": {
+                "description": "Retrieve a paginated list of invites created by the user, most recent first. Admins get all invites of the organization.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Get invites",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.InvitesPage"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get invites",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create an invite code for registration with a pre-assigned role and group. Admins can create invites for any role, other users can create invites for the user role only when REGISTRATION_USER_INVITES is on. Only owners of the group can invite to it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Create invite",
+                "parameters": [
+                    {
+                        "description": "Invite, one use of the user role by default",
+                        "name": "invite",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateInviteReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.InviteDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Group not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create invite",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/invites/{code}": {
+            "delete": {
+                "description": "Delete the invite so that it can't be used anymore. Only the user that created the invite and admins are permitted to revoke it.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "invites"
+                ],
+                "summary": "Revoke invite",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Invite code",
+                        "name": "code",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "Invite not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to revoke invite",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/leaderboard": {
+            "get": {
+                "description": "Retrieve users with the most votes, only active users with public profiles are listed. Leaderboards are cached for a short time",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vote"
+                ],
+                "summary": "Get leaderboard",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Count votes of this emoji only, 1 to 5",
+                        "name": "emoji",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Count votes cast or changed within the last day, week, month or all (default)",
+                        "name": "window",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Number of users, 10 by default",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Leaderboard"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong value",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get leaderboard",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users": {
+            "get": {
+                "description": "Retrieve a paginated list of user profiles, either by page number or by cursor.\nCursor pagination is keyed on creation time and works with the default sort only.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get a paginated list of users",
+                "parameters": [
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the next page, empty value starts from the first page",
+                        "name": "after",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Cursor of the previous page, empty value starts from the last page",
+                        "name": "before",
+                        "in": "query"
+                    },
+                    {
+                        "type": "boolean",
+                        "description": "Count total amount of users, defaults to true for page numbers and false for cursors",
+                        "name": "include_total",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Filter by public custom attribute, e.g. attr.location=Berlin",
+                        "name": "attr.name",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by state",
+                        "name": "state",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Filter by role",
+                        "name": "role",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created at or after, RFC3339",
+                        "name": "created_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Created before, RFC3339",
+                        "name": "created_to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated at or after, RFC3339",
+                        "name": "updated_from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Updated before, RFC3339",
+                        "name": "updated_to",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "nickname",
+                            "created_at",
+                            "rating"
+                        ],
+                        "type": "string",
+                        "description": "Sort by nickname, created_at or rating",
+                        "name": "sort",
+                        "in": "query"
+                    },
+                    {
+                        "enum": [
+                            "asc",
+                            "desc"
+                        ],
+                        "type": "string",
+                        "description": "Sort order",
+                        "name": "order",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paginated list of user profiles",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GetUserListResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong filter",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get users list",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Create a new user profile with the provided information. An invite code is required when registration is invite-only, users registered with an invite get its role and group",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Create a user profile",
+                "parameters": [
+                    {
+                        "description": "User profile details",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateUserReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "201": {
+                        "description": "Created",
+                        "schema": {
+                            "$ref": "#/definitions/domain.CreateUserResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "403": {
+                        "description": "Registration is closed",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to create user profile",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/login": {
+            "post": {
+                "description": "Log in with the provided credentials and generate a JWT token",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Log in and generate JWT token",
+                "parameters": [
+                    {
+                        "description": "User credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.LoginResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "401": {
+                        "description": "Failed to log in",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/nickname/{nickname}": {
+            "get": {
+                "description": "Resolve a current or former nickname to the current profile, hidden profiles are resolved only for their owners and moderators",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Resolve nickname",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Current or former nickname",
+                        "name": "nickname",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.NicknameDTO"
+                        }
+                    },
+                    "404": {
+                        "description": "Nickname not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to resolve nickname",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/search": {
+            "get": {
+                "description": "Full-text and fuzzy search by nickname, first name and last name, ranked by relevance",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Search users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "Search query, words are matched by prefix and with typo tolerance",
+                        "name": "q",
+                        "in": "query",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, real names are matched and shown according to privacy settings of the users and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paginated search results",
+                        "schema": {
+                            "$ref": "#/definitions/domain.SearchUsersResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Empty search query",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to search users",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}": {
+            "get": {
+                "description": "Retrieve user details by the provided user ID",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get user by ID",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, profiles are rendered according to privacy settings of the user and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "1 renders the rating as a string, e.g. ❤️:3; 😂:1;",
+                        "name": "X-API-Version",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "User profile details",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GetProfileDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get user profile",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update an existing user profile with the provided information. Owners of groups are permitted to update profiles of the group members.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user profile",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateUserReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user profile",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Mark a user profile as deleted, it can be restored by admins until it is purged",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Delete user by ID",
+                "responses": {
+                    "200": {
+                        "description": "Profile successfully deleted",
+                        "schema": {
+                            "type": "string"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/attributes": {
+            "get": {
+                "description": "Retrieve custom profile attributes that are visible to the authenticated user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Get user attributes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.AttributesResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get user attributes",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Replace custom profile attributes, validated against the attributes schema",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "attributes"
+                ],
+                "summary": "Update user attributes",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Attributes and their visibility",
+                        "name": "attributes",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdateAttributesReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user attributes",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/block": {
+            "post": {
+                "description": "Block the user, blocked users can't rate or follow the profile and follows between the users are removed",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocks"
+                ],
+                "summary": "Block user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID to block",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to block user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Unblock the user, removed follows aren't restored",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocks"
+                ],
+                "summary": "Unblock user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID to unblock",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to unblock user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/blocks": {
+            "get": {
+                "description": "Retrieve a paginated list of users blocked by the user, most recently blocked first",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "blocks"
+                ],
+                "summary": "Get blocked users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paginated list of blocked users",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GetUserListResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get blocked users",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/export": {
+            "post": {
+                "description": "Start building a ZIP archive with everything that is stored about the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "export"
+                ],
+                "summary": "Request data export",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "202": {
+                        "description": "Accepted",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ExportDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/follow": {
+            "post": {
+                "description": "Follow the user, following an already followed user is a no-op",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Follow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID to follow",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to follow user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "delete": {
+                "description": "Stop following the user",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Unfollow user",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID to unfollow",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to unfollow user",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/followers": {
+            "get": {
+                "description": "Retrieve a paginated list of users following the user, most recent first. Only public profiles are listed to users.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Get followers",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paginated list of followers",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GetUserListResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get followers",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/following": {
+            "get": {
+                "description": "Retrieve a paginated list of users followed by the user, most recent first. Only public profiles are listed to users.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Get followed users",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "Paginated list of followed users",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GetUserListResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get followed users",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/follows/{other_id}": {
+            "get": {
+                "description": "Check whether the users follow each other. Users that don't exist, aren't active or are hidden are reported as unrelated, unless the viewer is a moderator.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "follows"
+                ],
+                "summary": "Get follow relationship",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Other user ID",
+                        "name": "other_id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.FollowRelationDTO"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get follow relationship",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/groups": {
+            "get": {
+                "description": "Retrieve a paginated list of groups the user is a member of along with their role in each, most recently joined first.\nInvitations to groups are listed with status invited, only to the user themselves, moderators and admins.",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "groups"
+                ],
+                "summary": "Get user groups",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Membership status: active (default) or invited",
+                        "name": "status",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Page number",
+                        "name": "page",
+                        "in": "query"
+                    },
+                    {
+                        "type": "integer",
+                        "description": "Items per page",
+                        "name": "limit",
+                        "in": "query"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.GroupsPage"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get user groups",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/password": {
+            "put": {
+                "description": "Update the password for the authenticated user or admin",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update user password",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "User credentials",
+                        "name": "user",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.UpdatePasswordReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update user password",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/preferences": {
+            "get": {
+                "description": "Get locale, timezone, date format and notification settings of the user, defaults are returned if the user hasn't set them",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Preferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get preferences",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Update locale, timezone, date format and notification settings of the user, fields that are left out keep their current values",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update preferences",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Preferences",
+                        "name": "preferences",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.Preferences"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.Preferences"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update preferences",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/privacy": {
+            "get": {
+                "description": "Get privacy settings of the profile",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Get privacy settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.PrivacySettings"
+                        }
+                    },
+                    "400": {
+                        "description": "Wrong UserId",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get privacy settings",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "put": {
+                "description": "Set profile visibility, real name visibility and whether the rating breakdown is hidden from other users",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "users"
+                ],
+                "summary": "Update privacy settings",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "description": "Privacy settings",
+                        "name": "privacy",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.PrivacySettings"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Invalid request payload",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to update privacy settings",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/users/{id}/rating/history": {
+            "get": {
+                "description": "Retrieve votes received by the user per interval, votes are counted by the time they were cast or last changed and intervals without votes are returned empty",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vote"
+                ],
+                "summary": "Get rating history",
+                "parameters": [
+                    {
+                        "type": "string",
+                        "description": "User ID",
+                        "name": "id",
+                        "in": "path",
+                        "required": true
+                    },
+                    {
+                        "type": "string",
+                        "description": "Bucket size: hour, day (default), week or month, weeks start on Monday",
+                        "name": "interval",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Start of the period, RFC 3339, 30 intervals before the end by default",
+                        "name": "from",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "End of the period, exclusive, RFC 3339, now by default",
+                        "name": "to",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "IANA time zone the buckets are aligned to, UTC by default",
+                        "name": "timezone",
+                        "in": "query"
+                    },
+                    {
+                        "type": "string",
+                        "description": "Optional bearer token, the breakdown by emoji is rendered according to privacy settings of the user and identity of the viewer",
+                        "name": "Authorization",
+                        "in": "header"
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.RatingHistory"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Failed to get rating history",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        },
+        "/vote": {
+            "put": {
+                "description": "Change Vote for a user by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vote"
+                ],
+                "summary": "Change Vote",
+                "parameters": [
+                    {
+                        "description": "Vote credentials",
+                        "name": "vote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VoteReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            },
+            "post": {
+                "description": "Vote for a user by id",
+                "consumes": [
+                    "application/json"
+                ],
+                "produces": [
+                    "application/json"
+                ],
+                "tags": [
+                    "vote"
+                ],
+                "summary": "Vote",
+                "parameters": [
+                    {
+                        "description": "Vote credentials",
+                        "name": "vote",
+                        "in": "body",
+                        "required": true,
+                        "schema": {
+                            "$ref": "#/definitions/domain.VoteReq"
+                        }
+                    }
+                ],
+                "responses": {
+                    "200": {
+                        "description": "OK",
+                        "schema": {
+                            "$ref": "#/definitions/domain.MessageResp"
+                        }
+                    },
+                    "400": {
+                        "description": "Bad Request",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "404": {
+                        "description": "User not found",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    },
+                    "500": {
+                        "description": "Internal Server Error",
+                        "schema": {
+                            "$ref": "#/definitions/domain.ErrorResp"
+                        }
+                    }
+                }
+            }
+        }
+    },
+    "definitions": {
+        "domain.AttributesResp": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "visibility": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Visibility"
+                    }
+                }
+            }
+        },
+        "domain.AuditAction": {
+            "type": "string",
+            "enum": [
+                "create",
+                "update",
+                "password",
+                "delete",
+                "restore",
+                "privacy",
+                "attributes",
+                "import",
+                "purge",
+                "preferences",
+                "group_create",
+                "group_update",
+                "group_delete",
+                "group_member_add",
+                "group_member_remove",
+                "invite_create",
+                "invite_revoke"
+            ],
+            "x-enum-varnames": [
+                "AuditCreate",
+                "AuditUpdate",
+                "AuditPassword",
+                "AuditDelete",
+                "AuditRestore",
+                "AuditPrivacy",
+                "AuditAttributes",
+                "AuditImport",
+                "AuditPurge",
+                "AuditPreferences",
+                "AuditGroupCreate",
+                "AuditGroupUpdate",
+                "AuditGroupDelete",
+                "AuditGroupMemberAdd",
+                "AuditGroupMemberRemove",
+                "AuditInviteCreate",
+                "AuditInviteRevoke"
+            ]
+        },
+        "domain.AuditPage": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "records": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.AuditRecord"
+                    }
+                },
+                "total_items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.AuditRecord": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/domain.AuditAction"
+                },
+                "actor_oid": {
+                    "type": "string"
+                },
+                "after": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "before": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "integer"
+                },
+                "ip": {
+                    "type": "string"
+                },
+                "request_id": {
+                    "type": "string"
+                },
+                "target_oid": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.BatchAction": {
+            "type": "string",
+            "enum": [
+                "ban",
+                "unban",
+                "delete",
+                "set_role"
+            ],
+            "x-enum-varnames": [
+                "BatchBan",
+                "BatchUnban",
+                "BatchDelete",
+                "BatchSetRole"
+            ]
+        },
+        "domain.BatchChunkDTO": {
+            "type": "object",
+            "properties": {
+                "affected": {
+                    "type": "integer"
+                },
+                "chunk": {
+                    "type": "integer"
+                },
+                "error": {
+                    "type": "string"
+                },
+                "users": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.BatchReq": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/domain.BatchAction"
+                },
+                "chunked": {
+                    "type": "boolean"
+                },
+                "filter": {
+                    "$ref": "#/definitions/domain.UsersFilter"
+                },
+                "oids": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                }
+            }
+        },
+        "domain.BatchResultDTO": {
+            "type": "object",
+            "properties": {
+                "action": {
+                    "$ref": "#/definitions/domain.BatchAction"
+                },
+                "affected": {
+                    "type": "array",
+                    "items": {
+                        "type": "string"
+                    }
+                },
+                "chunks": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BatchChunkDTO"
+                    }
+                },
+                "matched": {
+                    "type": "integer"
+                },
+                "skipped": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.BatchSkippedDTO"
+                    }
+                }
+            }
+        },
+        "domain.BatchSkippedDTO": {
+            "type": "object",
+            "properties": {
+                "oid": {
+                    "type": "string"
+                },
+                "reason": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateInviteReq": {
+            "type": "object",
+            "properties": {
+                "expires_in_hours": {
+                    "type": "integer"
+                },
+                "group_oid": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "user_role": {
+                    "$ref": "#/definitions/domain.Role"
+                }
+            }
+        },
+        "domain.CreateOrganizationReq": {
+            "type": "object",
+            "properties": {
+                "admin": {
+                    "$ref": "#/definitions/domain.CreateUserReq"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateUserReq": {
+            "type": "object",
+            "properties": {
+                "first_name": {
+                    "type": "string"
+                },
+                "invite_code": {
+                    "description": "InviteCode is required when registration is invite-only",
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.CreateUserResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.DateFormat": {
+            "type": "string",
+            "enum": [
+                "iso",
+                "dmy",
+                "mdy"
+            ],
+            "x-enum-varnames": [
+                "DateFormatISO",
+                "DateFormatDMY",
+                "DateFormatMDY"
+            ]
+        },
+        "domain.EmojiRating": {
+            "type": "object",
+            "properties": {
+                "count": {
+                    "type": "integer"
+                },
+                "emoji": {
+                    "type": "string"
+                },
+                "emoji_id": {
+                    "type": "integer"
+                },
+                "share": {
+                    "type": "number"
+                }
+            }
+        },
+        "domain.ErrorResp": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ExportDTO": {
+            "type": "object",
+            "properties": {
+                "completed_at": {
+                    "type": "string"
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "download_url": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "requested_by": {
+                    "type": "string"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.ExportStatus"
+                },
+                "user_oid": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.ExportStatus": {
+            "type": "string",
+            "enum": [
+                "pending",
+                "ready",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ExportPending",
+                "ExportReady",
+                "ExportFailed"
+            ]
+        },
+        "domain.FollowRelationDTO": {
+            "type": "object",
+            "properties": {
+                "followed_by": {
+                    "type": "boolean"
+                },
+                "follows": {
+                    "type": "boolean"
+                },
+                "mutual": {
+                    "type": "boolean"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "other_oid": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GetProfileDTO": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "followers_count": {
+                    "type": "integer"
+                },
+                "following_count": {
+                    "type": "integer"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "rating": {
+                    "$ref": "#/definitions/domain.Rating"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.State"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_role": {
+                    "$ref": "#/definitions/domain.Role"
+                }
+            }
+        },
+        "domain.GetUserListResp": {
+            "type": "object",
+            "properties": {
+                "next": {
+                    "type": "string"
+                },
+                "page": {
+                    "type": "integer"
+                },
+                "prev": {
+                    "type": "string"
+                },
+                "total_users": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GetUserResp"
+                    }
+                }
+            }
+        },
+        "domain.GetUserResp": {
+            "type": "object",
+            "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "created_at": {
+                    "type": "string"
+                },
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "state": {
+                    "type": "integer"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GroupDTO": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "description": {
+                    "type": "string"
+                },
+                "members_count": {
+                    "type": "integer"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.GroupRole"
+                },
+                "updated_at": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GroupMemberDTO": {
+            "type": "object",
+            "properties": {
+                "joined_at": {
+                    "type": "string"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.GroupRole"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.UserProfileDTO"
+                }
+            }
+        },
+        "domain.GroupMemberReq": {
+            "type": "object",
+            "properties": {
+                "role": {
+                    "$ref": "#/definitions/domain.GroupRole"
+                },
+                "user_oid": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GroupMembersPage": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "members": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GroupMemberDTO"
+                    }
+                },
+                "total_items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.GroupReq": {
+            "type": "object",
+            "properties": {
+                "description": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.GroupRole": {
+            "type": "string",
+            "enum": [
+                "owner",
+                "member"
+            ],
+            "x-enum-varnames": [
+                "GroupOwner",
+                "GroupMember"
+            ]
+        },
+        "domain.GroupsPage": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "groups": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.GroupDTO"
+                    }
+                },
+                "total_items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportReport": {
+            "type": "object",
+            "properties": {
+                "created": {
+                    "type": "integer"
+                },
+                "dry_run": {
+                    "type": "boolean"
+                },
+                "error": {
+                    "description": "Error tells why the input couldn't be read to the end, rows created before that are kept.",
+                    "type": "string"
+                },
+                "failed": {
+                    "type": "integer"
+                },
+                "rows": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.ImportRowResult"
+                    }
+                },
+                "skipped": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.ImportRowResult": {
+            "type": "object",
+            "properties": {
+                "error": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "row": {
+                    "type": "integer"
+                },
+                "status": {
+                    "$ref": "#/definitions/domain.ImportStatus"
+                }
+            }
+        },
+        "domain.ImportStatus": {
+            "type": "string",
+            "enum": [
+                "created",
+                "skipped",
+                "failed"
+            ],
+            "x-enum-varnames": [
+                "ImportCreated",
+                "ImportSkipped",
+                "ImportFailed"
+            ]
+        },
+        "domain.InviteDTO": {
             "type": "object",
             "properties": {
-                "first_name": {
+                "code": {
                     "type": "string"
                 },
-                "last_name": {
+                "created_at": {
+                    "type": "string"
+                },
+                "created_by": {
+                    "type": "string"
+                },
+                "expires_at": {
+                    "type": "string"
+                },
+                "group_oid": {
+                    "type": "string"
+                },
+                "max_uses": {
+                    "type": "integer"
+                },
+                "user_role": {
+                    "$ref": "#/definitions/domain.Role"
+                },
+                "uses": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.InvitesPage": {
+            "type": "object",
+            "properties": {
+                "current_page": {
+                    "type": "integer"
+                },
+                "invites": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.InviteDTO"
+                    }
+                },
+                "total_items": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.Leaderboard": {
+            "type": "object",
+            "properties": {
+                "emoji_id": {
+                    "type": "integer"
+                },
+                "entries": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.LeaderboardEntry"
+                    }
+                },
+                "limit": {
+                    "type": "integer"
+                },
+                "updated_at": {
                     "type": "string"
                 },
+                "window": {
+                    "$ref": "#/definitions/domain.LeaderboardWindow"
+                }
+            }
+        },
+        "domain.LeaderboardEntry": {
+            "type": "object",
+            "properties": {
+                "place": {
+                    "type": "integer"
+                },
+                "user": {
+                    "$ref": "#/definitions/domain.UserProfileDTO"
+                },
+                "votes": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.LeaderboardWindow": {
+            "type": "string",
+            "enum": [
+                "day",
+                "week",
+                "month",
+                "all"
+            ],
+            "x-enum-varnames": [
+                "WindowDay",
+                "WindowWeek",
+                "WindowMonth",
+                "WindowAll"
+            ]
+        },
+        "domain.LoginReq": {
+            "type": "object",
+            "properties": {
                 "nickname": {
                     "type": "string"
                 },
@@ -438,51 +3565,245 @@ const docTemplate = `{
                 }
             }
         },
-        "domain.CreateUserResp": {
+        "domain.LoginResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "token": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.MessageResp": {
             "type": "object",
             "properties": {
                 "message": {
                     "type": "string"
+                }
+            }
+        },
+        "domain.NameVisibility": {
+            "type": "string",
+            "enum": [
+                "everyone",
+                "users",
+                "self"
+            ],
+            "x-enum-varnames": [
+                "NameEveryone",
+                "NameUsers",
+                "NameSelf"
+            ]
+        },
+        "domain.NicknameDTO": {
+            "type": "object",
+            "properties": {
+                "changed_at": {
+                    "type": "string"
+                },
+                "former": {
+                    "type": "boolean"
+                },
+                "nickname": {
+                    "type": "string"
                 },
                 "oid": {
                     "type": "string"
                 }
             }
         },
-        "domain.ErrorResp": {
+        "domain.NotificationSettings": {
             "type": "object",
             "properties": {
-                "error": {
+                "follows": {
+                    "type": "boolean"
+                },
+                "newsletter": {
+                    "type": "boolean"
+                },
+                "votes": {
+                    "type": "boolean"
+                }
+            }
+        },
+        "domain.Organization": {
+            "type": "object",
+            "properties": {
+                "created_at": {
+                    "type": "string"
+                },
+                "id": {
+                    "type": "string"
+                },
+                "name": {
+                    "type": "string"
+                },
+                "slug": {
                     "type": "string"
                 }
             }
         },
-        "domain.GetUserListResp": {
+        "domain.Preferences": {
             "type": "object",
             "properties": {
-                "page": {
-                    "type": "integer"
+                "date_format": {
+                    "$ref": "#/definitions/domain.DateFormat"
                 },
-                "total_users": {
+                "locale": {
+                    "type": "string"
+                },
+                "notifications": {
+                    "$ref": "#/definitions/domain.NotificationSettings"
+                },
+                "timezone": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.PrivacySettings": {
+            "type": "object",
+            "properties": {
+                "hide_rating_breakdown": {
+                    "type": "boolean"
+                },
+                "profile_visibility": {
+                    "$ref": "#/definitions/domain.ProfileVisibility"
+                },
+                "real_name_visibility": {
+                    "$ref": "#/definitions/domain.NameVisibility"
+                }
+            }
+        },
+        "domain.ProfileVisibility": {
+            "type": "string",
+            "enum": [
+                "public",
+                "unlisted",
+                "hidden"
+            ],
+            "x-enum-varnames": [
+                "ProfilePublic",
+                "ProfileUnlisted",
+                "ProfileHidden"
+            ]
+        },
+        "domain.Rating": {
+            "type": "object",
+            "properties": {
+                "emojis": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EmojiRating"
+                    }
+                },
+                "total": {
                     "type": "integer"
+                }
+            }
+        },
+        "domain.RatingBucket": {
+            "type": "object",
+            "properties": {
+                "emojis": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.EmojiRating"
+                    }
                 },
-                "users": {
+                "start": {
+                    "type": "string"
+                },
+                "total": {
+                    "type": "integer"
+                }
+            }
+        },
+        "domain.RatingHistory": {
+            "type": "object",
+            "properties": {
+                "buckets": {
                     "type": "array",
                     "items": {
-                        "$ref": "#/definitions/domain.GetUserResp"
+                        "$ref": "#/definitions/domain.RatingBucket"
                     }
+                },
+                "from": {
+                    "type": "string"
+                },
+                "interval": {
+                    "$ref": "#/definitions/domain.RatingInterval"
+                },
+                "timezone": {
+                    "type": "string"
+                },
+                "to": {
+                    "type": "string"
                 }
             }
         },
-        "domain.GetUserResp": {
+        "domain.RatingInterval": {
+            "type": "string",
+            "enum": [
+                "hour",
+                "day",
+                "week",
+                "month"
+            ],
+            "x-enum-varnames": [
+                "IntervalHour",
+                "IntervalDay",
+                "IntervalWeek",
+                "IntervalMonth"
+            ]
+        },
+        "domain.RestoreUserResp": {
+            "type": "object",
+            "properties": {
+                "message": {
+                    "type": "string"
+                },
+                "nickname": {
+                    "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Role": {
+            "type": "integer",
+            "enum": [
+                1,
+                2,
+                3
+            ],
+            "x-enum-varnames": [
+                "Usr",
+                "Moderator",
+                "Admin"
+            ]
+        },
+        "domain.SearchUserResp": {
             "type": "object",
             "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
                 "created_at": {
                     "type": "string"
                 },
                 "first_name": {
                     "type": "string"
                 },
+                "highlights": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "type": "string"
+                    }
+                },
                 "last_name": {
                     "type": "string"
                 },
@@ -492,6 +3813,9 @@ const docTemplate = `{
                 "oid": {
                     "type": "string"
                 },
+                "rank": {
+                    "type": "number"
+                },
                 "state": {
                     "type": "integer"
                 },
@@ -500,66 +3824,188 @@ const docTemplate = `{
                 }
             }
         },
-        "domain.LoginReq": {
+        "domain.SearchUsersResp": {
             "type": "object",
             "properties": {
-                "nickname": {
-                    "type": "string"
+                "current_page": {
+                    "type": "integer"
                 },
-                "password": {
-                    "type": "string"
+                "total_items": {
+                    "type": "integer"
+                },
+                "users": {
+                    "type": "array",
+                    "items": {
+                        "$ref": "#/definitions/domain.SearchUserResp"
+                    }
                 }
             }
         },
-        "domain.LoginResp": {
+        "domain.State": {
+            "type": "integer",
+            "enum": [
+                -1,
+                0,
+                1
+            ],
+            "x-enum-varnames": [
+                "Deleted",
+                "Banned",
+                "Active"
+            ]
+        },
+        "domain.UpdateAttributesReq": {
             "type": "object",
             "properties": {
-                "message": {
-                    "type": "string"
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
                 },
-                "token": {
-                    "type": "string"
+                "visibility": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Visibility"
+                    }
                 }
             }
         },
-        "domain.MessageResp": {
+        "domain.UpdatePasswordReq": {
             "type": "object",
             "properties": {
-                "message": {
+                "password": {
                     "type": "string"
                 }
             }
         },
-        "domain.UpdatePasswordReq": {
+        "domain.UpdateUserReq": {
             "type": "object",
             "properties": {
-                "password": {
+                "first_name": {
+                    "type": "string"
+                },
+                "last_name": {
+                    "type": "string"
+                },
+                "nickname": {
                     "type": "string"
                 }
             }
         },
-        "domain.UpdateUserReq": {
+        "domain.UserProfileDTO": {
             "type": "object",
             "properties": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "attributes_visibility": {
+                    "type": "object",
+                    "additionalProperties": {
+                        "$ref": "#/definitions/domain.Visibility"
+                    }
+                },
+                "created_at": {
+                    "type": "string"
+                },
                 "first_name": {
                     "type": "string"
                 },
+                "invite_code": {
+                    "description": "InviteCode is only read on registration",
+                    "type": "string"
+                },
                 "last_name": {
                     "type": "string"
                 },
                 "nickname": {
                     "type": "string"
+                },
+                "oid": {
+                    "type": "string"
+                },
+                "password": {
+                    "type": "string"
+                },
+                "privacy": {
+                    "description": "Privacy is kept in cache along with the rest of the profile, but never rendered to other users",
+                    "allOf": [
+                        {
+                            "$ref": "#/definitions/domain.PrivacySettings"
+                        }
+                    ]
+                },
+                "rating": {
+                    "type": "integer"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.State"
+                },
+                "updated_at": {
+                    "type": "string"
+                },
+                "user_role": {
+                    "$ref": "#/definitions/domain.Role"
                 }
             }
         },
-        "domain.VoteReq": {
+        "domain.UsersFilter": {
             "type": "object",
             "properties": {
-                "oid": {
+                "attributes": {
+                    "type": "object",
+                    "additionalProperties": true
+                },
+                "created_from": {
+                    "type": "string"
+                },
+                "created_to": {
+                    "type": "string"
+                },
+                "desc": {
+                    "type": "boolean"
+                },
+                "listed": {
+                    "description": "Listed leaves out profiles that are unlisted or hidden by their owners",
+                    "type": "boolean"
+                },
+                "role": {
+                    "$ref": "#/definitions/domain.Role"
+                },
+                "sort": {
+                    "type": "string"
+                },
+                "state": {
+                    "$ref": "#/definitions/domain.State"
+                },
+                "updated_from": {
                     "type": "string"
                 },
-                "value": {
+                "updated_to": {
+                    "type": "string"
+                }
+            }
+        },
+        "domain.Visibility": {
+            "type": "string",
+            "enum": [
+                "public",
+                "self",
+                "moderators"
+            ],
+            "x-enum-varnames": [
+                "VisibilityPublic",
+                "VisibilitySelf",
+                "VisibilityModerators"
+            ]
+        },
+        "domain.VoteReq": {
+            "type": "object",
+            "properties": {
+                "emoji": {
                     "type": "integer"
+                },
+                "oid": {
+                    "type": "string"
                 }
             }
         }
@@ -0,0 +1,79 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/api"
+	"github.com/sosshik/rest-user-management/cmd/internal/database"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+// runImport creates users in bulk from a CSV or NDJSON file and prints the per-row report, e.g.
+//
+//	go run ./cmd import -dry-run -tenant acme users.csv
+func runImport(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("import", flag.ExitOnError)
+	format := flags.String("format", "", "csv or ndjson, detected by file extension by default")
+	tenant := flags.String("tenant", cfg.Tenant.Default, "slug of the organization users are imported into")
+	dryRun := flags.Bool("dry-run", false, "only validate rows without creating users")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		return errors.New("usage: import [-format csv|ndjson] [-dry-run] [-tenant slug] <file>")
+	}
+	path := flags.Arg(0)
+
+	if *format == "" {
+		switch strings.ToLower(filepath.Ext(path)) {
+		case ".csv":
+			*format = api.ImportFormatCSV
+		case ".ndjson", ".jsonl":
+			*format = api.ImportFormatNDJSON
+		}
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("unable to open import file: %w", err)
+	}
+	defer file.Close()
+
+	keyring, err := pii.Load(cfg.PII.KeyringFile)
+	if err != nil {
+		return err
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.DB.Close()
+	db.PII = keyring
+
+	org, err := db.GetOrganizationBySlug(*tenant)
+	if err != nil {
+		return fmt.Errorf("unable to find organization %q: %w", *tenant, err)
+	}
+
+	importer := api.API{Orgs: db, DB: db, Config: cfg, Nicknames: nickname.NewValidator(cfg.Nickname)}
+	report, err := importer.ImportUsers(org.ID, file, *format, *dryRun)
+	if err != nil && len(report.Rows) == 0 {
+		return err
+	}
+
+	// rows created before a read error are kept, so the report is printed either way
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if encodeErr := encoder.Encode(report); encodeErr != nil {
+		return encodeErr
+	}
+	return err
+}
@@ -1,586 +1,916 @@
-package api
-
-import (
-	"database/sql"
-	"errors"
-	"fmt"
-	"net/http"
-	"os"
-	"strconv"
-	"strings"
-	"time"
-	"unicode"
-
-	"github.com/redis/go-redis/v9"
-
-	"github.com/golang-jwt/jwt"
-	"github.com/google/uuid"
-	"github.com/labstack/echo/v4"
-	log "github.com/sirupsen/logrus"
-	"github.com/sosshik/rest-user-management/cmd/internal/domain"
-	"golang.org/x/crypto/bcrypt"
-)
-
-type API struct {
-	DB     domain.UserProfileManager
-	Cache  domain.CacheInterface
-	Rating domain.StatsManager
-}
-
-type CustomClaims struct {
-	OID  uuid.UUID   `json:"oid"`
-	Role domain.Role `json:"user_role"`
-	jwt.StandardClaims
-}
-
-func (a *API) BasicAuth(username, password string, c echo.Context) (bool, error) {
-
-	passwordHash, err := a.DB.GetPassword(username)
-	if err != nil {
-		log.Warn(err)
-		return false, err
-	}
-	err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
-	if err != nil {
-		log.Warnf("auth: wrong password: %s", err)
-		return false, err
-	}
-	return true, nil
-}
-
-func CheckPassword(psw string) error {
-
-	if len(psw) < 8 {
-
-		return errors.New("password is too short, should be at least 8 symbols")
-
-	}
-
-	var lower, upper, number, symbol bool
-
-	for _, letter := range psw {
-
-		if unicode.IsLower(letter) {
-			lower = true
-		}
-		if unicode.IsUpper(letter) {
-			upper = true
-		}
-		if unicode.IsNumber(letter) {
-			number = true
-		}
-		if unicode.IsSymbol(letter) || unicode.IsPunct(letter) {
-			symbol = true
-		}
-	}
-
-	if lower && upper && number && symbol {
-		return nil
-	}
-	return errors.New("wrong password format: password must contatin at least 1 upper case letter, 1 lower case letter, 1 number and 1 symbol")
-}
-
-func (a *API) JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
-	return func(c echo.Context) error {
-		tokenString, _ := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
-		if tokenString == "" {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Missing token"})
-		}
-
-		token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
-			return []byte(os.Getenv("JWT_KEY")), nil
-		})
-
-		if err != nil || !token.Valid {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
-		}
-
-		claims, ok := token.Claims.(*CustomClaims)
-
-		if !ok {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error with authentication, please re-login."})
-		}
-
-		state, err := a.DB.GetUserState(claims.OID)
-		if err != nil {
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error with authentication, please re-login."})
-		}
-		if state != 1 {
-			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Your profile is banned or deleted"})
-		}
-
-		c.Set("oid", claims.OID)
-		c.Set("role", claims.Role)
-
-		return next(c)
-	}
-}
-
-func (a *API) CreateToken(nickname string) (string, error) {
-
-	user, err := a.DB.GetUserForToken(nickname)
-	if err != nil {
-		return "", err
-	}
-
-	if user.State != domain.Active {
-		return "", errors.New("unable to create JWT token: user is not in active status")
-	}
-
-	claims := &CustomClaims{
-		OID:  user.OID,
-		Role: user.Role,
-		StandardClaims: jwt.StandardClaims{
-			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
-		},
-	}
-
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_KEY")))
-	if err != nil {
-		return "", fmt.Errorf("unable to create JWT token: %w", err)
-	}
-
-	return tokenString, nil
-}
-
-// @Summary Create a user profile
-// @Description Create a new user profile with the provided information
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param user body domain.CreateUserReq true "User profile details"
-// @Success 201 {object} domain.CreateUserResp
-// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
-// @Failure 500 {object} domain.ErrorResp "Failed to create user profile"
-// @Router /users [post]
-func (a *API) HandleCreateUserProfile(c echo.Context) error {
-
-	var user domain.UserProfileDTO
-	if err := c.Bind(&user); err != nil {
-		log.Warnf("HandleCreateUserProfile - unable to decode JSON: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-
-	err := CheckPassword(user.Password)
-	if err != nil {
-		log.Warnf("HandleCreateUserProfile - user provided wrong password: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
-	}
-
-	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Warnf("HandleCreateUserProfile - unable to generate hash for password: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unable to generate hash for password"})
-	}
-	user.Password = string(hash)
-	user.OID = uuid.New()
-	user.CreatedAt = time.Now().UTC()
-	user.UpdatedAt = time.Now().UTC()
-	user.State = domain.Active
-
-	err = a.DB.CreateUserProfile(user)
-	if err != nil {
-		log.Warnf("HandleCreateUserProfile: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create user profile"})
-	}
-
-	log.Infof("Successfully created user profile for user %s with oid %s", user.Nickname, user.OID.String())
-	return c.JSON(http.StatusCreated, map[string]string{
-		"oid":     user.OID.String(),
-		"message": "User profile created successfully.",
-	})
-}
-
-// @Summary Log in and generate JWT token
-// @Description Log in with the provided credentials and generate a JWT token
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param user body domain.LoginReq true "User credentials"
-// @Success 200 {object} domain.LoginResp
-// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
-// @Failure 401 {object} domain.ErrorResp "Failed to log in"
-// @Router /users/login [post]
-func (a *API) HandleLogIn(c echo.Context) error {
-
-	var user domain.UserProfileDTO
-	if err := c.Bind(&user); err != nil {
-		log.Warnf("HandleLogIn - unable to decode JSON: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-
-	token, err := a.CreateToken(user.Nickname)
-	if err != nil {
-		log.Warnf("HandleLogIn: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to log in"})
-	}
-
-	log.Infof("JWT token for user %s with oid %s", user.Nickname, user.OID.String())
-
-	c.Response().Header().Set("x-auth-token", "Bearer "+token)
-
-	return c.JSON(http.StatusOK, map[string]string{
-		"token":   token,
-		"message": "Successfully logged in",
-	})
-
-}
-
-// @Summary Update user profile
-// @Description Update an existing user profile with the provided information
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param id path string true "User ID"
-// @Param user body domain.UpdateUserReq true "User credentials"
-// @Success 200 {object} domain.MessageResp
-// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
-// @Failure 500 {object} domain.ErrorResp "Failed to update user profile"
-// @Router /users/{id} [put]
-func (a *API) HandleUpdateUserProfile(c echo.Context) error {
-	userRoleFromAuth := c.Get("role").(domain.Role)
-	userIDFromAuth := c.Get("oid")
-
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		log.Warnf("HandleUpdateUserProfile - unable to convert string to uuid: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-
-	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
-	}
-
-	var updateUser domain.UserProfileDTO
-	if err := c.Bind(&updateUser); err != nil {
-		log.Warnf("HandleUpdateUserProfile - unable to decode JSON: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-
-	updateUser.UpdatedAt = time.Now().UTC()
-
-	err = a.DB.UpdateUserProfile(updateUser, userID)
-	if err != nil {
-		log.Warnf("HandleUpdateUserProfile: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
-	}
-
-	log.Infof("Successfully updated user profile for user %s with oid %s", updateUser.Nickname, userID)
-	return c.JSON(http.StatusOK, map[string]string{"message": "User profile updated successfully."})
-}
-
-// @Summary Update user password
-// @Description Update the password for the authenticated user or admin
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param id path string true "User ID"
-// @Param user body domain.UpdatePasswordReq true "User credentials"
-// @Success 200 {object} domain.MessageResp
-// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
-// @Failure 500 {object} domain.ErrorResp "Failed to update user password"
-// @Router /users/{id}/password [put]
-func (a *API) HandleUpdateUserPassword(c echo.Context) error {
-
-	userRoleFromAuth := c.Get("role").(domain.Role)
-	userIDFromAuth := c.Get("oid").(uuid.UUID)
-
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		log.Warnf("HandleUpdateUserPassword - unable to convert string to uuid: %s", err)
-		return err
-	}
-
-	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
-	}
-
-	var updatePass domain.UserProfileDTO
-	if err := c.Bind(&updatePass); err != nil {
-		log.Warnf("HandleUpdateUserPassword - unable to decode JSON: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-
-	err = CheckPassword(updatePass.Password)
-	if err != nil {
-		log.Warnf("HandleUpdateUserPassword - user provided wrong password: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
-	}
-
-	newPass, err := bcrypt.GenerateFromPassword([]byte(updatePass.Password), bcrypt.DefaultCost)
-	if err != nil {
-		log.Warnf("HandleUpdateUserPassword - unable to generate hash for password: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unable to generate hash for password"})
-	}
-
-	err = a.DB.UpdatePassword(string(newPass), userID)
-	if err != nil {
-		log.Warnf("HandleUpdateUserPassword: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
-	}
-
-	log.Infof("Successfully updated user password for user oid %s", userID.String())
-	return c.JSON(http.StatusOK, map[string]string{"message": "User password updated successfully."})
-}
-
-// @Summary Get user by ID
-// @Description Retrieve user details by the provided user ID
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param id path string true "User ID"
-// @Success 200 {object} domain.GetUserResp "User profile details"
-// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
-// @Failure 500 {object} domain.ErrorResp "Failed to get user profile"
-// @Router /users/{id} [get]
-func (a *API) HandleGetUserById(c echo.Context) error {
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		log.Warnf("HandleGetUserById: unable to parse uuid: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
-	}
-
-	user, err := a.Cache.GetUser(userID.String())
-	if err == nil {
-		return c.JSON(http.StatusOK, user)
-	}
-	if err != nil && err != redis.Nil {
-		log.Warnf("HandleGetUserById: %s", err)
-	}
-
-	user, err = a.DB.GetUserById(userID)
-	if err != nil {
-		log.Warnf("HandleGetUserById: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user profile"})
-	}
-
-	rating, err := a.Rating.GetRatingSeparately(user.OID)
-	if err != nil {
-		log.Warnf("HandleGetUserById: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user profile"})
-	}
-
-	err = a.Cache.Set(userID.String(), user)
-	if err != nil {
-		log.Warnf("HandleGetUserById: unable to save cache: %s", err)
-	}
-
-	return c.JSON(http.StatusOK, domain.GetProfileDTO{
-		OID:       user.OID,
-		Nickname:  user.Nickname,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		State:     user.State,
-		Role:      user.Role,
-		Rating:    rating,
-	})
-}
-
-// @Summary Get a paginated list of users
-// @Description Retrieve a paginated list of user profiles
-// @Tags users
-// @Accept json
-// @Produce json
-// @Param page query int false "Page number"
-// @Param limit query int false "Items per page"
-// @Success 200 {object} domain.GetUserListResp "Paginated list of user profiles"
-// @Failure 500 {object} domain.ErrorResp "Failed to get users list"
-// @Router /users [get]
-func (a *API) HandleGetUsersList(c echo.Context) error {
-
-	pageNumber, err := strconv.Atoi(c.QueryParam("page"))
-	if err != nil || pageNumber < 1 {
-		pageNumber = 1
-	}
-
-	pageSize, err := strconv.Atoi(c.QueryParam("limit"))
-	if err != nil || pageSize < 1 {
-		pageSize = defaultPageSize
-	}
-
-	offset := (pageNumber - 1) * pageSize
-
-	usersList, err := a.Cache.GetUsersList(a.Cache.MakeKey(pageSize, offset))
-	if err == nil {
-		return c.JSON(http.StatusOK, usersList)
-	}
-	if err != nil && err != redis.Nil {
-		log.Warnf("HandleGetUsersList: %s", err)
-	}
-
-	users, err := a.DB.GetUsersList(pageSize, offset)
-	if err != nil {
-		log.Warnf("HandleGetUsersList: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users list"})
-	}
-
-	var oids []uuid.UUID
-
-	for _, user := range users {
-		oids = append(oids, user.OID)
-	}
-
-	ratings, err := a.Rating.GetRatingForList(oids)
-	if err != nil {
-		log.Warnf("HandleGetUsersList: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users list"})
-	}
-
-	fmt.Println(len(users), ratings)
-
-	for i, user := range users {
-
-		if rating, ok := ratings[user.OID]; ok {
-			users[i].Rating = rating
-		}
-
-	}
-
-	totalUsers := len(users)
-
-	if len(users) == pageSize || pageNumber > 0 {
-		totalUsers, err = a.DB.GetUsersCount()
-		if err != nil {
-			log.Warnf("HandleGetUsersList: %s", err)
-			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users amount"})
-		}
-	}
-
-	usersList.CurrentPage = pageNumber
-	usersList.TotalItems = totalUsers
-	usersList.Users = users
-
-	err = a.Cache.Set(a.Cache.MakeKey(pageSize, pageNumber), usersList)
-	if err != nil {
-		log.Warnf("HandleGetUsersList: unable to save cache: %s", err)
-	}
-
-	return c.JSON(http.StatusOK, usersList)
-
-}
-
-// @Summary Delete user by ID
-// @Description Delete a user profile by the provided user ID
-// @Tags users
-// @Accept json
-// @Produce json
-// @Success 200 {string} string "Profile successfully deleted"
-// @Failure 400 {object} domain.ErrorResp
-// @Failure 500 {object} domain.ErrorResp
-// @Router /users/{id} [delete]
-func (a *API) HandleDeleteUser(c echo.Context) error {
-	userRoleFromAuth := c.Get("role").(domain.Role)
-	userIDFromAuth := c.Get("oid").(uuid.UUID)
-
-	userID, err := uuid.Parse(c.Param("id"))
-	if err != nil {
-		log.Warnf("HandleUpdateUserProfile - unable to convert string to uuid: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-
-	if userID != userIDFromAuth && userRoleFromAuth != domain.Admin {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
-	}
-
-	err = a.DB.DeleteUser(userID)
-	if err != nil {
-		log.Warnf("HandleUpdateUserProfile: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error happaned, unable to delete profile"})
-	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "Profile successfully deleted"})
-}
-
-// @Summary Vote
-// @Description Vote for a user by id
-// @Tags vote
-// @Accept json
-// @Produce json
-// @Param vote body domain.VoteReq true "Vote credentials"
-// @Success 200 {object} domain.MessageResp
-// @Failure 400 {object} domain.ErrorResp
-// @Failure 500 {object} domain.ErrorResp
-// @Router /vote [post]
-func (a *API) HandleVote(c echo.Context) error {
-	userIDFromAuth := c.Get("oid").(uuid.UUID)
-	var vote domain.VoteDTO
-	if err := c.Bind(&vote); err != nil {
-		log.Warnf("HandleVote - unable to decode JSON: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-	vote.FromOID = userIDFromAuth
-	vote.VotedAt = time.Now().UTC()
-	if vote.FromOID == vote.ToOID {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You can't rate yourself"})
-	} else if vote.EmojiId > 5 || vote.EmojiId < 1 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong value"})
-	}
-
-	_, voteExists, err := a.Rating.GetVote(vote)
-	if err != nil && err != sql.ErrNoRows {
-		log.Warnf("HandleVote: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to check existance of vote"})
-	}
-	if voteExists {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You already rated this user"})
-	}
-
-	lastVoted, err := a.Rating.LastVotedAt(vote)
-	if err != nil {
-		log.Warnf("HandleVote: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to existance of vote"})
-	}
-	if lastVoted.Add(1 * time.Hour).After(time.Now().UTC()) {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You already voted in last hour, users can vote only one time per hour"})
-	}
-
-	err = a.Rating.RateProfile(vote)
-	if err != nil {
-		log.Warnf("HandleVote: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to change the rating"})
-	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "Your vote has been submitted"})
-}
-
-// @Summary Change Vote
-// @Description Change Vote for a user by id
-// @Tags vote
-// @Accept json
-// @Produce json
-// @Param vote body domain.VoteReq true "Vote credentials"
-// @Success 200 {object} domain.MessageResp
-// @Failure 400 {object} domain.ErrorResp
-// @Failure 500 {object} domain.ErrorResp
-// @Router /vote [put]
-func (a *API) HandleChangeVote(c echo.Context) error {
-	userIDFromAuth := c.Get("oid").(uuid.UUID)
-	var vote domain.VoteDTO
-	if err := c.Bind(&vote); err != nil {
-		log.Warnf("HandleChangeVote - unable to decode JSON: %s", err)
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
-	}
-	vote.FromOID = userIDFromAuth
-	vote.VotedAt = time.Now().UTC()
-
-	if vote.EmojiId > 5 || vote.EmojiId < 1 {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong value"})
-	}
-
-	dbVote, _, err := a.Rating.GetVote(vote)
-	if err != nil {
-		log.Warnf("HandleChangeVote: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to get the vote"})
-	}
-
-	if dbVote.EmojiId == vote.EmojiId {
-		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Vote is the same as before"})
-	}
-	err = a.Rating.UpdateProfileRating(vote, dbVote.EmojiId)
-	if err != nil {
-		log.Warnf("HandleChangeVote: %s", err)
-		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to change the vote"})
-	}
-	return c.JSON(http.StatusOK, map[string]string{"message": "Your vote has been changed"})
-}
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/redis/go-redis/v9"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/export"
+	"github.com/sosshik/rest-user-management/cmd/internal/leaderboard"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+	"github.com/sosshik/rest-user-management/pkg/config"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type API struct {
+	Orgs      domain.OrganizationManager
+	DB        domain.UserProfileManager
+	Cache     domain.CacheInterface
+	Rating    domain.StatsManager
+	Follows   domain.FollowManager
+	Blocks    domain.BlockManager
+	Prefs     domain.PreferencesManager
+	Groups    domain.GroupManager
+	Invites   domain.InviteManager
+	Audit     domain.AuditManager
+	Config    *config.Config
+	Nicknames *nickname.Validator
+	Exporter  *export.Exporter
+
+	Leaderboard *leaderboard.Refresher
+
+	// orgs caches organizations by slug
+	orgs sync.Map
+}
+
+type CustomClaims struct {
+	OID    uuid.UUID   `json:"oid"`
+	Role   domain.Role `json:"user_role"`
+	Tenant uuid.UUID   `json:"tenant"`
+	jwt.StandardClaims
+}
+
+func (a *API) BasicAuth(username, password string, c echo.Context) (bool, error) {
+	tenant := tenantOf(c)
+
+	passwordHash, err := a.DB.GetPassword(tenant, username)
+	if err != nil {
+		log.Warn(err)
+		return false, err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(passwordHash), []byte(password))
+	if err != nil {
+		log.Warnf("auth: wrong password: %s", err)
+		return false, err
+	}
+	return true, nil
+}
+
+// hasReservedName tells whether a name would be taken for an encrypted one once stored.
+func hasReservedName(firstName, lastName string) bool {
+	return pii.IsReserved(firstName) || pii.IsReserved(lastName)
+}
+
+func CheckPassword(psw string) error {
+
+	if len(psw) < 8 {
+
+		return errors.New("password is too short, should be at least 8 symbols")
+
+	}
+
+	var lower, upper, number, symbol bool
+
+	for _, letter := range psw {
+
+		if unicode.IsLower(letter) {
+			lower = true
+		}
+		if unicode.IsUpper(letter) {
+			upper = true
+		}
+		if unicode.IsNumber(letter) {
+			number = true
+		}
+		if unicode.IsSymbol(letter) || unicode.IsPunct(letter) {
+			symbol = true
+		}
+	}
+
+	if lower && upper && number && symbol {
+		return nil
+	}
+	return errors.New("wrong password format: password must contatin at least 1 upper case letter, 1 lower case letter, 1 number and 1 symbol")
+}
+
+func (a *API) JWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		tokenString, _ := strings.CutPrefix(c.Request().Header.Get("Authorization"), "Bearer ")
+		if tokenString == "" {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Missing token"})
+		}
+
+		token, err := jwt.ParseWithClaims(tokenString, &CustomClaims{}, func(token *jwt.Token) (interface{}, error) {
+			return []byte(os.Getenv("JWT_KEY")), nil
+		})
+
+		if err != nil || !token.Valid {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+
+		claims, ok := token.Claims.(*CustomClaims)
+
+		if !ok {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error with authentication, please re-login."})
+		}
+
+		// tokens issued before organizations were introduced don't have a tenant and have to be renewed,
+		// tokens with an audience are issued for other purposes, e.g. export downloads
+		if claims.Tenant == uuid.Nil || claims.Audience != "" {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+		// a token is never accepted in an organization other than the one it was issued in
+		if explicit, _ := c.Get("tenant_explicit").(bool); explicit && tenantOf(c) != claims.Tenant {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid token"})
+		}
+
+		state, err := a.DB.GetUserState(claims.Tenant, claims.OID)
+		if err != nil {
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error with authentication, please re-login."})
+		}
+		if state != 1 {
+			return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Your profile is banned or deleted"})
+		}
+
+		c.Set("tenant", claims.Tenant)
+		c.Set("oid", claims.OID)
+		c.Set("role", claims.Role)
+
+		return next(c)
+	}
+}
+
+func (a *API) CreateToken(tenant uuid.UUID, nickname string) (string, error) {
+
+	user, err := a.DB.GetUserForToken(tenant, nickname)
+	if err != nil {
+		return "", err
+	}
+
+	if user.State != domain.Active {
+		return "", errors.New("unable to create JWT token: user is not in active status")
+	}
+
+	claims := &CustomClaims{
+		OID:    user.OID,
+		Role:   user.Role,
+		Tenant: tenant,
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(24 * time.Hour).Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_KEY")))
+	if err != nil {
+		return "", fmt.Errorf("unable to create JWT token: %w", err)
+	}
+
+	return tokenString, nil
+}
+
+// @Summary Create a user profile
+// @Description Create a new user profile with the provided information. An invite code is required when registration is invite-only, users registered with an invite get its role and group
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body domain.CreateUserReq true "User profile details"
+// @Success 201 {object} domain.CreateUserResp
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 403 {object} domain.ErrorResp "Registration is closed"
+// @Failure 500 {object} domain.ErrorResp "Failed to create user profile"
+// @Router /users [post]
+func (a *API) HandleCreateUserProfile(c echo.Context) error {
+	tenant := tenantOf(c)
+
+	var user domain.UserProfileDTO
+	if err := c.Bind(&user); err != nil {
+		log.Warnf("HandleCreateUserProfile - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	switch a.Config.Registration.Mode {
+	case config.RegistrationOpen:
+	case config.RegistrationInviteOnly:
+		if user.InviteCode == "" {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invite code is required"})
+		}
+	default:
+		return c.JSON(http.StatusForbidden, map[string]string{"error": "Registration is closed"})
+	}
+
+	if hasReservedName(user.FirstName, user.LastName) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "First and last names can't start with \"pii:\""})
+	}
+
+	err := CheckPassword(user.Password)
+	if err != nil {
+		log.Warnf("HandleCreateUserProfile - user provided wrong password: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(user.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Warnf("HandleCreateUserProfile - unable to generate hash for password: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unable to generate hash for password"})
+	}
+	user.Password = string(hash)
+	user.OID = uuid.New()
+	user.CreatedAt = time.Now().UTC()
+	user.UpdatedAt = time.Now().UTC()
+	user.State = domain.Active
+	// the role can only be given by an invite
+	user.Role = domain.Usr
+
+	err = a.prepareNickname(tenant, &user, user.OID)
+	if err != nil {
+		if isNicknamePolicyError(err) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Warnf("HandleCreateUserProfile: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create user profile"})
+	}
+
+	if user.InviteCode != "" {
+		created, err := a.Invites.CreateUserProfileWithInvite(tenant, user, user.InviteCode)
+		if err != nil {
+			log.Warnf("HandleCreateUserProfile: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create user profile"})
+		}
+		if !created {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invite code is invalid or expired"})
+		}
+	} else {
+		err = a.DB.CreateUserProfile(tenant, user)
+		if err != nil {
+			log.Warnf("HandleCreateUserProfile: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create user profile"})
+		}
+	}
+
+	after := profileFields(user)
+	if user.InviteCode != "" {
+		after["invite_code"] = user.InviteCode
+	}
+	a.audit(c, tenant, domain.AuditRecord{ActorOID: user.OID, TargetOID: user.OID, Action: domain.AuditCreate, After: after})
+
+	log.Infof("Successfully created user profile for user %s with oid %s", user.Nickname, user.OID.String())
+	return c.JSON(http.StatusCreated, map[string]string{
+		"oid":     user.OID.String(),
+		"message": "User profile created successfully.",
+	})
+}
+
+// @Summary Log in and generate JWT token
+// @Description Log in with the provided credentials and generate a JWT token
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param user body domain.LoginReq true "User credentials"
+// @Success 200 {object} domain.LoginResp
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 401 {object} domain.ErrorResp "Failed to log in"
+// @Router /users/login [post]
+func (a *API) HandleLogIn(c echo.Context) error {
+	tenant := tenantOf(c)
+
+	var user domain.UserProfileDTO
+	if err := c.Bind(&user); err != nil {
+		log.Warnf("HandleLogIn - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	token, err := a.CreateToken(tenant, user.Nickname)
+	if err != nil {
+		log.Warnf("HandleLogIn: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Failed to log in"})
+	}
+
+	log.Infof("JWT token for user %s with oid %s", user.Nickname, user.OID.String())
+
+	c.Response().Header().Set("x-auth-token", "Bearer "+token)
+
+	return c.JSON(http.StatusOK, map[string]string{
+		"token":   token,
+		"message": "Successfully logged in",
+	})
+
+}
+
+// @Summary Update user profile
+// @Description Update an existing user profile with the provided information. Owners of groups are permitted to update profiles of the group members.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param user body domain.UpdateUserReq true "User credentials"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 500 {object} domain.ErrorResp "Failed to update user profile"
+// @Router /users/{id} [put]
+func (a *API) HandleUpdateUserProfile(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdateUserProfile - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	permitted, err := a.canEditProfile(tenant, userID, userIDFromAuth, userRoleFromAuth)
+	if err != nil {
+		log.Warnf("HandleUpdateUserProfile: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
+	}
+	if !permitted {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
+	}
+
+	var updateUser domain.UserProfileDTO
+	if err := c.Bind(&updateUser); err != nil {
+		log.Warnf("HandleUpdateUserProfile - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if hasReservedName(updateUser.FirstName, updateUser.LastName) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "First and last names can't start with \"pii:\""})
+	}
+
+	currentUser, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleUpdateUserProfile: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
+	}
+
+	if nickname.Normalize(updateUser.Nickname) != currentUser.Nickname {
+		err = a.prepareNickname(tenant, &updateUser, userID)
+		if err == nil && userRoleFromAuth == domain.Usr {
+			err = a.checkNicknameCooldown(tenant, userID)
+		}
+	} else {
+		err = a.keepNickname(tenant, &updateUser, currentUser.Nickname, userID)
+	}
+	if err != nil {
+		if isNicknamePolicyError(err) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Warnf("HandleUpdateUserProfile: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
+	}
+
+	updateUser.UpdatedAt = time.Now().UTC()
+
+	err = a.DB.UpdateUserProfile(tenant, updateUser, userID)
+	if err != nil {
+		log.Warnf("HandleUpdateUserProfile: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditUpdate, Before: profileFields(currentUser), After: profileFields(updateUser)})
+
+	log.Infof("Successfully updated user profile for user %s with oid %s", updateUser.Nickname, userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "User profile updated successfully."})
+}
+
+// @Summary Update user password
+// @Description Update the password for the authenticated user or admin
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param user body domain.UpdatePasswordReq true "User credentials"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 500 {object} domain.ErrorResp "Failed to update user password"
+// @Router /users/{id}/password [put]
+func (a *API) HandleUpdateUserPassword(c echo.Context) error {
+	tenant := tenantOf(c)
+
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdateUserPassword - unable to convert string to uuid: %s", err)
+		return err
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
+	}
+
+	var updatePass domain.UserProfileDTO
+	if err := c.Bind(&updatePass); err != nil {
+		log.Warnf("HandleUpdateUserPassword - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	err = CheckPassword(updatePass.Password)
+	if err != nil {
+		log.Warnf("HandleUpdateUserPassword - user provided wrong password: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	newPass, err := bcrypt.GenerateFromPassword([]byte(updatePass.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Warnf("HandleUpdateUserPassword - unable to generate hash for password: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unable to generate hash for password"})
+	}
+
+	err = a.DB.UpdatePassword(tenant, string(newPass), userID)
+	if err != nil {
+		log.Warnf("HandleUpdateUserPassword: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user profile"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditPassword, After: map[string]interface{}{"password": string(newPass)}})
+
+	log.Infof("Successfully updated user password for user oid %s", userID.String())
+	return c.JSON(http.StatusOK, map[string]string{"message": "User password updated successfully."})
+}
+
+// @Summary Get user by ID
+// @Description Retrieve user details by the provided user ID
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param Authorization header string false "Optional bearer token, profiles are rendered according to privacy settings of the user and identity of the viewer"
+// @Param X-API-Version header int false "1 renders the rating as a string, e.g. ❤️:3; 😂:1;"
+// @Success 200 {object} domain.GetProfileDTO "User profile details"
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to get user profile"
+// @Router /users/{id} [get]
+func (a *API) HandleGetUserById(c echo.Context) error {
+	tenant := tenantOf(c)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetUserById: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	viewerID, viewerRole := viewer(c)
+
+	// the profile is cached as it is stored and rendered for the viewer on every request
+	user, err := a.Cache.GetUser(tenant, userID.String())
+	if err != nil {
+		if err != redis.Nil {
+			log.Warnf("HandleGetUserById: %s", err)
+		}
+
+		user, err = a.DB.GetUserById(tenant, userID)
+		if err != nil {
+			log.Warnf("HandleGetUserById: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user profile"})
+		}
+
+		err = a.Cache.Set(tenant, userID.String(), user)
+		if err != nil {
+			log.Warnf("HandleGetUserById: unable to save cache: %s", err)
+		}
+	}
+	if user.State == domain.Deleted || !canSeeProfile(user, viewerID, viewerRole) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	var rating domain.Rating
+	if canSeeRatingBreakdown(user, viewerID, viewerRole) {
+		var excluded []uuid.UUID
+		if a.Config.Block.ExcludeVotes {
+			excluded, err = a.Blocks.GetBlockedOIDs(tenant, user.OID)
+			if err != nil {
+				log.Warnf("HandleGetUserById: %s", err)
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user profile"})
+			}
+		}
+		rating, err = a.Rating.GetRatingBreakdown(tenant, user.OID, excluded)
+	} else {
+		rating.Total, err = a.Rating.GetRating(tenant, user.OID)
+	}
+	if err != nil {
+		log.Warnf("HandleGetUserById: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user profile"})
+	}
+
+	followers, following, err := a.Follows.GetFollowCounts(tenant, user.OID)
+	if err != nil {
+		log.Warnf("HandleGetUserById: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user profile"})
+	}
+
+	user = renderProfile(user, viewerID, viewerRole)
+
+	profile := domain.GetProfileDTO{
+		OID:       user.OID,
+		Nickname:  user.Nickname,
+		FirstName: user.FirstName,
+		LastName:  user.LastName,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+		State:     user.State,
+		Role:      user.Role,
+		Rating:    rating,
+
+		FollowersCount: followers,
+		FollowingCount: following,
+
+		Attributes: user.Attributes,
+	}
+	if apiVersion(c) == 1 {
+		return c.JSON(http.StatusOK, domain.GetProfileV1DTO{GetProfileDTO: profile, Rating: rating.String()})
+	}
+	return c.JSON(http.StatusOK, profile)
+}
+
+// @Summary Get a paginated list of users
+// @Description Retrieve a paginated list of user profiles, either by page number or by cursor.
+// @Description Cursor pagination is keyed on creation time and works with the default sort only.
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param Authorization header string false "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer"
+// @Param after query string false "Cursor of the next page, empty value starts from the first page"
+// @Param before query string false "Cursor of the previous page, empty value starts from the last page"
+// @Param include_total query bool false "Count total amount of users, defaults to true for page numbers and false for cursors"
+// @Param attr.name query string false "Filter by public custom attribute, e.g. attr.location=Berlin"
+// @Param state query int false "Filter by state"
+// @Param role query int false "Filter by role"
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param updated_from query string false "Updated at or after, RFC3339"
+// @Param updated_to query string false "Updated before, RFC3339"
+// @Param sort query string false "Sort by nickname, created_at or rating" Enums(nickname, created_at, rating)
+// @Param order query string false "Sort order" Enums(asc, desc)
+// @Success 200 {object} domain.GetUserListResp "Paginated list of user profiles"
+// @Failure 400 {object} domain.ErrorResp "Wrong filter"
+// @Failure 500 {object} domain.ErrorResp "Failed to get users list"
+// @Router /users [get]
+func (a *API) HandleGetUsersList(c echo.Context) error {
+	tenant := tenantOf(c)
+
+	page, err := pageQuery(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	filter, err := usersFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	if page.Keyset && filter.SortBy != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Cursor pagination is supported only when sorting by created_at"})
+	}
+	// unlisted and hidden profiles are never shown in the list
+	filter.Listed = true
+	viewerID, viewerRole := viewer(c)
+
+	// the page isn't cached if the key can't be made, as it may be stale by then
+	var usersList domain.Pagination[domain.UserProfileDTO]
+	cacheKey, err := a.Cache.MakeKey(tenant, page, filter)
+	if err == nil {
+		usersList, err = a.Cache.GetUsersList(tenant, cacheKey)
+		if err == nil {
+			return c.JSON(http.StatusOK, renderUsersList(usersList, viewerID, viewerRole))
+		}
+	}
+	if err != nil && err != redis.Nil {
+		log.Warnf("HandleGetUsersList: %s", err)
+	}
+
+	var users []domain.UserProfileDTO
+	if page.Keyset {
+		users, usersList.Next, usersList.Prev, err = a.getUsersByCursor(tenant, page, filter)
+	} else {
+		users, err = a.DB.GetUsersList(tenant, page.Size, page.Offset, filter)
+		usersList.CurrentPage = page.Offset/page.Size + 1
+	}
+	if err != nil {
+		log.Warnf("HandleGetUsersList: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users list"})
+	}
+
+	var oids []uuid.UUID
+
+	for _, user := range users {
+		oids = append(oids, user.OID)
+	}
+
+	ratings, err := a.Rating.GetRatingForList(tenant, oids)
+	if err != nil {
+		log.Warnf("HandleGetUsersList: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users list"})
+	}
+
+	for i, user := range users {
+		users[i].Rating = ratings[user.OID].Total
+	}
+
+	if page.IncludeTotal {
+		totalUsers, err := a.DB.GetUsersCount(tenant, filter)
+		if err != nil {
+			log.Warnf("HandleGetUsersList: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get users amount"})
+		}
+		usersList.TotalItems = &totalUsers
+	}
+
+	usersList.Users = users
+
+	if cacheKey != "" {
+		err = a.Cache.Set(tenant, cacheKey, usersList)
+		if err != nil {
+			log.Warnf("HandleGetUsersList: unable to save cache: %s", err)
+		}
+	}
+
+	return c.JSON(http.StatusOK, renderUsersList(usersList, viewerID, viewerRole))
+
+}
+
+// renderUsersList renders every user of the list for the viewer, leaving the cached list intact.
+func renderUsersList(usersList domain.Pagination[domain.UserProfileDTO], viewerID uuid.UUID, viewerRole domain.Role) domain.Pagination[domain.UserProfileDTO] {
+	users := make([]domain.UserProfileDTO, 0, len(usersList.Users))
+	for _, user := range usersList.Users {
+		users = append(users, renderProfile(user, viewerID, viewerRole))
+	}
+	usersList.Users = users
+	return usersList
+}
+
+// pageParams reads "page" and "limit" query params, falling back to the first page of defaultPageSize.
+func pageParams(c echo.Context) (int, int) {
+	pageNumber, err := strconv.Atoi(c.QueryParam("page"))
+	if err != nil || pageNumber < 1 {
+		pageNumber = 1
+	}
+
+	pageSize, err := strconv.Atoi(c.QueryParam("limit"))
+	if err != nil || pageSize < 1 {
+		pageSize = defaultPageSize
+	}
+
+	return pageNumber, pageSize
+}
+
+// @Summary Delete user by ID
+// @Description Mark a user profile as deleted, it can be restored by admins until it is purged
+// @Tags users
+// @Accept json
+// @Produce json
+// @Success 200 {string} string "Profile successfully deleted"
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp
+// @Router /users/{id} [delete]
+func (a *API) HandleDeleteUser(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdateUserProfile - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
+	}
+
+	state, err := a.DB.GetUserState(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleDeleteUser: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error happaned, unable to delete profile"})
+	}
+
+	err = a.DB.DeleteUser(tenant, userID, a.Config.Deletion.ReleaseNickname)
+	if err != nil {
+		log.Warnf("HandleUpdateUserProfile: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Error happaned, unable to delete profile"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditDelete,
+		Before: map[string]interface{}{"state": state}, After: map[string]interface{}{"state": int(domain.Deleted)}})
+
+	err = a.Cache.Delete(tenant, userID.String())
+	if err == nil {
+		err = a.Cache.InvalidateLists(tenant)
+	}
+	if err != nil {
+		log.Warnf("HandleDeleteUser: unable to invalidate cache: %s", err)
+	}
+
+	log.Infof("Successfully deleted user profile with oid %s", userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Profile successfully deleted"})
+}
+
+// @Summary Restore deleted user
+// @Description Restore a deleted user profile that wasn't purged yet
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.RestoreUserResp
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp
+// @Router /admin/users/{id}/restore [post]
+func (a *API) HandleRestoreUser(c echo.Context) error {
+	tenant := tenantOf(c)
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to restore profiles."})
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleRestoreUser - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	nickname, err := a.DB.RestoreUser(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleRestoreUser: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to restore profile"})
+	}
+
+	// restored nickname doesn't have a skeleton yet, so it's filled like on a regular profile update
+	user, err := a.DB.GetUserById(tenant, userID)
+	if err == nil {
+		err = a.keepNickname(tenant, &user, user.Nickname, userID)
+	}
+	if err == nil {
+		user.UpdatedAt = time.Now().UTC()
+		err = a.DB.UpdateUserProfile(tenant, user, userID)
+	}
+	if err != nil {
+		log.Warnf("HandleRestoreUser: unable to update nickname skeleton: %s", err)
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditRestore,
+		Before: map[string]interface{}{"state": int(domain.Deleted)}, After: map[string]interface{}{"state": int(domain.Active), "nickname": nickname}})
+
+	err = a.Cache.Delete(tenant, userID.String())
+	if err == nil {
+		err = a.Cache.InvalidateLists(tenant)
+	}
+	if err != nil {
+		log.Warnf("HandleRestoreUser: unable to invalidate cache: %s", err)
+	}
+
+	log.Infof("Successfully restored user profile %s with oid %s", nickname, userID)
+	return c.JSON(http.StatusOK, domain.RestoreUserResp{
+		OID:      userID,
+		Nickname: nickname,
+		Message:  "Profile successfully restored",
+	})
+}
+
+// @Summary Vote
+// @Description Vote for a user by id
+// @Tags vote
+// @Accept json
+// @Produce json
+// @Param vote body domain.VoteReq true "Vote credentials"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp
+// @Router /vote [post]
+func (a *API) HandleVote(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+	var vote domain.VoteDTO
+	if err := c.Bind(&vote); err != nil {
+		log.Warnf("HandleVote - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	vote.FromOID = userIDFromAuth
+	vote.VotedAt = time.Now().UTC()
+	if vote.FromOID == vote.ToOID {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You can't rate yourself"})
+	} else if vote.EmojiId > 5 || vote.EmojiId < 1 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong value"})
+	}
+
+	// votes are stored apart from profiles, so the user is checked to be in the same organization
+	state, err := a.DB.GetUserState(tenant, vote.ToOID)
+	if err != nil {
+		log.Warnf("HandleVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to change the rating"})
+	}
+	if domain.State(state) != domain.Active {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	blocked, err := a.Blocks.IsBlocked(tenant, vote.ToOID, vote.FromOID)
+	if err != nil {
+		log.Warnf("HandleVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to check whether you are blocked"})
+	}
+	if blocked {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to rate this user"})
+	}
+
+	_, voteExists, err := a.Rating.GetVote(tenant, vote)
+	if err != nil && err != sql.ErrNoRows {
+		log.Warnf("HandleVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to check existance of vote"})
+	}
+	if voteExists {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You already rated this user"})
+	}
+
+	lastVoted, err := a.Rating.LastVotedAt(tenant, vote)
+	if err != nil {
+		log.Warnf("HandleVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to existance of vote"})
+	}
+	if lastVoted.Add(1 * time.Hour).After(time.Now().UTC()) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You already voted in last hour, users can vote only one time per hour"})
+	}
+
+	err = a.Rating.RateProfile(tenant, vote)
+	if err != nil {
+		log.Warnf("HandleVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to change the rating"})
+	}
+
+	// the list is sorted by ratings copied to profiles, a rating that isn't copied is fixed by the next vote or sync-ratings
+	rating, err := a.Rating.GetRating(tenant, vote.ToOID)
+	if err == nil {
+		err = a.DB.SetRating(tenant, vote.ToOID, rating)
+	}
+	if err != nil {
+		log.Warnf("HandleVote: unable to copy the rating: %s", err)
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Your vote has been submitted"})
+}
+
+// @Summary Change Vote
+// @Description Change Vote for a user by id
+// @Tags vote
+// @Accept json
+// @Produce json
+// @Param vote body domain.VoteReq true "Vote credentials"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp
+// @Router /vote [put]
+func (a *API) HandleChangeVote(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+	var vote domain.VoteDTO
+	if err := c.Bind(&vote); err != nil {
+		log.Warnf("HandleChangeVote - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	vote.FromOID = userIDFromAuth
+	vote.VotedAt = time.Now().UTC()
+
+	if vote.EmojiId > 5 || vote.EmojiId < 1 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong value"})
+	}
+
+	blocked, err := a.Blocks.IsBlocked(tenant, vote.ToOID, vote.FromOID)
+	if err != nil {
+		log.Warnf("HandleChangeVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to check whether you are blocked"})
+	}
+	if blocked {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to rate this user"})
+	}
+
+	dbVote, _, err := a.Rating.GetVote(tenant, vote)
+	if err != nil {
+		log.Warnf("HandleChangeVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to get the vote"})
+	}
+
+	if dbVote.EmojiId == vote.EmojiId {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Vote is the same as before"})
+	}
+	err = a.Rating.UpdateProfileRating(tenant, vote, dbVote)
+	if err != nil {
+		log.Warnf("HandleChangeVote: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to change the vote"})
+	}
+	return c.JSON(http.StatusOK, map[string]string{"message": "Your vote has been changed"})
+}
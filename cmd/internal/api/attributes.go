@@ -0,0 +1,297 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+const (
+	attributesSchemaURL  = "attributes.json"
+	attributeQueryPrefix = "attr."
+)
+
+func compileAttributesSchema(schema []byte) (*jsonschema.Schema, error) {
+	compiler := jsonschema.NewCompiler()
+	if err := compiler.AddResource(attributesSchemaURL, bytes.NewReader(schema)); err != nil {
+		return nil, fmt.Errorf("unable to load attributes schema: %w", err)
+	}
+	compiled, err := compiler.Compile(attributesSchemaURL)
+	if err != nil {
+		return nil, fmt.Errorf("unable to compile attributes schema: %w", err)
+	}
+	return compiled, nil
+}
+
+func CheckVisibility(attributes map[string]interface{}, visibility map[string]domain.Visibility) error {
+	for name, v := range visibility {
+		if _, ok := attributes[name]; !ok {
+			return fmt.Errorf("visibility is set for unknown attribute %q", name)
+		}
+		switch v {
+		case domain.VisibilityPublic, domain.VisibilitySelf, domain.VisibilityModerators:
+		default:
+			return fmt.Errorf("wrong visibility %q for attribute %q, should be one of: public, self, moderators", v, name)
+		}
+	}
+	return nil
+}
+
+// VisibleAttributes returns the attributes of user that viewer with viewerRole is allowed to see.
+// Attributes without explicit visibility are public.
+func VisibleAttributes(user domain.UserProfileDTO, viewerID uuid.UUID, viewerRole domain.Role) map[string]interface{} {
+	if len(user.Attributes) == 0 {
+		return nil
+	}
+	if viewerID == user.OID {
+		return user.Attributes
+	}
+
+	visible := make(map[string]interface{})
+	for name, value := range user.Attributes {
+		switch user.AttributesVisibility[name] {
+		case domain.VisibilitySelf:
+			continue
+		case domain.VisibilityModerators:
+			if viewerRole < domain.Moderator {
+				continue
+			}
+		}
+		visible[name] = value
+	}
+	return visible
+}
+
+// attributesFilter collects "attr.<name>=<value>" query params into a filter.
+// Values that are valid JSON scalars (numbers, booleans) are matched as such, anything else as a string.
+func attributesFilter(c echo.Context) map[string]interface{} {
+	filter := make(map[string]interface{})
+	for param, values := range c.QueryParams() {
+		name, ok := strings.CutPrefix(param, attributeQueryPrefix)
+		if !ok || name == "" || len(values) == 0 {
+			continue
+		}
+		var value interface{}
+		if err := json.Unmarshal([]byte(values[0]), &value); err != nil {
+			value = values[0]
+		}
+		switch value.(type) {
+		case float64, bool:
+			filter[name] = value
+		default:
+			filter[name] = values[0]
+		}
+	}
+	return filter
+}
+
+// @Summary Get user attributes
+// @Description Retrieve custom profile attributes that are visible to the authenticated user
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.AttributesResp
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to get user attributes"
+// @Router /users/{id}/attributes [get]
+func (a *API) HandleGetAttributes(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetAttributes: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	user, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleGetAttributes: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user attributes"})
+	}
+
+	resp := domain.AttributesResp{Attributes: VisibleAttributes(user, userIDFromAuth, userRoleFromAuth)}
+	if userID == userIDFromAuth {
+		resp.Visibility = user.AttributesVisibility
+	}
+
+	return c.JSON(http.StatusOK, resp)
+}
+
+// @Summary Update user attributes
+// @Description Replace custom profile attributes, validated against the attributes schema
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param attributes body domain.UpdateAttributesReq true "Attributes and their visibility"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 500 {object} domain.ErrorResp "Failed to update user attributes"
+// @Router /users/{id}/attributes [put]
+func (a *API) HandleUpdateAttributes(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to change other profiles except his own."})
+	}
+
+	var req domain.UpdateAttributesReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleUpdateAttributes - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if req.Attributes == nil {
+		req.Attributes = map[string]interface{}{}
+	}
+	if req.Visibility == nil {
+		req.Visibility = map[string]domain.Visibility{}
+	}
+
+	schema, err := a.DB.GetAttributesSchema(tenant)
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user attributes"})
+	}
+	compiled, err := compileAttributesSchema(schema)
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user attributes"})
+	}
+
+	err = compiled.Validate(req.Attributes)
+	if err != nil {
+		var validationErr *jsonschema.ValidationError
+		if errors.As(err, &validationErr) {
+			log.Warnf("HandleUpdateAttributes - attributes do not match schema: %s", err)
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": validationErr.Error()})
+		}
+		log.Warnf("HandleUpdateAttributes: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user attributes"})
+	}
+
+	err = CheckVisibility(req.Attributes, req.Visibility)
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes - user provided wrong visibility: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	currentUser, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user attributes"})
+	}
+
+	err = a.DB.UpdateAttributes(tenant, req.Attributes, req.Visibility, userID)
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update user attributes"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditAttributes,
+		Before: attributesFields(currentUser.Attributes, currentUser.AttributesVisibility), After: attributesFields(req.Attributes, req.Visibility)})
+
+	// cached list pages hold the profile with its previous attributes, visible to everyone and matched by filters
+	err = a.Cache.Delete(tenant, userID.String())
+	if err == nil {
+		err = a.Cache.InvalidateLists(tenant)
+	}
+	if err != nil {
+		log.Warnf("HandleUpdateAttributes: unable to invalidate cache: %s", err)
+	}
+
+	log.Infof("Successfully updated attributes for user with oid %s", userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "User attributes updated successfully."})
+}
+
+// @Summary Get attributes schema
+// @Description Retrieve the JSON Schema that custom profile attributes are validated against
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Success 200 {object} object
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp "Failed to get attributes schema"
+// @Router /admin/attributes/schema [get]
+func (a *API) HandleGetAttributesSchema(c echo.Context) error {
+	tenant := tenantOf(c)
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to manage attributes schema."})
+	}
+
+	schema, err := a.DB.GetAttributesSchema(tenant)
+	if err != nil {
+		log.Warnf("HandleGetAttributesSchema: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get attributes schema"})
+	}
+
+	return c.JSONBlob(http.StatusOK, schema)
+}
+
+// @Summary Set attributes schema
+// @Description Replace the JSON Schema that custom profile attributes are validated against
+// @Tags attributes
+// @Accept json
+// @Produce json
+// @Param schema body object true "JSON Schema"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Invalid schema"
+// @Failure 500 {object} domain.ErrorResp "Failed to update attributes schema"
+// @Router /admin/attributes/schema [put]
+func (a *API) HandleSetAttributesSchema(c echo.Context) error {
+	tenant := tenantOf(c)
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to manage attributes schema."})
+	}
+
+	var schema json.RawMessage
+	if err := c.Bind(&schema); err != nil {
+		log.Warnf("HandleSetAttributesSchema - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if _, err := compileAttributesSchema(schema); err != nil {
+		log.Warnf("HandleSetAttributesSchema - admin provided wrong schema: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid schema: " + err.Error()})
+	}
+
+	err := a.DB.SetAttributesSchema(tenant, schema)
+	if err != nil {
+		log.Warnf("HandleSetAttributesSchema: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update attributes schema"})
+	}
+
+	log.Infof("Successfully updated attributes schema")
+	return c.JSON(http.StatusOK, map[string]string{"message": "Attributes schema updated successfully."})
+}
+
+// attributesFields returns attributes and their visibility as audited fields, empty ones are left out.
+func attributesFields(attributes map[string]interface{}, visibility map[string]domain.Visibility) map[string]interface{} {
+	fields := map[string]interface{}{}
+	if len(attributes) > 0 {
+		fields["attributes"] = attributes
+	}
+	if len(visibility) > 0 {
+		fields["attributes_visibility"] = visibility
+	}
+	return fields
+}
@@ -0,0 +1,29 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+func TestUpdateAttributesInvalidatesLists(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	user := s.store.addUser(org.ID, "alice", domain.Usr)
+	s.cache.Set(org.ID, user.OID.String(), user)
+	version := s.cache.listsVersion(org.ID)
+
+	rec := s.do(http.MethodPut, "/api/users/"+user.OID.String()+"/attributes", "", s.token(org.ID, user),
+		`{"attributes":{"city":"Kyiv"},"visibility":{"city":"self"}}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("update attributes: status = %d: %s", rec.Code, rec.Body)
+	}
+
+	if got := s.cache.listsVersion(org.ID); got != version+1 {
+		t.Errorf("lists version = %d, want %d", got, version+1)
+	}
+	if _, err := s.cache.GetUser(org.ID, user.OID.String()); err == nil {
+		t.Error("cached profile is kept")
+	}
+}
@@ -0,0 +1,148 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// auditSource returns the user that made the request and where it came from.
+func auditSource(c echo.Context) domain.AuditSource {
+	actor, _ := c.Get("oid").(uuid.UUID)
+	return domain.AuditSource{
+		Actor:     actor,
+		IP:        c.RealIP(),
+		RequestID: c.Response().Header().Get(echo.HeaderXRequestID),
+	}
+}
+
+// auditDiff keeps only the fields that differ between before and after and redacts secrets.
+func auditDiff(before map[string]interface{}, after map[string]interface{}) (map[string]interface{}, map[string]interface{}) {
+	changedBefore := make(map[string]interface{})
+	changedAfter := make(map[string]interface{})
+
+	for field, value := range after {
+		previous, ok := before[field]
+		if ok && reflect.DeepEqual(previous, value) {
+			continue
+		}
+		if ok {
+			changedBefore[field] = previous
+		}
+		changedAfter[field] = value
+	}
+	for field, value := range before {
+		if _, ok := after[field]; !ok {
+			changedBefore[field] = value
+		}
+	}
+
+	domain.RedactSecrets(changedBefore)
+	domain.RedactSecrets(changedAfter)
+	return changedBefore, changedAfter
+}
+
+// profileFields returns the fields of the profile a user can change themselves.
+func profileFields(user domain.UserProfileDTO) map[string]interface{} {
+	return map[string]interface{}{
+		"nickname":   user.Nickname,
+		"first_name": user.FirstName,
+		"last_name":  user.LastName,
+	}
+}
+
+// audit appends records of changes made by the request to the audit log, records without changes are skipped.
+// The change is already made by then, so failing to write the records is logged and doesn't fail the request.
+func (a *API) audit(c echo.Context, tenant uuid.UUID, records ...domain.AuditRecord) {
+	source := auditSource(c)
+	now := time.Now().UTC()
+
+	changed := make([]domain.AuditRecord, 0, len(records))
+	for _, record := range records {
+		record.Before, record.After = auditDiff(record.Before, record.After)
+		if len(record.Before) == 0 && len(record.After) == 0 {
+			continue
+		}
+		if record.ActorOID == uuid.Nil {
+			record.ActorOID = source.Actor
+		}
+		record.IP = source.IP
+		record.RequestID = source.RequestID
+		record.CreatedAt = now
+		changed = append(changed, record)
+	}
+	if len(changed) == 0 {
+		return
+	}
+
+	if err := a.Audit.WriteAudit(tenant, changed); err != nil {
+		log.Errorf("audit: unable to write %d audit records of request %s: %s", len(changed), source.RequestID, err)
+	}
+}
+
+// @Summary Get audit log
+// @Description Retrieve a paginated list of changes made to profiles, groups and invites of the organization, most recent first. Only admins are permitted to view the audit log.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param actor query string false "ID of the user that made the change"
+// @Param target query string false "ID of the changed user or group, invites are audited under the user that created them"
+// @Param action query string false "Action, e.g. update, password, delete, set_role, group_member_add"
+// @Param from query string false "Start of the period, RFC 3339"
+// @Param to query string false "End of the period, exclusive, RFC 3339"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} domain.AuditPage
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp "Failed to get audit log"
+// @Router /admin/audit [get]
+func (a *API) HandleGetAudit(c echo.Context) error {
+	tenant := tenantOf(c)
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to view the audit log."})
+	}
+
+	filter := domain.AuditFilter{Action: domain.AuditAction(c.QueryParam("action"))}
+	for param, dest := range map[string]**uuid.UUID{"actor": &filter.Actor, "target": &filter.Target} {
+		if value := c.QueryParam(param); value != "" {
+			oid, err := uuid.Parse(value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong " + param + " id"})
+			}
+			*dest = &oid
+		}
+	}
+	for param, dest := range map[string]**time.Time{"from": &filter.From, "to": &filter.To} {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong " + param + " time, should be RFC 3339"})
+			}
+			*dest = &t
+		}
+	}
+
+	pageNumber, pageSize := pageParams(c)
+
+	records, err := a.Audit.GetAudit(tenant, filter, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("HandleGetAudit: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get audit log"})
+	}
+
+	total, err := a.Audit.GetAuditCount(tenant, filter)
+	if err != nil {
+		log.Warnf("HandleGetAudit: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get audit log"})
+	}
+
+	if records == nil {
+		records = []domain.AuditRecord{}
+	}
+	return c.JSON(http.StatusOK, domain.AuditPage{TotalItems: total, CurrentPage: pageNumber, Records: records})
+}
@@ -0,0 +1,154 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// @Summary Batch action on users
+// @Description Ban, unban, delete or set role of many users at once, listed by oid or matched by a filter.
+// @Description Moderators can ban, unban and delete users with user role only, setting roles is permitted to admins only.
+// @Description The batch runs in a single transaction, chunked batches run in a transaction per chunk and report progress per chunk.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param batch body domain.BatchReq true "Action and its targets"
+// @Success 200 {object} domain.BatchResultDTO
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 500 {object} domain.ErrorResp "Failed to apply batch action"
+// @Router /admin/users/batch [post]
+func (a *API) HandleBatchUsers(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	if userRoleFromAuth < domain.Moderator {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins and moderators are permitted to run batch actions."})
+	}
+
+	var req domain.BatchReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleBatchUsers - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	switch req.Action {
+	case domain.BatchBan, domain.BatchUnban, domain.BatchDelete:
+	case domain.BatchSetRole:
+		if userRoleFromAuth != domain.Admin {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to change roles."})
+		}
+		if req.Role < domain.Usr || req.Role > domain.Admin {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("wrong role %d", req.Role)})
+		}
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("wrong action %q, should be one of: ban, unban, delete, set_role", req.Action)})
+	}
+
+	if (len(req.OIDs) == 0) == (req.Filter == nil) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Either oids or filter should be set"})
+	}
+	if req.Filter != nil && isEmptyFilter(*req.Filter) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Filter should have at least one condition"})
+	}
+
+	targets, err := a.DB.GetBatchTargets(tenant, req.OIDs, req.Filter, a.Config.Batch.MaxUsers+1)
+	if err != nil {
+		log.Warnf("HandleBatchUsers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to apply batch action"})
+	}
+	if len(targets) > a.Config.Batch.MaxUsers {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("Batch matches more than %d users, please narrow it down", a.Config.Batch.MaxUsers)})
+	}
+
+	result := domain.BatchResultDTO{
+		Action:   req.Action,
+		Matched:  len(targets),
+		Affected: []uuid.UUID{},
+		Skipped:  []domain.BatchSkippedDTO{},
+		Chunks:   []domain.BatchChunkDTO{},
+	}
+
+	found := make(map[uuid.UUID]bool, len(targets))
+	var permitted []uuid.UUID
+	for _, target := range targets {
+		found[target.OID] = true
+		if reason := batchSkipReason(req, target, userIDFromAuth, userRoleFromAuth); reason != "" {
+			result.Skipped = append(result.Skipped, domain.BatchSkippedDTO{OID: target.OID, Reason: reason})
+			continue
+		}
+		permitted = append(permitted, target.OID)
+	}
+	for _, oid := range req.OIDs {
+		if !found[oid] {
+			found[oid] = true
+			result.Skipped = append(result.Skipped, domain.BatchSkippedDTO{OID: oid, Reason: "user not found"})
+		}
+	}
+
+	chunkSize := len(permitted)
+	if req.Chunked && a.Config.Batch.ChunkSize > 0 {
+		chunkSize = a.Config.Batch.ChunkSize
+	}
+
+	for start := 0; start < len(permitted); start += chunkSize {
+		chunk := permitted[start:min(start+chunkSize, len(permitted))]
+		chunkResult := domain.BatchChunkDTO{Chunk: len(result.Chunks) + 1, Users: len(chunk)}
+
+		affected, err := a.DB.ApplyBatchAction(tenant, req.Action, req.Role, chunk, auditSource(c), a.Config.Deletion.ReleaseNickname)
+		if err != nil {
+			log.Warnf("HandleBatchUsers: %s", err)
+			if !req.Chunked {
+				return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to apply batch action"})
+			}
+			chunkResult.Error = "Failed to apply batch action"
+		}
+
+		for _, oid := range affected {
+			if err := a.Cache.Delete(tenant, oid.String()); err != nil {
+				log.Warnf("HandleBatchUsers: unable to invalidate cache: %s", err)
+			}
+		}
+
+		if len(affected) > 0 {
+			if err := a.Cache.InvalidateLists(tenant); err != nil {
+				log.Warnf("HandleBatchUsers: unable to invalidate cache: %s", err)
+			}
+		}
+
+		chunkResult.Affected = len(affected)
+		result.Affected = append(result.Affected, affected...)
+		result.Chunks = append(result.Chunks, chunkResult)
+		log.Infof("Batch %s by %s: chunk %d done, %d of %d users affected", req.Action, userIDFromAuth, chunkResult.Chunk, len(affected), len(chunk))
+	}
+
+	log.Infof("Successfully applied batch %s by %s: %d matched, %d affected, %d skipped", req.Action, userIDFromAuth, result.Matched, len(result.Affected), len(result.Skipped))
+	return c.JSON(http.StatusOK, result)
+}
+
+// batchSkipReason explains why target is left out of the batch, or returns an empty string if the action applies to it.
+func batchSkipReason(req domain.BatchReq, target domain.UserProfileDTO, actorID uuid.UUID, actorRole domain.Role) string {
+	switch {
+	case target.OID == actorID:
+		return "batch actions can't be applied to own profile"
+	case actorRole != domain.Admin && target.Role >= domain.Moderator:
+		return "moderators are permitted to manage users with user role only"
+	case req.Action == domain.BatchBan && target.State == domain.Banned:
+		return "user is already banned"
+	case req.Action == domain.BatchUnban && target.State == domain.Active:
+		return "user is not banned"
+	case req.Action == domain.BatchSetRole && target.Role == req.Role:
+		return "user already has this role"
+	}
+	return ""
+}
+
+func isEmptyFilter(filter domain.UsersFilter) bool {
+	return len(filter.Attributes) == 0 && filter.State == nil && filter.Role == nil &&
+		filter.CreatedFrom == nil && filter.CreatedTo == nil && filter.UpdatedFrom == nil && filter.UpdatedTo == nil
+}
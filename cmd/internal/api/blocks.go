@@ -0,0 +1,126 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// @Summary Block user
+// @Description Block the user, blocked users can't rate or follow the profile and follows between the users are removed
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID to block"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to block user"
+// @Router /users/{id}/block [post]
+func (a *API) HandleBlock(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleBlock: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID == userIDFromAuth {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You can't block yourself"})
+	}
+
+	found, err := a.Blocks.Block(tenant, userIDFromAuth, userID)
+	if err != nil {
+		log.Warnf("HandleBlock: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to block user"})
+	}
+	if !found {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	log.Infof("User %s blocked user %s", userIDFromAuth, userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "User blocked successfully."})
+}
+
+// @Summary Unblock user
+// @Description Unblock the user, removed follows aren't restored
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID to unblock"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to unblock user"
+// @Router /users/{id}/block [delete]
+func (a *API) HandleUnblock(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUnblock: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	err = a.Blocks.Unblock(tenant, userIDFromAuth, userID)
+	if err != nil {
+		log.Warnf("HandleUnblock: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to unblock user"})
+	}
+
+	log.Infof("User %s unblocked user %s", userIDFromAuth, userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "User unblocked successfully."})
+}
+
+// @Summary Get blocked users
+// @Description Retrieve a paginated list of users blocked by the user, most recently blocked first
+// @Tags blocks
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} domain.GetUserListResp "Paginated list of blocked users"
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to get blocked users"
+// @Router /users/{id}/blocks [get]
+func (a *API) HandleGetBlockedUsers(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetBlockedUsers: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to view users blocked by other users."})
+	}
+
+	pageNumber, pageSize := pageParams(c)
+
+	users, err := a.Blocks.GetBlockedUsers(tenant, userID, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("HandleGetBlockedUsers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get blocked users"})
+	}
+
+	total, err := a.Blocks.GetBlockedUsersCount(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleGetBlockedUsers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get blocked users"})
+	}
+
+	return c.JSON(http.StatusOK, renderUsersList(domain.Pagination[domain.UserProfileDTO]{
+		TotalItems:  &total,
+		CurrentPage: pageNumber,
+		Users:       users,
+	}, userIDFromAuth, userRoleFromAuth))
+}
@@ -0,0 +1,112 @@
+package api
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+var errWrongCursor = errors.New("wrong cursor")
+
+// encodeCursor makes an opaque cursor pointing at user, clients should pass it back as is.
+func encodeCursor(user domain.UserProfileDTO) string {
+	// Cursor consists of a time and an uuid only, so it can always be marshalled.
+	encoded, _ := json.Marshal(domain.Cursor{CreatedAt: user.CreatedAt, OID: user.OID})
+	return base64.RawURLEncoding.EncodeToString(encoded)
+}
+
+func decodeCursor(cursor string) (*domain.Cursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errWrongCursor
+	}
+
+	var c domain.Cursor
+	if err := json.Unmarshal(decoded, &c); err != nil || c.CreatedAt.IsZero() || c.OID == uuid.Nil {
+		return nil, errWrongCursor
+	}
+	return &c, nil
+}
+
+// pageQuery reads pagination query params. Presence of "after" or "before" switches to keyset mode,
+// empty values start from the first or the last page respectively.
+// Total amount of items is counted by default in offset mode only, "include_total" overrides it.
+func pageQuery(c echo.Context) (domain.PageQuery, error) {
+	pageNumber, pageSize := pageParams(c)
+	page := domain.PageQuery{Size: pageSize, Offset: (pageNumber - 1) * pageSize}
+
+	params := c.QueryParams()
+	if params.Has("after") && params.Has("before") {
+		return domain.PageQuery{}, fmt.Errorf("after and before can not be used together")
+	}
+	if params.Has("after") || params.Has("before") {
+		cursor, err := decodeCursor(params.Get("after") + params.Get("before"))
+		if err != nil {
+			return domain.PageQuery{}, err
+		}
+		page = domain.PageQuery{Size: pageSize, Keyset: true, Cursor: cursor, Backward: params.Has("before")}
+	}
+
+	page.IncludeTotal = !page.Keyset
+	if param := c.QueryParam("include_total"); param != "" {
+		includeTotal, err := strconv.ParseBool(param)
+		if err != nil {
+			return domain.PageQuery{}, fmt.Errorf("wrong include_total %q, should be true or false", param)
+		}
+		page.IncludeTotal = includeTotal
+	}
+
+	return page, nil
+}
+
+// getUsersByCursor returns the users of a keyset page in list order along with cursors of the neighbouring pages,
+// cursors are empty when there is nothing to go to.
+func (a *API) getUsersByCursor(tenant uuid.UUID, page domain.PageQuery, filter domain.UsersFilter) ([]domain.UserProfileDTO, string, string, error) {
+	// Going backward scans the list in reverse order, one extra user tells whether there is a page beyond this one.
+	ascending := filter.Desc == page.Backward
+	users, err := a.DB.GetUsersByCursor(tenant, page.Size+1, page.Cursor, ascending, filter)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	more := len(users) > page.Size
+	if more {
+		users = users[:page.Size]
+	}
+	if page.Backward {
+		slices.Reverse(users)
+	}
+	if len(users) == 0 {
+		return users, "", "", nil
+	}
+
+	var next, prev string
+	first, last := encodeCursor(users[0]), encodeCursor(users[len(users)-1])
+	if page.Backward {
+		if more {
+			prev = first
+		}
+		if page.Cursor != nil {
+			next = last
+		}
+	} else {
+		if more {
+			next = last
+		}
+		if page.Cursor != nil {
+			prev = first
+		}
+	}
+	return users, next, prev, nil
+}
@@ -0,0 +1,163 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// downloadAudience marks download tokens, they are signed with the same key as auth tokens,
+// so JWTMiddleware rejects tokens with any audience and downloads require this one.
+const downloadAudience = "export-download"
+
+type ExportClaims struct {
+	ExportOID uuid.UUID `json:"export_oid"`
+	Tenant    uuid.UUID `json:"tenant"`
+	jwt.StandardClaims
+}
+
+func (a *API) createDownloadURL(tenant uuid.UUID, export domain.ExportDTO) (string, error) {
+	expiresAt := time.Now().Add(time.Duration(a.Config.Export.LinkTTLMinutes) * time.Minute)
+	if export.ExpiresAt != nil && export.ExpiresAt.Before(expiresAt) {
+		expiresAt = *export.ExpiresAt
+	}
+
+	claims := &ExportClaims{
+		ExportOID: export.OID,
+		Tenant:    tenant,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  downloadAudience,
+			ExpiresAt: expiresAt.Unix(),
+		},
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+	tokenString, err := token.SignedString([]byte(os.Getenv("JWT_KEY")))
+	if err != nil {
+		return "", fmt.Errorf("unable to create download token: %w", err)
+	}
+
+	return fmt.Sprintf("/api/exports/%s/download?token=%s", export.OID, tokenString), nil
+}
+
+// @Summary Request data export
+// @Description Start building a ZIP archive with everything that is stored about the user
+// @Tags export
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 202 {object} domain.ExportDTO
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp
+// @Router /users/{id}/export [post]
+func (a *API) HandleRequestExport(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleRequestExport - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to export other profiles except his own."})
+	}
+
+	export, err := a.Exporter.Request(tenant, userID, userIDFromAuth)
+	if err != nil {
+		log.Warnf("HandleRequestExport: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to start data export"})
+	}
+
+	log.Infof("Data export %s of user with oid %s requested by %s", export.OID, userID, userIDFromAuth)
+	return c.JSON(http.StatusAccepted, export)
+}
+
+// @Summary Get data export
+// @Description Get the status of a data export and a time-limited download link once it's ready
+// @Tags export
+// @Accept json
+// @Produce json
+// @Param id path string true "Export ID"
+// @Success 200 {object} domain.ExportDTO
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp
+// @Router /exports/{id} [get]
+func (a *API) HandleGetExport(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetExport - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	export, err := a.Exporter.Exports.GetExport(tenant, exportID)
+	if err != nil {
+		log.Warnf("HandleGetExport: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to get data export"})
+	}
+
+	if export.UserOID != userIDFromAuth && export.RequestedBy != userIDFromAuth && userRoleFromAuth != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "User is not permitted to access this export."})
+	}
+
+	if export.Status == domain.ExportReady {
+		export.DownloadURL, err = a.createDownloadURL(tenant, export)
+		if err != nil {
+			log.Warnf("HandleGetExport: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Unable to get data export"})
+		}
+	}
+
+	return c.JSON(http.StatusOK, export)
+}
+
+// @Summary Download data export
+// @Description Download a data export archive using the link from the export status
+// @Tags export
+// @Produce application/zip
+// @Param id path string true "Export ID"
+// @Param token query string true "Download token"
+// @Success 200 {file} file
+// @Failure 401 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp
+// @Router /exports/{id}/download [get]
+func (a *API) HandleDownloadExport(c echo.Context) error {
+	exportID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleDownloadExport - unable to convert string to uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	token, err := jwt.ParseWithClaims(c.QueryParam("token"), &ExportClaims{}, func(token *jwt.Token) (interface{}, error) {
+		return []byte(os.Getenv("JWT_KEY")), nil
+	})
+	if err != nil || !token.Valid {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or expired download link"})
+	}
+	claims, ok := token.Claims.(*ExportClaims)
+	if !ok || !claims.VerifyAudience(downloadAudience, true) || claims.ExportOID != exportID {
+		return c.JSON(http.StatusUnauthorized, map[string]string{"error": "Invalid or expired download link"})
+	}
+
+	// the download link is used without a bearer token, so the organization comes from the link itself
+	export, err := a.Exporter.Exports.GetExport(claims.Tenant, exportID)
+	if err != nil || export.Status != domain.ExportReady {
+		log.Warnf("HandleDownloadExport: export %s is not available: %v", exportID, err)
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Export is not available"})
+	}
+
+	return c.Attachment(export.FilePath, fmt.Sprintf("export-%s.zip", export.UserOID))
+}
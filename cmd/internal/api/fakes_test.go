@@ -0,0 +1,504 @@
+package api
+
+import (
+	"database/sql"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/redis/go-redis/v9"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+const testJWTKey = "test-key"
+
+// fakeStore keeps everything in memory per tenant, like the stored procedures scope every query by tenant.
+// Methods that tests don't need aren't implemented and panic through the nil embedded interface.
+type fakeStore struct {
+	domain.DomainInterface
+
+	mu      sync.Mutex
+	orgs    map[string]domain.Organization
+	users   map[uuid.UUID]map[uuid.UUID]domain.UserProfileDTO
+	groups  map[uuid.UUID]map[uuid.UUID]domain.GroupDTO
+	members map[uuid.UUID]map[uuid.UUID]*fakeMember
+	audit   map[uuid.UUID][]domain.AuditRecord
+
+	formerNicknames map[uuid.UUID]string
+}
+
+func newFakeStore() *fakeStore {
+	return &fakeStore{
+		orgs:    make(map[string]domain.Organization),
+		users:   make(map[uuid.UUID]map[uuid.UUID]domain.UserProfileDTO),
+		groups:  make(map[uuid.UUID]map[uuid.UUID]domain.GroupDTO),
+		members: make(map[uuid.UUID]map[uuid.UUID]*fakeMember),
+		audit:   make(map[uuid.UUID][]domain.AuditRecord),
+
+		formerNicknames: make(map[uuid.UUID]string),
+	}
+}
+
+func (s *fakeStore) addOrg(slug string) domain.Organization {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org := domain.Organization{ID: uuid.New(), Slug: slug, Name: slug, CreatedAt: time.Now().UTC()}
+	s.orgs[slug] = org
+	s.users[org.ID] = make(map[uuid.UUID]domain.UserProfileDTO)
+	s.groups[org.ID] = make(map[uuid.UUID]domain.GroupDTO)
+	return org
+}
+
+func (s *fakeStore) addUser(tenant uuid.UUID, nick string, role domain.Role) domain.UserProfileDTO {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user := domain.UserProfileDTO{
+		OID:                uuid.New(),
+		Nickname:           nick,
+		FirstName:          "First " + nick,
+		LastName:           "Last " + nick,
+		State:              domain.Active,
+		Role:               role,
+		CreatedAt:          time.Now().UTC(),
+		UpdatedAt:          time.Now().UTC(),
+		NicknameNormalized: nickname.Key(nick),
+		NicknameSkeleton:   nickname.Skeleton(nick),
+		Privacy:            &domain.PrivacySettings{ProfileVisibility: domain.ProfilePublic, RealNameVisibility: domain.NameEveryone},
+	}
+	s.users[tenant][user.OID] = user
+	return user
+}
+
+func (s *fakeStore) user(tenant uuid.UUID, oid uuid.UUID) (domain.UserProfileDTO, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[tenant][oid]
+	return user, ok
+}
+
+func (s *fakeStore) GetOrganizationBySlug(slug string) (domain.Organization, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	org, ok := s.orgs[slug]
+	if !ok {
+		return domain.Organization{}, fmt.Errorf("GetOrganizationBySlug: %w", sql.ErrNoRows)
+	}
+	return org, nil
+}
+
+func (s *fakeStore) GetUserById(tenant uuid.UUID, oid uuid.UUID) (domain.UserProfileDTO, error) {
+	user, ok := s.user(tenant, oid)
+	if !ok {
+		return domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", sql.ErrNoRows)
+	}
+	return user, nil
+}
+
+func (s *fakeStore) GetUserState(tenant uuid.UUID, oid uuid.UUID) (int, error) {
+	user, ok := s.user(tenant, oid)
+	if !ok {
+		return int(domain.Deleted), nil
+	}
+	return int(user.State), nil
+}
+
+func (s *fakeStore) UpdateUserProfile(tenant uuid.UUID, update domain.UserProfileDTO, oid uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[tenant][oid]
+	if !ok {
+		return nil
+	}
+	user.Nickname, user.FirstName, user.LastName = update.Nickname, update.FirstName, update.LastName
+	user.NicknameNormalized, user.NicknameSkeleton = update.NicknameNormalized, update.NicknameSkeleton
+	s.users[tenant][oid] = user
+	return nil
+}
+
+func (s *fakeStore) SetRating(tenant uuid.UUID, oid uuid.UUID, rating int) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[tenant][oid]
+	if !ok {
+		return nil
+	}
+	user.Rating = rating
+	s.users[tenant][oid] = user
+	return nil
+}
+
+func (s *fakeStore) GetAttributesSchema(tenant uuid.UUID) ([]byte, error) {
+	return []byte(`{"type": "object"}`), nil
+}
+
+func (s *fakeStore) UpdateAttributes(tenant uuid.UUID, attributes map[string]interface{}, visibility map[string]domain.Visibility, oid uuid.UUID) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	user, ok := s.users[tenant][oid]
+	if !ok {
+		return nil
+	}
+	user.Attributes, user.AttributesVisibility = attributes, visibility
+	s.users[tenant][oid] = user
+	return nil
+}
+
+// ResolveNickname resolves current nicknames and former ones recorded by formerNicknames.
+func (s *fakeStore) ResolveNickname(tenant uuid.UUID, key string) (domain.NicknameDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users[tenant] {
+		resolved := domain.NicknameDTO{OID: user.OID, Nickname: user.Nickname, Visibility: privacyOf(user).ProfileVisibility}
+		if user.NicknameNormalized == key {
+			return resolved, nil
+		}
+		if s.formerNicknames[user.OID] == key {
+			resolved.Former = true
+			return resolved, nil
+		}
+	}
+	return domain.NicknameDTO{}, sql.ErrNoRows
+}
+
+func (s *fakeStore) GetNicknameConflict(tenant uuid.UUID, oid uuid.UUID, normalized string, skeleton string) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, user := range s.users[tenant] {
+		if user.OID == oid {
+			continue
+		}
+		if (normalized != "" && user.NicknameNormalized == normalized) || (skeleton != "" && user.NicknameSkeleton == skeleton) {
+			return user.Nickname, nil
+		}
+	}
+	return "", nil
+}
+
+func (s *fakeStore) GetNicknameReservation(tenant uuid.UUID, nickname string, since time.Time) (uuid.UUID, error) {
+	return uuid.Nil, nil
+}
+
+func (s *fakeStore) GetLastNicknameChange(tenant uuid.UUID, oid uuid.UUID) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (s *fakeStore) GetFollowCounts(tenant uuid.UUID, oid uuid.UUID) (int, int, error) {
+	return 0, 0, nil
+}
+
+func (s *fakeStore) IsBlocked(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) (bool, error) {
+	return false, nil
+}
+
+func (s *fakeStore) GetBlockedOIDs(tenant uuid.UUID, oid uuid.UUID) ([]uuid.UUID, error) {
+	return nil, nil
+}
+
+func (s *fakeStore) WriteAudit(tenant uuid.UUID, records []domain.AuditRecord) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.audit[tenant] = append(s.audit[tenant], records...)
+	return nil
+}
+
+func (s *fakeStore) auditRecords(tenant uuid.UUID) []domain.AuditRecord {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return append([]domain.AuditRecord(nil), s.audit[tenant]...)
+}
+
+// fakeMember is a row of group_members.
+type fakeMember struct {
+	role        domain.GroupRole
+	status      domain.GroupMemberStatus
+	editGranted bool
+}
+
+func (s *fakeStore) CreateGroup(tenant uuid.UUID, group domain.GroupDTO, owner uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.groups[tenant][group.OID] = group
+	s.members[group.OID] = map[uuid.UUID]*fakeMember{owner: {role: domain.GroupOwner, status: domain.GroupActive}}
+	return true, nil
+}
+
+func (s *fakeStore) GetGroup(tenant uuid.UUID, oid uuid.UUID) (domain.GroupDTO, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	group, ok := s.groups[tenant][oid]
+	if !ok {
+		return domain.GroupDTO{}, fmt.Errorf("GetGroup: %w", sql.ErrNoRows)
+	}
+	return group, nil
+}
+
+func (s *fakeStore) AddGroupMember(tenant uuid.UUID, group uuid.UUID, user uuid.UUID, role domain.GroupRole, status domain.GroupMemberStatus, editGranted bool) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.groups[tenant][group]; !ok {
+		return true, nil
+	}
+	if member, ok := s.members[group][user]; ok {
+		member.role = role
+		if member.status != domain.GroupActive {
+			member.status = status
+		}
+		member.editGranted = member.editGranted || editGranted
+		return true, nil
+	}
+	s.members[group][user] = &fakeMember{role: role, status: status, editGranted: editGranted}
+	return true, nil
+}
+
+func (s *fakeStore) AcceptGroupInvitation(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member, ok := s.members[group][user]
+	if _, exists := s.groups[tenant][group]; !exists || !ok || member.status != domain.GroupInvited {
+		return false, nil
+	}
+	member.status, member.editGranted = domain.GroupActive, true
+	return true, nil
+}
+
+func (s *fakeStore) GetGroupRole(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (domain.GroupRole, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	member, ok := s.members[group][user]
+	if _, exists := s.groups[tenant][group]; !exists || !ok || member.status != domain.GroupActive {
+		return "", nil
+	}
+	return member.role, nil
+}
+
+func (s *fakeStore) IsGroupOwnerOf(tenant uuid.UUID, owner uuid.UUID, member uuid.UUID) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.users[tenant][member].Role != domain.Usr {
+		return false, nil
+	}
+	for group := range s.groups[tenant] {
+		o, m := s.members[group][owner], s.members[group][member]
+		if o != nil && m != nil && o.role == domain.GroupOwner && o.status == domain.GroupActive && m.status == domain.GroupActive && m.editGranted {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// fakeCache keeps entries per tenant like the Redis cache does, lists counts how many times list pages
+// of every tenant were invalidated.
+type fakeCache struct {
+	mu      sync.Mutex
+	entries map[uuid.UUID]map[string]interface{}
+	lists   map[uuid.UUID]int
+}
+
+func newFakeCache() *fakeCache {
+	return &fakeCache{entries: make(map[uuid.UUID]map[string]interface{}), lists: make(map[uuid.UUID]int)}
+}
+
+func (f *fakeCache) Set(tenant uuid.UUID, key string, value interface{}) error {
+	return f.SetWithTTL(tenant, key, value, 0)
+}
+
+func (f *fakeCache) SetWithTTL(tenant uuid.UUID, key string, value interface{}, ttl time.Duration) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.entries[tenant] == nil {
+		f.entries[tenant] = make(map[string]interface{})
+	}
+	f.entries[tenant][key] = value
+	return nil
+}
+
+func (f *fakeCache) get(tenant uuid.UUID, key string) (interface{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	value, ok := f.entries[tenant][key]
+	if !ok {
+		return nil, redis.Nil
+	}
+	return value, nil
+}
+
+func (f *fakeCache) GetUser(tenant uuid.UUID, key string) (domain.UserProfileDTO, error) {
+	value, err := f.get(tenant, key)
+	if err != nil {
+		return domain.UserProfileDTO{}, err
+	}
+	return value.(domain.UserProfileDTO), nil
+}
+
+func (f *fakeCache) GetUsersList(tenant uuid.UUID, key string) (domain.Pagination[domain.UserProfileDTO], error) {
+	value, err := f.get(tenant, key)
+	if err != nil {
+		return domain.Pagination[domain.UserProfileDTO]{}, err
+	}
+	return value.(domain.Pagination[domain.UserProfileDTO]), nil
+}
+
+func (f *fakeCache) GetLeaderboard(tenant uuid.UUID, key string) (domain.Leaderboard, error) {
+	value, err := f.get(tenant, key)
+	if err != nil {
+		return domain.Leaderboard{}, err
+	}
+	return value.(domain.Leaderboard), nil
+}
+
+func (f *fakeCache) MakeKey(tenant uuid.UUID, page domain.PageQuery, filter domain.UsersFilter) (string, error) {
+	return fmt.Sprintf("page:%v,filter:%v", page, filter), nil
+}
+
+func (f *fakeCache) Delete(tenant uuid.UUID, key string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	delete(f.entries[tenant], key)
+	return nil
+}
+
+func (f *fakeCache) InvalidateLists(tenant uuid.UUID) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.lists[tenant]++
+	return nil
+}
+
+func (f *fakeCache) listsVersion(tenant uuid.UUID) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.lists[tenant]
+}
+
+// fakeRating keeps votes per tenant.
+type fakeRating struct {
+	domain.StatsManager
+
+	mu    sync.Mutex
+	votes map[uuid.UUID][]domain.VoteDTO
+}
+
+func newFakeRating() *fakeRating {
+	return &fakeRating{votes: make(map[uuid.UUID][]domain.VoteDTO)}
+}
+
+func (f *fakeRating) tenantVotes(tenant uuid.UUID) []domain.VoteDTO {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]domain.VoteDTO(nil), f.votes[tenant]...)
+}
+
+func (f *fakeRating) RateProfile(tenant uuid.UUID, vote domain.VoteDTO) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.votes[tenant] = append(f.votes[tenant], vote)
+	return nil
+}
+
+func (f *fakeRating) GetVote(tenant uuid.UUID, vote domain.VoteDTO) (domain.VoteDTO, bool, error) {
+	for _, existing := range f.tenantVotes(tenant) {
+		if existing.FromOID == vote.FromOID && existing.ToOID == vote.ToOID {
+			return existing, true, nil
+		}
+	}
+	return domain.VoteDTO{}, false, nil
+}
+
+func (f *fakeRating) LastVotedAt(tenant uuid.UUID, vote domain.VoteDTO) (time.Time, error) {
+	return time.Time{}, nil
+}
+
+func (f *fakeRating) GetRating(tenant uuid.UUID, oid uuid.UUID) (int, error) {
+	var total int
+	for _, vote := range f.tenantVotes(tenant) {
+		if vote.ToOID == oid {
+			total++
+		}
+	}
+	return total, nil
+}
+
+func (f *fakeRating) GetRatingBreakdown(tenant uuid.UUID, oid uuid.UUID, excluded []uuid.UUID) (domain.Rating, error) {
+	total, err := f.GetRating(tenant, oid)
+	return domain.Rating{Total: total}, err
+}
+
+// testServer serves the API backed by fakes with the routes and middlewares of the app.
+type testServer struct {
+	t      *testing.T
+	api    *API
+	store  *fakeStore
+	cache  *fakeCache
+	rating *fakeRating
+	echo   *echo.Echo
+}
+
+func newTestServer(t *testing.T) *testServer {
+	t.Setenv("JWT_KEY", testJWTKey)
+
+	store, cache, rating := newFakeStore(), newFakeCache(), newFakeRating()
+	store.addOrg("default")
+
+	cfg := &config.Config{
+		Tenant:   config.TenantConfig{Default: "default", Header: "X-Tenant"},
+		Nickname: config.NicknameConfig{MinLength: 3, MaxLength: 32, ChangeCooldownHours: 720},
+		Block:    config.BlockConfig{ExcludeVotes: true},
+	}
+	a := &API{Orgs: store, DB: store, Cache: cache, Rating: rating, Follows: store, Blocks: store, Prefs: store, Groups: store, Invites: store, Audit: store,
+		Config: cfg, Nicknames: nickname.NewValidator(cfg.Nickname)}
+
+	e := echo.New()
+	e.Use(a.TenantMiddleware)
+	e.PUT("/api/users/:id", a.HandleUpdateUserProfile, a.JWTMiddleware)
+	e.PUT("/api/users/:id/attributes", a.HandleUpdateAttributes, a.JWTMiddleware)
+	e.GET("/api/users/:id", a.HandleGetUserById, a.OptionalJWTMiddleware)
+	e.GET("/api/users/nickname/:nickname", a.HandleResolveNickname, a.OptionalJWTMiddleware)
+	e.POST("/api/vote", a.HandleVote, a.JWTMiddleware)
+	e.POST("/api/groups", a.HandleCreateGroup, a.JWTMiddleware)
+	e.POST("/api/groups/:id/members", a.HandleAddGroupMember, a.JWTMiddleware)
+	e.POST("/api/groups/:id/accept", a.HandleAcceptGroupInvitation, a.JWTMiddleware)
+	e.GET("/tenant", func(c echo.Context) error {
+		return c.String(http.StatusOK, tenantOf(c).String())
+	}, a.OptionalJWTMiddleware)
+
+	return &testServer{t: t, api: a, store: store, cache: cache, rating: rating, echo: e}
+}
+
+// token returns a JWT of user issued in tenant.
+func (s *testServer) token(tenant uuid.UUID, user domain.UserProfileDTO) string {
+	claims := &CustomClaims{
+		OID:            user.OID,
+		Role:           user.Role,
+		Tenant:         tenant,
+		StandardClaims: jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Hour).Unix()},
+	}
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(testJWTKey))
+	if err != nil {
+		s.t.Fatal(err)
+	}
+	return token
+}
+
+// do sends a request naming the organization by slug, if set, and authenticated with token, if set.
+func (s *testServer) do(method string, path string, slug string, token string, body string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	if slug != "" {
+		req.Header.Set("X-Tenant", slug)
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+	rec := httptest.NewRecorder()
+	s.echo.ServeHTTP(rec, req)
+	return rec
+}
@@ -0,0 +1,71 @@
+package api
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/labstack/echo/v4"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// usersFilter reads filter and sort query params of the users list.
+func usersFilter(c echo.Context) (domain.UsersFilter, error) {
+	filter := domain.UsersFilter{Attributes: attributesFilter(c)}
+
+	if param := c.QueryParam("state"); param != "" {
+		state, err := strconv.Atoi(param)
+		if err != nil || state < int(domain.Deleted) || state > int(domain.Active) {
+			return domain.UsersFilter{}, fmt.Errorf("wrong state %q", param)
+		}
+		filter.State = (*domain.State)(&state)
+	}
+
+	if param := c.QueryParam("role"); param != "" {
+		role, err := strconv.Atoi(param)
+		if err != nil || role < int(domain.Usr) || role > int(domain.Admin) {
+			return domain.UsersFilter{}, fmt.Errorf("wrong role %q", param)
+		}
+		filter.Role = (*domain.Role)(&role)
+	}
+
+	ranges := []struct {
+		param string
+		value **time.Time
+	}{
+		{"created_from", &filter.CreatedFrom},
+		{"created_to", &filter.CreatedTo},
+		{"updated_from", &filter.UpdatedFrom},
+		{"updated_to", &filter.UpdatedTo},
+	}
+	for _, r := range ranges {
+		param := c.QueryParam(r.param)
+		if param == "" {
+			continue
+		}
+		t, err := time.Parse(time.RFC3339, param)
+		if err != nil {
+			return domain.UsersFilter{}, fmt.Errorf("wrong %s %q, should be in RFC3339 format", r.param, param)
+		}
+		t = t.UTC()
+		*r.value = &t
+	}
+
+	switch sort := c.QueryParam("sort"); sort {
+	case "", domain.SortByCreatedAt:
+	case domain.SortByNickname, domain.SortByRating:
+		filter.SortBy = sort
+	default:
+		return domain.UsersFilter{}, fmt.Errorf("wrong sort %q, should be one of: nickname, created_at, rating", sort)
+	}
+
+	switch order := c.QueryParam("order"); order {
+	case "", "asc":
+	case "desc":
+		filter.Desc = true
+	default:
+		return domain.UsersFilter{}, fmt.Errorf("wrong order %q, should be asc or desc", order)
+	}
+
+	return filter, nil
+}
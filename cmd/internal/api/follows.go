@@ -0,0 +1,212 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// @Summary Follow user
+// @Description Follow the user, following an already followed user is a no-op
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID to follow"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to follow user"
+// @Router /users/{id}/follow [post]
+func (a *API) HandleFollow(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleFollow: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID == userIDFromAuth {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You can't follow yourself"})
+	}
+
+	followed, err := a.Follows.Follow(tenant, userIDFromAuth, userID, userRoleFromAuth >= domain.Moderator)
+	if err != nil {
+		log.Warnf("HandleFollow: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to follow user"})
+	}
+	if !followed {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	log.Infof("User %s followed user %s", userIDFromAuth, userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "User followed successfully."})
+}
+
+// @Summary Unfollow user
+// @Description Stop following the user
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID to unfollow"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to unfollow user"
+// @Router /users/{id}/follow [delete]
+func (a *API) HandleUnfollow(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUnfollow: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	err = a.Follows.Unfollow(tenant, userIDFromAuth, userID)
+	if err != nil {
+		log.Warnf("HandleUnfollow: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to unfollow user"})
+	}
+
+	log.Infof("User %s unfollowed user %s", userIDFromAuth, userID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "User unfollowed successfully."})
+}
+
+// @Summary Get followers
+// @Description Retrieve a paginated list of users following the user, most recent first. Only public profiles are listed to users.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param Authorization header string false "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer"
+// @Success 200 {object} domain.GetUserListResp "Paginated list of followers"
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to get followers"
+// @Router /users/{id}/followers [get]
+func (a *API) HandleGetFollowers(c echo.Context) error {
+	return a.getFollows(c, domain.FollowersList)
+}
+
+// @Summary Get followed users
+// @Description Retrieve a paginated list of users followed by the user, most recent first. Only public profiles are listed to users.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param Authorization header string false "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer"
+// @Success 200 {object} domain.GetUserListResp "Paginated list of followed users"
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to get followed users"
+// @Router /users/{id}/following [get]
+func (a *API) HandleGetFollowing(c echo.Context) error {
+	return a.getFollows(c, domain.FollowingList)
+}
+
+func (a *API) getFollows(c echo.Context, list domain.FollowList) error {
+	tenant := tenantOf(c)
+	failed := "Failed to get followers"
+	if list == domain.FollowingList {
+		failed = "Failed to get followed users"
+	}
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("getFollows: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	viewerID, viewerRole := viewer(c)
+
+	user, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("getFollows: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": failed})
+	}
+	if user.State == domain.Deleted || !canSeeProfile(user, viewerID, viewerRole) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	pageNumber, pageSize := pageParams(c)
+	all := viewerRole >= domain.Moderator
+
+	users, err := a.Follows.GetFollows(tenant, userID, list, all, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("getFollows: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": failed})
+	}
+
+	if len(users) > 0 {
+		oids := make([]uuid.UUID, 0, len(users))
+		for _, user := range users {
+			oids = append(oids, user.OID)
+		}
+		ratings, err := a.Rating.GetRatingForList(tenant, oids)
+		if err != nil {
+			log.Warnf("getFollows: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": failed})
+		}
+		for i, user := range users {
+			users[i].Rating = ratings[user.OID].Total
+		}
+	}
+
+	total, err := a.Follows.GetFollowsCount(tenant, userID, list, all)
+	if err != nil {
+		log.Warnf("getFollows: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": failed})
+	}
+
+	return c.JSON(http.StatusOK, renderUsersList(domain.Pagination[domain.UserProfileDTO]{
+		TotalItems:  &total,
+		CurrentPage: pageNumber,
+		Users:       users,
+	}, viewerID, viewerRole))
+}
+
+// @Summary Get follow relationship
+// @Description Check whether the users follow each other. Users that don't exist, aren't active or are hidden are reported as unrelated, unless the viewer is a moderator.
+// @Tags follows
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param other_id path string true "Other user ID"
+// @Param Authorization header string false "Optional bearer token"
+// @Success 200 {object} domain.FollowRelationDTO
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to get follow relationship"
+// @Router /users/{id}/follows/{other_id} [get]
+func (a *API) HandleGetFollowRelation(c echo.Context) error {
+	tenant := tenantOf(c)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetFollowRelation: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+	otherID, err := uuid.Parse(c.Param("other_id"))
+	if err != nil {
+		log.Warnf("HandleGetFollowRelation: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	_, viewerRole := viewer(c)
+
+	relation, err := a.Follows.GetFollowRelation(tenant, userID, otherID, viewerRole >= domain.Moderator)
+	if err != nil {
+		log.Warnf("HandleGetFollowRelation: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get follow relationship"})
+	}
+
+	return c.JSON(http.StatusOK, relation)
+}
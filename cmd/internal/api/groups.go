@@ -0,0 +1,605 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// canManageGroup checks that the user is permitted to change the group and its members,
+// it's permitted to moderators, admins and owners of the group.
+func (a *API) canManageGroup(tenant uuid.UUID, group uuid.UUID, userID uuid.UUID, userRole domain.Role) (bool, error) {
+	if userRole != domain.Usr {
+		return true, nil
+	}
+	role, err := a.Groups.GetGroupRole(tenant, group, userID)
+	if err != nil {
+		return false, err
+	}
+	return role == domain.GroupOwner, nil
+}
+
+// canEditProfile checks that the editor is permitted to change the profile of userID, it's permitted to the user themselves,
+// moderators, admins and owners of groups the user belongs to, if the user accepted the invitation to the group
+// or was added to it by a moderator or an admin.
+func (a *API) canEditProfile(tenant uuid.UUID, userID uuid.UUID, editorID uuid.UUID, editorRole domain.Role) (bool, error) {
+	if userID == editorID || editorRole != domain.Usr {
+		return true, nil
+	}
+	return a.Groups.IsGroupOwnerOf(tenant, editorID, userID)
+}
+
+func groupFields(group domain.GroupDTO) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        group.Name,
+		"description": group.Description,
+	}
+}
+
+// memberFields returns the membership as audited fields. They are keyed by the group, so that records of role changes keep it.
+func memberFields(group uuid.UUID, role domain.GroupRole, status domain.GroupMemberStatus) map[string]interface{} {
+	membership := map[string]interface{}{}
+	if role != "" {
+		membership["role"] = role
+	}
+	if status != "" {
+		membership["status"] = status
+	}
+	return map[string]interface{}{group.String(): membership}
+}
+
+func checkGroup(req *domain.GroupReq) error {
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		return errors.New("Name is required")
+	}
+	if len(req.Name) > 255 {
+		return errors.New("Name should be up to 255 characters")
+	}
+	return nil
+}
+
+// @Summary Create group
+// @Description Create a group of users, the user creating it becomes its owner
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param group body domain.GroupReq true "Group"
+// @Success 201 {object} domain.GroupDTO
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 409 {object} domain.ErrorResp "Group already exists"
+// @Failure 500 {object} domain.ErrorResp
+// @Router /groups [post]
+func (a *API) HandleCreateGroup(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	var req domain.GroupReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleCreateGroup - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := checkGroup(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	now := time.Now().UTC()
+	group := domain.GroupDTO{
+		OID:          uuid.New(),
+		Name:         req.Name,
+		Description:  req.Description,
+		MembersCount: 1,
+		Role:         domain.GroupOwner,
+		CreatedAt:    now,
+		UpdatedAt:    now,
+	}
+
+	created, err := a.Groups.CreateGroup(tenant, group, userIDFromAuth)
+	if err != nil {
+		log.Warnf("HandleCreateGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create group"})
+	}
+	if !created {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Group already exists"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: group.OID, Action: domain.AuditGroupCreate, After: groupFields(group)})
+
+	log.Infof("User %s created group %s with oid %s", userIDFromAuth, group.Name, group.OID)
+	return c.JSON(http.StatusCreated, group)
+}
+
+// @Summary Get groups
+// @Description Retrieve a paginated list of groups of the organization ordered by name
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} domain.GroupsPage
+// @Failure 500 {object} domain.ErrorResp "Failed to get groups"
+// @Router /groups [get]
+func (a *API) HandleGetGroups(c echo.Context) error {
+	tenant := tenantOf(c)
+	pageNumber, pageSize := pageParams(c)
+
+	groups, err := a.Groups.GetGroups(tenant, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("HandleGetGroups: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get groups"})
+	}
+
+	total, err := a.Groups.GetGroupsCount(tenant)
+	if err != nil {
+		log.Warnf("HandleGetGroups: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get groups"})
+	}
+
+	if groups == nil {
+		groups = []domain.GroupDTO{}
+	}
+	return c.JSON(http.StatusOK, domain.GroupsPage{TotalItems: total, CurrentPage: pageNumber, Groups: groups})
+}
+
+// @Summary Get group by ID
+// @Description Retrieve a group by its ID
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} domain.GroupDTO
+// @Failure 400 {object} domain.ErrorResp "Wrong GroupId"
+// @Failure 404 {object} domain.ErrorResp "Group not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to get group"
+// @Router /groups/{id} [get]
+func (a *API) HandleGetGroup(c echo.Context) error {
+	tenant := tenantOf(c)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetGroup: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+
+	group, err := a.Groups.GetGroup(tenant, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Group not found"})
+		}
+		log.Warnf("HandleGetGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get group"})
+	}
+
+	return c.JSON(http.StatusOK, group)
+}
+
+// @Summary Update group
+// @Description Change the name and the description of the group. Only owners of the group, moderators and admins are permitted to change it.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param group body domain.GroupReq true "Group"
+// @Success 200 {object} domain.GroupDTO
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "Group not found"
+// @Failure 409 {object} domain.ErrorResp "Group already exists"
+// @Failure 500 {object} domain.ErrorResp "Failed to update group"
+// @Router /groups/{id} [put]
+func (a *API) HandleUpdateGroup(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdateGroup: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+
+	var req domain.GroupReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleUpdateGroup - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := checkGroup(&req); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	group, err := a.Groups.GetGroup(tenant, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Group not found"})
+		}
+		log.Warnf("HandleUpdateGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update group"})
+	}
+
+	permitted, err := a.canManageGroup(tenant, groupID, userIDFromAuth, userRoleFromAuth)
+	if err != nil {
+		log.Warnf("HandleUpdateGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update group"})
+	}
+	if !permitted {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only owners of the group are permitted to change it."})
+	}
+
+	before := groupFields(group)
+	group.Name = req.Name
+	group.Description = req.Description
+	group.UpdatedAt = time.Now().UTC()
+
+	updated, err := a.Groups.UpdateGroup(tenant, group)
+	if err != nil {
+		log.Warnf("HandleUpdateGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update group"})
+	}
+	if !updated {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Group already exists"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: groupID, Action: domain.AuditGroupUpdate, Before: before, After: groupFields(group)})
+
+	log.Infof("User %s updated group with oid %s", userIDFromAuth, groupID)
+	return c.JSON(http.StatusOK, group)
+}
+
+// @Summary Delete group
+// @Description Delete the group, its members are kept. Only owners of the group, moderators and admins are permitted to delete it.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "Group not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to delete group"
+// @Router /groups/{id} [delete]
+func (a *API) HandleDeleteGroup(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleDeleteGroup: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+
+	permitted, err := a.canManageGroup(tenant, groupID, userIDFromAuth, userRoleFromAuth)
+	if err != nil {
+		log.Warnf("HandleDeleteGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete group"})
+	}
+	if !permitted {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only owners of the group are permitted to delete it."})
+	}
+
+	group, err := a.Groups.GetGroup(tenant, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Group not found"})
+		}
+		log.Warnf("HandleDeleteGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete group"})
+	}
+
+	err = a.Groups.DeleteGroup(tenant, groupID)
+	if err != nil {
+		log.Warnf("HandleDeleteGroup: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to delete group"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: groupID, Action: domain.AuditGroupDelete, Before: groupFields(group)})
+
+	log.Infof("User %s deleted group with oid %s", userIDFromAuth, groupID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Group deleted successfully."})
+}
+
+// @Summary Get group members
+// @Description Retrieve a paginated list of members of the group, owners first
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} domain.GroupMembersPage
+// @Failure 400 {object} domain.ErrorResp "Wrong GroupId"
+// @Failure 500 {object} domain.ErrorResp "Failed to get group members"
+// @Router /groups/{id}/members [get]
+func (a *API) HandleGetGroupMembers(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetGroupMembers: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+
+	pageNumber, pageSize := pageParams(c)
+
+	members, err := a.Groups.GetGroupMembers(tenant, groupID, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("HandleGetGroupMembers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get group members"})
+	}
+
+	total, err := a.Groups.GetGroupMembersCount(tenant, groupID)
+	if err != nil {
+		log.Warnf("HandleGetGroupMembers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get group members"})
+	}
+
+	rendered := make([]domain.GroupMemberDTO, 0, len(members))
+	for _, member := range members {
+		member.User = renderProfile(member.User, userIDFromAuth, userRoleFromAuth)
+		rendered = append(rendered, member)
+	}
+
+	return c.JSON(http.StatusOK, domain.GroupMembersPage{TotalItems: total, CurrentPage: pageNumber, Members: rendered})
+}
+
+// @Summary Add group member
+// @Description Add the user to the group or change their role in it. Only owners of the group, moderators and admins are permitted to manage members.
+// @Description Users added by owners of the group are invited and become members once they accept, users added by moderators and admins become members at once.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param member body domain.GroupMemberReq true "Member and their role, member by default"
+// @Success 200 {object} domain.MessageResp "Member added or invited"
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "Group or user not found"
+// @Failure 409 {object} domain.ErrorResp "Group should have an owner"
+// @Failure 500 {object} domain.ErrorResp "Failed to add group member"
+// @Router /groups/{id}/members [post]
+func (a *API) HandleAddGroupMember(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleAddGroupMember: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+
+	var req domain.GroupMemberReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleAddGroupMember - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	switch req.Role {
+	case "":
+		req.Role = domain.GroupMember
+	case domain.GroupOwner, domain.GroupMember:
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Role should be one of: owner, member"})
+	}
+
+	_, err = a.Groups.GetGroup(tenant, groupID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Group not found"})
+		}
+		log.Warnf("HandleAddGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add group member"})
+	}
+
+	permitted, err := a.canManageGroup(tenant, groupID, userIDFromAuth, userRoleFromAuth)
+	if err != nil {
+		log.Warnf("HandleAddGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add group member"})
+	}
+	if !permitted {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only owners of the group are permitted to manage its members."})
+	}
+
+	state, err := a.DB.GetUserState(tenant, req.UserOID)
+	if err != nil {
+		log.Warnf("HandleAddGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add group member"})
+	}
+	if domain.State(state) == domain.Deleted {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	// owners that aren't moderators or admins only invite others, so that nobody gets power over a profile without its owner's consent
+	currentRole, err := a.Groups.GetGroupRole(tenant, groupID, req.UserOID)
+	if err != nil {
+		log.Warnf("HandleAddGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add group member"})
+	}
+	status := domain.GroupActive
+	if userRoleFromAuth == domain.Usr && req.UserOID != userIDFromAuth && currentRole == "" {
+		status = domain.GroupInvited
+	}
+
+	added, err := a.Groups.AddGroupMember(tenant, groupID, req.UserOID, req.Role, status, userRoleFromAuth != domain.Usr)
+	if err != nil {
+		log.Warnf("HandleAddGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to add group member"})
+	}
+	if !added {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Group should have an owner"})
+	}
+
+	before := map[string]interface{}{}
+	if currentRole != "" {
+		before = memberFields(groupID, currentRole, domain.GroupActive)
+	}
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: req.UserOID, Action: domain.AuditGroupMemberAdd,
+		Before: before, After: memberFields(groupID, req.Role, status)})
+
+	if status == domain.GroupInvited {
+		log.Infof("User %s invited user %s to group %s as %s", userIDFromAuth, req.UserOID, groupID, req.Role)
+		return c.JSON(http.StatusOK, map[string]string{"message": "The user is invited to the group and becomes a member once they accept."})
+	}
+	log.Infof("User %s added user %s to group %s as %s", userIDFromAuth, req.UserOID, groupID, req.Role)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Group member added successfully."})
+}
+
+// @Summary Accept group invitation
+// @Description Accept the invitation to the group, the user becomes its member and owners of the group become permitted to update their profile
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Wrong GroupId"
+// @Failure 404 {object} domain.ErrorResp "Group invitation not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to accept group invitation"
+// @Router /groups/{id}/accept [post]
+func (a *API) HandleAcceptGroupInvitation(c echo.Context) error {
+	tenant := tenantOf(c)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleAcceptGroupInvitation: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+
+	accepted, err := a.Groups.AcceptGroupInvitation(tenant, groupID, userIDFromAuth)
+	if err != nil {
+		log.Warnf("HandleAcceptGroupInvitation: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to accept group invitation"})
+	}
+	if !accepted {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Group invitation not found"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userIDFromAuth, Action: domain.AuditGroupMemberAdd,
+		Before: memberFields(groupID, "", domain.GroupInvited), After: memberFields(groupID, "", domain.GroupActive)})
+
+	log.Infof("User %s accepted the invitation to group %s", userIDFromAuth, groupID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Group invitation accepted successfully."})
+}
+
+// @Summary Remove group member
+// @Description Remove the user from the group or withdraw their invitation. Members can leave groups and decline invitations, only owners of the group, moderators and admins are permitted to remove others.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "Group ID"
+// @Param user_id path string true "User ID"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 409 {object} domain.ErrorResp "Group should have an owner"
+// @Failure 500 {object} domain.ErrorResp "Failed to remove group member"
+// @Router /groups/{id}/members/{user_id} [delete]
+func (a *API) HandleRemoveGroupMember(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	groupID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleRemoveGroupMember: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong GroupId"})
+	}
+	userID, err := uuid.Parse(c.Param("user_id"))
+	if err != nil {
+		log.Warnf("HandleRemoveGroupMember: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID != userIDFromAuth {
+		permitted, err := a.canManageGroup(tenant, groupID, userIDFromAuth, userRoleFromAuth)
+		if err != nil {
+			log.Warnf("HandleRemoveGroupMember: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to remove group member"})
+		}
+		if !permitted {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only owners of the group are permitted to manage its members."})
+		}
+	}
+
+	// invitations have no role, so they are audited as memberships with unknown role
+	role, err := a.Groups.GetGroupRole(tenant, groupID, userID)
+	if err != nil {
+		log.Warnf("HandleRemoveGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to remove group member"})
+	}
+
+	removed, err := a.Groups.RemoveGroupMember(tenant, groupID, userID)
+	if err != nil {
+		log.Warnf("HandleRemoveGroupMember: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to remove group member"})
+	}
+	if !removed {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Group should have an owner"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditGroupMemberRemove, Before: memberFields(groupID, role, "")})
+
+	log.Infof("User %s removed user %s from group %s", userIDFromAuth, userID, groupID)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Group member removed successfully."})
+}
+
+// @Summary Get user groups
+// @Description Retrieve a paginated list of groups the user is a member of along with their role in each, most recently joined first.
+// @Description Invitations to groups are listed with status invited, only to the user themselves, moderators and admins.
+// @Tags groups
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param status query string false "Membership status: active (default) or invited"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} domain.GroupsPage
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp "Failed to get user groups"
+// @Router /users/{id}/groups [get]
+func (a *API) HandleGetUserGroups(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetUserGroups: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	status := domain.GroupActive
+	switch domain.GroupMemberStatus(c.QueryParam("status")) {
+	case "", domain.GroupActive:
+	case domain.GroupInvited:
+		if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to see group invitations of other users."})
+		}
+		status = domain.GroupInvited
+	default:
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Status should be one of: active, invited"})
+	}
+
+	pageNumber, pageSize := pageParams(c)
+
+	groups, err := a.Groups.GetUserGroups(tenant, userID, status, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("HandleGetUserGroups: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user groups"})
+	}
+
+	total, err := a.Groups.GetUserGroupsCount(tenant, userID, status)
+	if err != nil {
+		log.Warnf("HandleGetUserGroups: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get user groups"})
+	}
+
+	if groups == nil {
+		groups = []domain.GroupDTO{}
+	}
+	return c.JSON(http.StatusOK, domain.GroupsPage{TotalItems: total, CurrentPage: pageNumber, Groups: groups})
+}
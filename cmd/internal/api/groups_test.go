@@ -0,0 +1,119 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// createGroup creates a group on behalf of the token holder and returns its oid.
+func (s *testServer) createGroup(token string, name string) string {
+	rec := s.do(http.MethodPost, "/api/groups", "", token, `{"name":"`+name+`"}`)
+	if rec.Code != http.StatusCreated {
+		s.t.Fatalf("create group: status = %d: %s", rec.Code, rec.Body)
+	}
+	var group domain.GroupDTO
+	if err := json.Unmarshal(rec.Body.Bytes(), &group); err != nil {
+		s.t.Fatal(err)
+	}
+	return group.OID.String()
+}
+
+func updateProfileBody(user domain.UserProfileDTO, firstName string) string {
+	return `{"nickname":"` + user.Nickname + `","first_name":"` + firstName + `","last_name":"` + user.LastName + `"}`
+}
+
+func TestGroupOwnerCantEditStranger(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	owner := s.store.addUser(org.ID, "owner", domain.Usr)
+	stranger := s.store.addUser(org.ID, "stranger", domain.Usr)
+	ownerToken := s.token(org.ID, owner)
+
+	group := s.createGroup(ownerToken, "Mine")
+	rec := s.do(http.MethodPost, "/api/groups/"+group+"/members", "", ownerToken, `{"user_oid":"`+stranger.OID.String()+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("add member: status = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = s.do(http.MethodPut, "/api/users/"+stranger.OID.String(), "", ownerToken, updateProfileBody(stranger, "Mallory"))
+	if rec.Code == http.StatusOK {
+		t.Errorf("owner updated profile of invited user: status = %d", rec.Code)
+	}
+	if got, _ := s.store.user(org.ID, stranger.OID); got.FirstName != stranger.FirstName {
+		t.Errorf("profile of invited user changed: %+v", got)
+	}
+
+	// once the user accepts, owners of the group can edit their profile
+	rec = s.do(http.MethodPost, "/api/groups/"+group+"/accept", "", s.token(org.ID, stranger), "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("accept invitation: status = %d: %s", rec.Code, rec.Body)
+	}
+	rec = s.do(http.MethodPut, "/api/users/"+stranger.OID.String(), "", ownerToken, updateProfileBody(stranger, "Accepted"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("owner updated profile of member: status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	// there is nothing to accept twice
+	rec = s.do(http.MethodPost, "/api/groups/"+group+"/accept", "", s.token(org.ID, stranger), "")
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("accept invitation again: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+}
+
+func TestGroupMemberAddedByAdmin(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	owner := s.store.addUser(org.ID, "owner", domain.Usr)
+	admin := s.store.addUser(org.ID, "admin", domain.Admin)
+	user := s.store.addUser(org.ID, "user", domain.Usr)
+	ownerToken := s.token(org.ID, owner)
+
+	group := s.createGroup(ownerToken, "Team")
+	rec := s.do(http.MethodPost, "/api/groups/"+group+"/members", "", s.token(org.ID, admin), `{"user_oid":"`+user.OID.String()+`"}`)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("add member: status = %d: %s", rec.Code, rec.Body)
+	}
+
+	rec = s.do(http.MethodPut, "/api/users/"+user.OID.String(), "", ownerToken, updateProfileBody(user, "Granted"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("owner updated profile of member added by admin: status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
+
+func TestGroupChangesAudited(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	owner := s.store.addUser(org.ID, "owner", domain.Usr)
+	user := s.store.addUser(org.ID, "user", domain.Usr)
+	ownerToken := s.token(org.ID, owner)
+
+	group := s.createGroup(ownerToken, "Audited")
+	s.do(http.MethodPost, "/api/groups/"+group+"/members", "", ownerToken, `{"user_oid":"`+user.OID.String()+`"}`)
+	s.do(http.MethodPost, "/api/groups/"+group+"/accept", "", s.token(org.ID, user), "")
+
+	want := []struct {
+		action domain.AuditAction
+		actor  domain.UserProfileDTO
+		target string
+	}{
+		{domain.AuditGroupCreate, owner, group},
+		{domain.AuditGroupMemberAdd, owner, user.OID.String()},
+		{domain.AuditGroupMemberAdd, user, user.OID.String()},
+	}
+	records := s.store.auditRecords(org.ID)
+	if len(records) != len(want) {
+		t.Fatalf("got %d audit records, want %d: %+v", len(records), len(want), records)
+	}
+	for i, record := range records {
+		if record.Action != want[i].action || record.ActorOID != want[i].actor.OID || record.TargetOID.String() != want[i].target {
+			t.Errorf("record %d = %s by %s of %s, want %s by %s of %s", i, record.Action, record.ActorOID, record.TargetOID,
+				want[i].action, want[i].actor.OID, want[i].target)
+		}
+	}
+	if status := records[1].After[group].(map[string]interface{})["status"]; status != domain.GroupInvited {
+		t.Errorf("invitation audited with status %v, want %s", status, domain.GroupInvited)
+	}
+}
@@ -0,0 +1,414 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	ImportFormatCSV    = "csv"
+	ImportFormatNDJSON = "ndjson"
+
+	maxImportLineSize = 1 << 20
+)
+
+var errImportInternal = errors.New("failed to create user profile")
+
+var errReservedName = errors.New(`first_name and last_name can't start with "pii:"`)
+
+// importer validates rows of a bulk import one by one and creates valid users in batches.
+type importer struct {
+	api    *API
+	dryRun bool
+	report domain.ImportReport
+
+	tenant uuid.UUID
+
+	// batch holds users that are not created yet, batchRows holds their indexes in report.Rows
+	batch     []domain.UserProfileDTO
+	batchRows []int
+
+	// nicknames and skeletons hold rows of already accepted nicknames to catch duplicates within the file
+	nicknames map[string]int
+	skeletons map[string]int
+}
+
+// ImportUsers creates users from CSV with a header row or from newline delimited JSON. Rows are validated
+// with the same rules as profiles created one by one, and users are created in batches of IMPORT_BATCH_SIZE,
+// each inside a transaction. Nothing is created in dry run mode, rows are only validated.
+// Problems with single rows end up in the report, error is returned only if the input can't be read,
+// in which case batches created before the error are kept and reported along with the error, and rows
+// of the unfinished batch are skipped.
+func (a *API) ImportUsers(tenant uuid.UUID, r io.Reader, format string, dryRun bool) (domain.ImportReport, error) {
+	imp := &importer{
+		api:       a,
+		tenant:    tenant,
+		dryRun:    dryRun,
+		report:    domain.ImportReport{DryRun: dryRun, Rows: []domain.ImportRowResult{}},
+		nicknames: make(map[string]int),
+		skeletons: make(map[string]int),
+	}
+
+	var err error
+	switch format {
+	case ImportFormatCSV:
+		err = imp.readCSV(r)
+	case ImportFormatNDJSON:
+		err = imp.readNDJSON(r)
+	default:
+		return domain.ImportReport{}, fmt.Errorf("wrong format %q, should be csv or ndjson", format)
+	}
+	if err != nil {
+		for _, i := range imp.batchRows {
+			imp.skip(i, "not created, input can't be read to the end")
+		}
+		imp.report.Error = err.Error()
+		return imp.report, err
+	}
+
+	imp.flush()
+	return imp.report, nil
+}
+
+func (imp *importer) readCSV(r io.Reader) error {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return fmt.Errorf("unable to read CSV header: %w", err)
+	}
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	if _, ok := columns["nickname"]; !ok {
+		return errors.New("CSV header should contain nickname column")
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return record[i]
+	}
+
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			return nil
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			imp.fail(imp.newRow(""), parseErr)
+			continue
+		}
+		if err != nil {
+			return fmt.Errorf("unable to read CSV: %w", err)
+		}
+
+		req := domain.ImportUserReq{
+			Nickname:     field(record, "nickname"),
+			FirstName:    field(record, "first_name"),
+			LastName:     field(record, "last_name"),
+			Password:     field(record, "password"),
+			PasswordHash: field(record, "password_hash"),
+		}
+		if param := field(record, "user_role"); param != "" {
+			role, err := strconv.Atoi(param)
+			if err != nil {
+				imp.fail(imp.newRow(req.Nickname), fmt.Errorf("wrong user_role %q", param))
+				continue
+			}
+			req.Role = domain.Role(role)
+		}
+
+		imp.add(req)
+	}
+}
+
+func (imp *importer) readNDJSON(r io.Reader) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, bufio.MaxScanTokenSize), maxImportLineSize)
+
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var req domain.ImportUserReq
+		if err := json.Unmarshal(line, &req); err != nil {
+			imp.fail(imp.newRow(""), fmt.Errorf("unable to decode JSON: %w", err))
+			continue
+		}
+
+		imp.add(req)
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("unable to read NDJSON: %w", err)
+	}
+	return nil
+}
+
+// add validates a single row and puts the user into the current batch.
+func (imp *importer) add(req domain.ImportUserReq) {
+	i := imp.newRow(req.Nickname)
+
+	normalized, err := imp.api.Nicknames.Validate(req.Nickname)
+	if err != nil {
+		imp.fail(i, err)
+		return
+	}
+	if hasReservedName(req.FirstName, req.LastName) {
+		imp.fail(i, errReservedName)
+		return
+	}
+	key, skeleton := nickname.Key(normalized), nickname.Skeleton(normalized)
+
+	if row, ok := imp.nicknames[key]; ok {
+		imp.skip(i, fmt.Sprintf("duplicate of row %d", row))
+		return
+	}
+	if row, ok := imp.skeletons[skeleton]; ok {
+		imp.fail(i, fmt.Errorf("nickname is too similar to nickname in row %d", row))
+		return
+	}
+
+	existing, err := imp.api.DB.GetNicknameConflict(imp.tenant, uuid.Nil, key, "")
+	if err != nil {
+		log.Warnf("ImportUsers: %s", err)
+		imp.fail(i, errImportInternal)
+		return
+	}
+	if existing != "" {
+		imp.skip(i, "user with this nickname already exists")
+		return
+	}
+
+	now := time.Now().UTC()
+	user := domain.UserProfileDTO{
+		OID:       uuid.New(),
+		Nickname:  req.Nickname,
+		FirstName: req.FirstName,
+		LastName:  req.LastName,
+		CreatedAt: now,
+		UpdatedAt: now,
+		State:     domain.Active,
+		Role:      req.Role,
+	}
+	if user.Role == 0 {
+		user.Role = domain.Usr
+	}
+	if user.Role < domain.Usr || user.Role > domain.Admin {
+		imp.fail(i, fmt.Errorf("wrong user_role %d", req.Role))
+		return
+	}
+
+	err = imp.api.prepareNickname(imp.tenant, &user, user.OID)
+	if err != nil {
+		if !isNicknamePolicyError(err) {
+			log.Warnf("ImportUsers: %s", err)
+			err = errImportInternal
+		}
+		imp.fail(i, err)
+		return
+	}
+
+	user.Password, err = imp.passwordHash(req)
+	if err != nil {
+		imp.fail(i, err)
+		return
+	}
+
+	imp.nicknames[key] = imp.report.Rows[i].Row
+	imp.skeletons[skeleton] = imp.report.Rows[i].Row
+	imp.batch = append(imp.batch, user)
+	imp.batchRows = append(imp.batchRows, i)
+	if len(imp.batch) >= imp.api.Config.Import.BatchSize {
+		imp.flush()
+	}
+}
+
+// passwordHash checks the password of a row and hashes it, pre-hashed passwords should be bcrypt hashes.
+// Passwords are only checked in dry run mode, as hashing is slow and the hash isn't needed.
+func (imp *importer) passwordHash(req domain.ImportUserReq) (string, error) {
+	if req.PasswordHash != "" {
+		if req.Password != "" {
+			return "", errors.New("only one of password and password_hash should be set")
+		}
+		if _, err := bcrypt.Cost([]byte(req.PasswordHash)); err != nil {
+			return "", errors.New("password_hash is not a bcrypt hash")
+		}
+		return req.PasswordHash, nil
+	}
+
+	if err := CheckPassword(req.Password); err != nil {
+		return "", err
+	}
+	if imp.dryRun {
+		return "", nil
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Warnf("ImportUsers - unable to generate hash for password: %s", err)
+		return "", errors.New("unable to generate hash for password")
+	}
+	return string(hash), nil
+}
+
+// flush creates users of the current batch in a single transaction. If the transaction fails,
+// users are created one by one, so that only the rows that caused the failure are reported as failed.
+func (imp *importer) flush() {
+	defer func() {
+		imp.batch = imp.batch[:0]
+		imp.batchRows = imp.batchRows[:0]
+	}()
+
+	if len(imp.batch) == 0 {
+		return
+	}
+
+	if imp.dryRun {
+		for j, user := range imp.batch {
+			imp.create(imp.batchRows[j], user.OID)
+		}
+		return
+	}
+
+	err := imp.api.DB.CreateUserProfiles(imp.tenant, imp.batch)
+	if err == nil {
+		for j, user := range imp.batch {
+			imp.create(imp.batchRows[j], user.OID)
+		}
+		return
+	}
+
+	log.Warnf("ImportUsers: batch failed, creating users one by one: %s", err)
+	for j, user := range imp.batch {
+		if err := imp.api.DB.CreateUserProfile(imp.tenant, user); err != nil {
+			log.Warnf("ImportUsers: %s", err)
+			imp.fail(imp.batchRows[j], errImportInternal)
+			continue
+		}
+		imp.create(imp.batchRows[j], user.OID)
+	}
+}
+
+func (imp *importer) newRow(nickname string) int {
+	imp.report.Rows = append(imp.report.Rows, domain.ImportRowResult{Row: len(imp.report.Rows) + 1, Nickname: nickname})
+	return len(imp.report.Rows) - 1
+}
+
+func (imp *importer) create(i int, oid uuid.UUID) {
+	imp.report.Rows[i].Status = domain.ImportCreated
+	imp.report.Rows[i].OID = &oid
+	imp.report.Created++
+}
+
+func (imp *importer) skip(i int, reason string) {
+	imp.report.Rows[i].Status = domain.ImportSkipped
+	imp.report.Rows[i].Error = reason
+	imp.report.Skipped++
+}
+
+func (imp *importer) fail(i int, err error) {
+	imp.report.Rows[i].Status = domain.ImportFailed
+	imp.report.Rows[i].Error = err.Error()
+	imp.report.Failed++
+}
+
+// importFormat detects the format of an import from the Content-Type header.
+func importFormat(contentType string) string {
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	switch mediaType {
+	case "text/csv":
+		return ImportFormatCSV
+	case "application/x-ndjson", "application/ndjson", "application/jsonl":
+		return ImportFormatNDJSON
+	}
+	return ""
+}
+
+// @Summary Import users
+// @Description Create users in bulk from CSV with a header row or from newline delimited JSON.
+// @Description Columns and fields are nickname, first_name, last_name, password or pre-hashed bcrypt password_hash, and optional user_role.
+// @Description Rows are validated like single profiles, users with existing nicknames are skipped.
+// @Tags admin
+// @Accept text/csv
+// @Accept application/x-ndjson
+// @Produce json
+// @Param format query string false "Format of the body, detected by Content-Type by default" Enums(csv, ndjson)
+// @Param dry_run query bool false "Only validate rows without creating users"
+// @Param users body string true "CSV or NDJSON rows"
+// @Success 200 {object} domain.ImportReport "Per-row report"
+// @Failure 400 {object} domain.ImportReport "Per-row report of rows read before the input turned out unreadable, or only the error"
+// @Router /admin/users/import [post]
+func (a *API) HandleImportUsers(c echo.Context) error {
+	tenant := tenantOf(c)
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to import users."})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = importFormat(c.Request().Header.Get(echo.HeaderContentType))
+	}
+
+	var dryRun bool
+	if param := c.QueryParam("dry_run"); param != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("wrong dry_run %q, should be true or false", param)})
+		}
+	}
+
+	report, err := a.ImportUsers(tenant, c.Request().Body, format, dryRun)
+	if err != nil {
+		log.Warnf("HandleImportUsers: %s", err)
+	}
+
+	// users created before a read error are kept, so they are audited and reported either way
+	if !dryRun {
+		var records []domain.AuditRecord
+		for _, row := range report.Rows {
+			if row.Status == domain.ImportCreated {
+				records = append(records, domain.AuditRecord{TargetOID: *row.OID, Action: domain.AuditImport, After: map[string]interface{}{"nickname": row.Nickname}})
+			}
+		}
+		a.audit(c, tenant, records...)
+	}
+	if err != nil {
+		if len(report.Rows) == 0 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		return c.JSON(http.StatusBadRequest, report)
+	}
+
+	log.Infof("Imported users, dry run %t: %d created, %d skipped, %d failed", dryRun, report.Created, report.Skipped, report.Failed)
+	return c.JSON(http.StatusOK, report)
+}
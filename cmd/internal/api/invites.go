@@ -0,0 +1,217 @@
+package api
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// newInviteCode returns a random code that can't be guessed.
+func newInviteCode() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("unable to generate invite code: %w", err)
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
+
+// inviteFields returns the invite as audited fields, the code itself is redacted.
+func inviteFields(invite domain.InviteDTO) map[string]interface{} {
+	return map[string]interface{}{
+		"invite_code": invite.Code,
+		"user_role":   invite.Role,
+		"group_oid":   invite.GroupOID,
+		"max_uses":    invite.MaxUses,
+		"expires_at":  invite.ExpiresAt,
+	}
+}
+
+// inviteOwner returns the user whose invite records target, the actor if the creator of the invite no longer exists.
+func inviteOwner(invite domain.InviteDTO, actor uuid.UUID) uuid.UUID {
+	if invite.CreatedBy == nil {
+		return actor
+	}
+	return *invite.CreatedBy
+}
+
+// @Summary Create invite
+// @Description Create an invite code for registration with a pre-assigned role and group. Admins can create invites for any role, other users can create invites for the user role only when REGISTRATION_USER_INVITES is on. Only owners of the group can invite to it.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param invite body domain.CreateInviteReq true "Invite, one use of the user role by default"
+// @Success 201 {object} domain.InviteDTO
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "Group not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to create invite"
+// @Router /invites [post]
+func (a *API) HandleCreateInvite(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	if userRoleFromAuth != domain.Admin && !a.Config.Registration.UserInvites {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to create invites."})
+	}
+
+	var req domain.CreateInviteReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleCreateInvite - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	if req.Role == 0 {
+		req.Role = domain.Usr
+	}
+	if req.Role < domain.Usr || req.Role > domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong user role"})
+	}
+	if req.Role != domain.Usr && userRoleFromAuth != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to invite moderators and admins."})
+	}
+	if req.MaxUses == 0 {
+		req.MaxUses = 1
+	}
+	if req.ExpiresInHours == 0 {
+		req.ExpiresInHours = a.Config.Registration.InviteTTLHours
+	}
+	if req.MaxUses < 0 || req.ExpiresInHours < 0 {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Max uses and expiration should be positive"})
+	}
+
+	if req.GroupOID != nil {
+		_, err := a.Groups.GetGroup(tenant, *req.GroupOID)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, map[string]string{"error": "Group not found"})
+			}
+			log.Warnf("HandleCreateInvite: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create invite"})
+		}
+
+		permitted, err := a.canManageGroup(tenant, *req.GroupOID, userIDFromAuth, userRoleFromAuth)
+		if err != nil {
+			log.Warnf("HandleCreateInvite: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create invite"})
+		}
+		if !permitted {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only owners of the group are permitted to manage its members."})
+		}
+	}
+
+	code, err := newInviteCode()
+	if err != nil {
+		log.Warnf("HandleCreateInvite: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create invite"})
+	}
+
+	now := time.Now().UTC()
+	invite := domain.InviteDTO{
+		Code:      code,
+		CreatedBy: &userIDFromAuth,
+		Role:      req.Role,
+		GroupOID:  req.GroupOID,
+		MaxUses:   req.MaxUses,
+		ExpiresAt: now.Add(time.Duration(req.ExpiresInHours) * time.Hour),
+		CreatedAt: now,
+	}
+
+	err = a.Invites.CreateInvite(tenant, invite)
+	if err != nil {
+		log.Warnf("HandleCreateInvite: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create invite"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userIDFromAuth, Action: domain.AuditInviteCreate, After: inviteFields(invite)})
+
+	log.Infof("User %s created an invite for %d uses expiring at %s", userIDFromAuth, invite.MaxUses, invite.ExpiresAt)
+	return c.JSON(http.StatusCreated, invite)
+}
+
+// @Summary Get invites
+// @Description Retrieve a paginated list of invites created by the user, most recent first. Admins get all invites of the organization.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Success 200 {object} domain.InvitesPage
+// @Failure 500 {object} domain.ErrorResp "Failed to get invites"
+// @Router /invites [get]
+func (a *API) HandleGetInvites(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	all := userRoleFromAuth == domain.Admin
+	pageNumber, pageSize := pageParams(c)
+
+	invites, err := a.Invites.GetInvites(tenant, userIDFromAuth, all, pageSize, (pageNumber-1)*pageSize)
+	if err != nil {
+		log.Warnf("HandleGetInvites: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get invites"})
+	}
+
+	total, err := a.Invites.GetInvitesCount(tenant, userIDFromAuth, all)
+	if err != nil {
+		log.Warnf("HandleGetInvites: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get invites"})
+	}
+
+	if invites == nil {
+		invites = []domain.InviteDTO{}
+	}
+	return c.JSON(http.StatusOK, domain.InvitesPage{TotalItems: total, CurrentPage: pageNumber, Invites: invites})
+}
+
+// @Summary Revoke invite
+// @Description Delete the invite so that it can't be used anymore. Only the user that created the invite and admins are permitted to revoke it.
+// @Tags invites
+// @Accept json
+// @Produce json
+// @Param code path string true "Invite code"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "Invite not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to revoke invite"
+// @Router /invites/{code} [delete]
+func (a *API) HandleRevokeInvite(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	code := c.Param("code")
+
+	invite, err := a.Invites.GetInvite(tenant, code)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Invite not found"})
+		}
+		log.Warnf("HandleRevokeInvite: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke invite"})
+	}
+
+	if userRoleFromAuth != domain.Admin && (invite.CreatedBy == nil || *invite.CreatedBy != userIDFromAuth) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to revoke invites of other users."})
+	}
+
+	err = a.Invites.DeleteInvite(tenant, code)
+	if err != nil {
+		log.Warnf("HandleRevokeInvite: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to revoke invite"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: inviteOwner(invite, userIDFromAuth), Action: domain.AuditInviteRevoke, Before: inviteFields(invite)})
+
+	log.Infof("User %s revoked an invite created by %v", userIDFromAuth, invite.CreatedBy)
+	return c.JSON(http.StatusOK, map[string]string{"message": "Invite revoked successfully."})
+}
@@ -0,0 +1,58 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/leaderboard"
+)
+
+// @Summary Get leaderboard
+// @Description Retrieve users with the most votes, only active users with public profiles are listed. Leaderboards are cached for a short time
+// @Tags vote
+// @Accept json
+// @Produce json
+// @Param emoji query int false "Count votes of this emoji only, 1 to 5"
+// @Param window query string false "Count votes cast or changed within the last day, week, month or all (default)"
+// @Param limit query int false "Number of users, 10 by default"
+// @Param Authorization header string false "Optional bearer token, profiles are rendered according to privacy settings of the users and identity of the viewer"
+// @Success 200 {object} domain.Leaderboard
+// @Failure 400 {object} domain.ErrorResp "Wrong value"
+// @Failure 500 {object} domain.ErrorResp "Failed to get leaderboard"
+// @Router /leaderboard [get]
+func (a *API) HandleGetLeaderboard(c echo.Context) error {
+	tenant := tenantOf(c)
+	viewerID, viewerRole := viewer(c)
+
+	query := domain.LeaderboardQuery{Window: domain.WindowAll, Limit: defaultPageSize}
+	if emoji := c.QueryParam("emoji"); emoji != "" {
+		emojiID, err := strconv.Atoi(emoji)
+		if err != nil || emojiID < 1 || emojiID > 5 {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong value"})
+		}
+		query.EmojiID = int32(emojiID)
+	}
+	if window := c.QueryParam("window"); window != "" {
+		query.Window = domain.LeaderboardWindow(window)
+		if !leaderboard.ValidWindow(query.Window) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong window"})
+		}
+	}
+	if limit, err := strconv.Atoi(c.QueryParam("limit")); err == nil && limit > 0 {
+		query.Limit = min(limit, a.Config.Leaderboard.MaxLimit)
+	}
+
+	board, err := a.Leaderboard.Get(tenant, query)
+	if err != nil {
+		log.Warnf("HandleGetLeaderboard: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get leaderboard"})
+	}
+
+	for i, entry := range board.Entries {
+		board.Entries[i].User = renderProfile(entry.User, viewerID, viewerRole)
+	}
+	return c.JSON(http.StatusOK, board)
+}
@@ -0,0 +1,150 @@
+package api
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/i18n"
+)
+
+const preferencesKey = "preferences"
+
+var timeType = reflect.TypeOf(time.Time{})
+
+// savedPreferences are preferences of the viewer kept in the request context, saved is false if they are defaults.
+type savedPreferences struct {
+	preferences domain.Preferences
+	saved       bool
+}
+
+// localizingSerializer translates error messages into the locale of the viewer and, if the request has local_time=true,
+// renders timestamps in the timezone of the viewer.
+type localizingSerializer struct {
+	echo.DefaultJSONSerializer
+	api *API
+}
+
+// JSONSerializer returns the serializer of API responses, it should be set as the JSON serializer of echo.
+func (a *API) JSONSerializer() echo.JSONSerializer {
+	return &localizingSerializer{api: a}
+}
+
+func (s *localizingSerializer) Serialize(c echo.Context, i interface{}, indent string) error {
+	if resp, ok := i.(map[string]string); ok {
+		if msg, ok := resp["error"]; ok {
+			i = map[string]string{"error": i18n.Translate(s.api.locale(c), msg)}
+		}
+	}
+
+	if localTime, _ := strconv.ParseBool(c.QueryParam("local_time")); localTime && i != nil {
+		if loc := s.api.timezone(c); loc != nil {
+			i = inLocation(reflect.ValueOf(i), loc).Interface()
+		}
+	}
+
+	return s.DefaultJSONSerializer.Serialize(c, i, indent)
+}
+
+// viewerPreferences returns preferences of the authenticated viewer, loading them once per request.
+// The last value is false for anonymous requests or if preferences can't be loaded.
+func (a *API) viewerPreferences(c echo.Context) (domain.Preferences, bool, bool) {
+	tenant := tenantOf(c)
+	if cached, ok := c.Get(preferencesKey).(savedPreferences); ok {
+		return cached.preferences, cached.saved, true
+	}
+
+	viewerID, _ := viewer(c)
+	if viewerID == uuid.Nil {
+		return domain.Preferences{}, false, false
+	}
+
+	preferences, saved, err := a.getPreferences(tenant, viewerID)
+	if err != nil {
+		log.Warnf("viewerPreferences: %s", err)
+		return domain.Preferences{}, false, false
+	}
+	c.Set(preferencesKey, savedPreferences{preferences: preferences, saved: saved})
+	return preferences, saved, true
+}
+
+// locale returns the locale saved by the viewer, or the one picked by Accept-Language, or the default one.
+func (a *API) locale(c echo.Context) string {
+	if preferences, saved, ok := a.viewerPreferences(c); ok && saved {
+		return preferences.Locale
+	}
+	return i18n.Match(c.Request().Header.Get("Accept-Language"), a.Config.Preferences.Locale)
+}
+
+// timezone returns the timezone of the viewer, or nil for anonymous requests.
+func (a *API) timezone(c echo.Context) *time.Location {
+	preferences, _, ok := a.viewerPreferences(c)
+	if !ok {
+		return nil
+	}
+	loc, err := time.LoadLocation(preferences.Timezone)
+	if err != nil {
+		log.Warnf("timezone: %s", err)
+		return nil
+	}
+	return loc
+}
+
+// inLocation returns a copy of v with all exported timestamps in it converted to loc, zero timestamps are left as they are.
+func inLocation(v reflect.Value, loc *time.Location) reflect.Value {
+	switch v.Kind() {
+	case reflect.Struct:
+		if v.Type() == timeType {
+			if t := v.Interface().(time.Time); !t.IsZero() {
+				return reflect.ValueOf(t.In(loc))
+			}
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(v)
+		for i := 0; i < out.NumField(); i++ {
+			if out.Field(i).CanSet() {
+				out.Field(i).Set(inLocation(v.Field(i), loc))
+			}
+		}
+		return out
+	case reflect.Pointer:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type().Elem())
+		out.Elem().Set(inLocation(v.Elem(), loc))
+		return out
+	case reflect.Interface:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.New(v.Type()).Elem()
+		out.Set(inLocation(v.Elem(), loc))
+		return out
+	case reflect.Slice:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeSlice(v.Type(), v.Len(), v.Len())
+		for i := 0; i < v.Len(); i++ {
+			out.Index(i).Set(inLocation(v.Index(i), loc))
+		}
+		return out
+	case reflect.Map:
+		if v.IsNil() {
+			return v
+		}
+		out := reflect.MakeMapWithSize(v.Type(), v.Len())
+		iter := v.MapRange()
+		for iter.Next() {
+			out.SetMapIndex(iter.Key(), inLocation(iter.Value(), loc))
+		}
+		return out
+	}
+	return v
+}
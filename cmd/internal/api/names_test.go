@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+func TestReservedNamesRejected(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	user := s.store.addUser(org.ID, "alice", domain.Usr)
+
+	for _, name := range []string{"pii:v1:k1:x:y", "pii:anything"} {
+		rec := s.do(http.MethodPut, "/api/users/"+user.OID.String(), "", s.token(org.ID, user), updateProfileBody(user, name))
+		if rec.Code != http.StatusBadRequest {
+			t.Errorf("update first name to %q: status = %d, want %d", name, rec.Code, http.StatusBadRequest)
+		}
+	}
+	if got, _ := s.store.user(org.ID, user.OID); got.FirstName != user.FirstName {
+		t.Errorf("reserved first name stored: %+v", got)
+	}
+	if rec := s.do(http.MethodGet, "/api/users/"+user.OID.String(), "", "", ""); rec.Code != http.StatusOK {
+		t.Errorf("read profile back: status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	// names merely containing the prefix are fine
+	rec := s.do(http.MethodPut, "/api/users/"+user.OID.String(), "", s.token(org.ID, user), updateProfileBody(user, "Api:pii:"))
+	if rec.Code != http.StatusOK {
+		t.Errorf("update first name: status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+}
@@ -0,0 +1,125 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+)
+
+// nicknamePolicyError is returned when a nickname can't be used because of nickname rules,
+// as opposed to errors that happened while checking them.
+type nicknamePolicyError struct {
+	error
+}
+
+var errNicknameReserved = nicknamePolicyError{errors.New("nickname was recently released by another user and is reserved for now, please choose another one")}
+
+// prepareNickname validates and normalizes user.Nickname and checks that it doesn't collide with
+// nicknames of other users, either case-insensitively or by confusable skeleton, and isn't reserved.
+func (a *API) prepareNickname(tenant uuid.UUID, user *domain.UserProfileDTO, oid uuid.UUID) error {
+	normalized, err := a.Nicknames.Validate(user.Nickname)
+	if err != nil {
+		return nicknamePolicyError{err}
+	}
+	user.Nickname = normalized
+	user.NicknameNormalized = nickname.Key(normalized)
+	user.NicknameSkeleton = nickname.Skeleton(normalized)
+
+	conflict, err := a.DB.GetNicknameConflict(tenant, oid, user.NicknameNormalized, user.NicknameSkeleton)
+	if err != nil {
+		return err
+	}
+	if conflict != "" {
+		return nicknamePolicyError{fmt.Errorf("nickname is taken or too similar to existing nickname %q", conflict)}
+	}
+
+	return a.checkNicknameReserved(tenant, user.NicknameNormalized, oid)
+}
+
+// keepNickname fills normalized forms of an unchanged nickname. Skeleton is left empty
+// if it collides with another profile, which is possible for nicknames created before skeleton checks.
+func (a *API) keepNickname(tenant uuid.UUID, user *domain.UserProfileDTO, current string, oid uuid.UUID) error {
+	user.Nickname = current
+	user.NicknameNormalized = nickname.Key(current)
+	user.NicknameSkeleton = nickname.Skeleton(current)
+
+	conflict, err := a.DB.GetNicknameConflict(tenant, oid, "", user.NicknameSkeleton)
+	if err != nil {
+		return err
+	}
+	if conflict != "" {
+		user.NicknameSkeleton = ""
+	}
+	return nil
+}
+
+// checkNicknameReserved returns errNicknameReserved if nickname, given in normalized form,
+// was released by a user other than oid during the reservation window.
+func (a *API) checkNicknameReserved(tenant uuid.UUID, normalized string, oid uuid.UUID) error {
+	reservation := time.Duration(a.Config.Nickname.ReservationHours) * time.Hour
+	reservedBy, err := a.DB.GetNicknameReservation(tenant, normalized, time.Now().UTC().Add(-reservation))
+	if err != nil {
+		return err
+	}
+	if reservedBy != uuid.Nil && reservedBy != oid {
+		return errNicknameReserved
+	}
+	return nil
+}
+
+// checkNicknameCooldown returns a nicknamePolicyError if the nickname of oid was changed less than the configured cooldown ago.
+func (a *API) checkNicknameCooldown(tenant uuid.UUID, oid uuid.UUID) error {
+	lastChange, err := a.DB.GetLastNicknameChange(tenant, oid)
+	if err != nil {
+		return err
+	}
+	nextChange := lastChange.Add(time.Duration(a.Config.Nickname.ChangeCooldownHours) * time.Hour)
+	if nextChange.After(time.Now().UTC()) {
+		return nicknamePolicyError{fmt.Errorf("nickname was changed recently, next change is allowed after %s", nextChange.Format(time.RFC3339))}
+	}
+	return nil
+}
+
+func isNicknamePolicyError(err error) bool {
+	var policyErr nicknamePolicyError
+	return errors.As(err, &policyErr)
+}
+
+// @Summary Resolve nickname
+// @Description Resolve a current or former nickname to the current profile, hidden profiles are resolved only for their owners and moderators
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param nickname path string true "Current or former nickname"
+// @Success 200 {object} domain.NicknameDTO
+// @Failure 404 {object} domain.ErrorResp "Nickname not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to resolve nickname"
+// @Router /users/nickname/{nickname} [get]
+func (a *API) HandleResolveNickname(c echo.Context) error {
+	tenant := tenantOf(c)
+	resolved, err := a.DB.ResolveNickname(tenant, nickname.Key(c.Param("nickname")))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return c.JSON(http.StatusNotFound, map[string]string{"error": "Nickname not found"})
+		}
+		log.Warnf("HandleResolveNickname: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resolve nickname"})
+	}
+
+	// nicknames of hidden profiles, current or former, don't reveal them to others
+	viewerID, viewerRole := viewer(c)
+	user := domain.UserProfileDTO{OID: resolved.OID, Privacy: &domain.PrivacySettings{ProfileVisibility: resolved.Visibility}}
+	if !canSeeProfile(user, viewerID, viewerRole) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "Nickname not found"})
+	}
+
+	return c.JSON(http.StatusOK, resolved)
+}
@@ -0,0 +1,43 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+)
+
+func TestResolveNicknameOfHiddenProfile(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	user := s.store.addUser(org.ID, "alice", domain.Usr)
+	other := s.store.addUser(org.ID, "bob", domain.Usr)
+	moderator := s.store.addUser(org.ID, "carol", domain.Moderator)
+
+	s.store.mu.Lock()
+	user.Privacy = &domain.PrivacySettings{ProfileVisibility: domain.ProfileHidden, RealNameVisibility: domain.NameEveryone}
+	s.store.users[org.ID][user.OID] = user
+	s.store.formerNicknames[user.OID] = nickname.Key("alice_old")
+	s.store.mu.Unlock()
+
+	for _, tc := range []struct {
+		name   string
+		token  string
+		status int
+	}{
+		{"anonymous", "", http.StatusNotFound},
+		{"other user", s.token(org.ID, other), http.StatusNotFound},
+		{"owner", s.token(org.ID, user), http.StatusOK},
+		{"moderator", s.token(org.ID, moderator), http.StatusOK},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			for _, nick := range []string{"alice", "alice_old"} {
+				rec := s.do(http.MethodGet, "/api/users/nickname/"+nick, "", tc.token, "")
+				if rec.Code != tc.status {
+					t.Errorf("resolve %s: status = %d, want %d: %s", nick, rec.Code, tc.status, rec.Body)
+				}
+			}
+		})
+	}
+}
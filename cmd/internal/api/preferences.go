@@ -0,0 +1,164 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+	_ "time/tzdata"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/i18n"
+)
+
+// defaultPreferences returns preferences of users that haven't set their own.
+func (a *API) defaultPreferences() domain.Preferences {
+	cfg := a.Config.Preferences
+	return domain.Preferences{
+		Locale:     cfg.Locale,
+		Timezone:   cfg.Timezone,
+		DateFormat: domain.DateFormat(cfg.DateFormat),
+		Notifications: domain.NotificationSettings{
+			Votes:      cfg.NotifyVotes,
+			Follows:    cfg.NotifyFollows,
+			Newsletter: cfg.NotifyNewsletter,
+		},
+	}
+}
+
+// getPreferences returns preferences of the user, falling back to defaults. The second value is false if defaults are returned.
+func (a *API) getPreferences(tenant uuid.UUID, oid uuid.UUID) (domain.Preferences, bool, error) {
+	preferences, found, err := a.Prefs.GetPreferences(tenant, oid)
+	if err != nil {
+		return domain.Preferences{}, false, err
+	}
+	if !found {
+		return a.defaultPreferences(), false, nil
+	}
+	return preferences, true, nil
+}
+
+func preferencesFields(preferences domain.Preferences) map[string]interface{} {
+	return map[string]interface{}{
+		"locale":                   preferences.Locale,
+		"timezone":                 preferences.Timezone,
+		"date_format":              preferences.DateFormat,
+		"notifications_votes":      preferences.Notifications.Votes,
+		"notifications_follows":    preferences.Notifications.Follows,
+		"notifications_newsletter": preferences.Notifications.Newsletter,
+	}
+}
+
+func checkPreferences(preferences domain.Preferences) error {
+	if !i18n.IsSupported(preferences.Locale) {
+		return fmt.Errorf("wrong locale %q, should be one of: %s", preferences.Locale, strings.Join(i18n.Locales(), ", "))
+	}
+	if preferences.Timezone == "" || preferences.Timezone == "Local" {
+		return fmt.Errorf("wrong timezone %q, should be an IANA time zone name", preferences.Timezone)
+	}
+	if _, err := time.LoadLocation(preferences.Timezone); err != nil {
+		return fmt.Errorf("wrong timezone %q, should be an IANA time zone name", preferences.Timezone)
+	}
+	switch preferences.DateFormat {
+	case domain.DateFormatISO, domain.DateFormatDMY, domain.DateFormatMDY:
+	default:
+		return fmt.Errorf("wrong date format %q, should be one of: iso, dmy, mdy", preferences.DateFormat)
+	}
+	return nil
+}
+
+// @Summary Get preferences
+// @Description Get locale, timezone, date format and notification settings of the user, defaults are returned if the user hasn't set them
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.Preferences
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to get preferences"
+// @Router /users/{id}/preferences [get]
+func (a *API) HandleGetPreferences(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetPreferences: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to view preferences of other users."})
+	}
+
+	preferences, _, err := a.getPreferences(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleGetPreferences: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get preferences"})
+	}
+
+	return c.JSON(http.StatusOK, preferences)
+}
+
+// @Summary Update preferences
+// @Description Update locale, timezone, date format and notification settings of the user, fields that are left out keep their current values
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param preferences body domain.Preferences true "Preferences"
+// @Success 200 {object} domain.Preferences
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 500 {object} domain.ErrorResp "Failed to update preferences"
+// @Router /users/{id}/preferences [put]
+func (a *API) HandleUpdatePreferences(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdatePreferences: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to update preferences of other users."})
+	}
+
+	preferences, _, err := a.getPreferences(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleUpdatePreferences: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update preferences"})
+	}
+	before := preferencesFields(preferences)
+
+	// the request is decoded over current preferences, so that it can hold only the fields being changed
+	if err := c.Bind(&preferences); err != nil {
+		log.Warnf("HandleUpdatePreferences - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := checkPreferences(preferences); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	err = a.Prefs.UpdatePreferences(tenant, userID, preferences)
+	if err != nil {
+		log.Warnf("HandleUpdatePreferences: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update preferences"})
+	}
+
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditPreferences, Before: before, After: preferencesFields(preferences)})
+
+	// the response is rendered with the preferences that were just saved
+	if userID == userIDFromAuth {
+		c.Set(preferencesKey, savedPreferences{preferences: preferences, saved: true})
+	}
+
+	log.Infof("Successfully updated preferences for user oid %s", userID.String())
+	return c.JSON(http.StatusOK, preferences)
+}
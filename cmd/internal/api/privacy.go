@@ -0,0 +1,206 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// OptionalJWTMiddleware authenticates the request if it has a token and lets anonymous requests through,
+// so that read routes can render profiles depending on who is looking at them.
+func (a *API) OptionalJWTMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	authenticated := a.JWTMiddleware(next)
+	return func(c echo.Context) error {
+		if c.Request().Header.Get("Authorization") == "" {
+			return next(c)
+		}
+		return authenticated(c)
+	}
+}
+
+// viewer returns oid and role of the authenticated user, or uuid.Nil and zero role for anonymous requests.
+func viewer(c echo.Context) (uuid.UUID, domain.Role) {
+	viewerID, _ := c.Get("oid").(uuid.UUID)
+	viewerRole, _ := c.Get("role").(domain.Role)
+	return viewerID, viewerRole
+}
+
+// privacyOf returns privacy settings of user, falling back to defaults if they weren't loaded.
+func privacyOf(user domain.UserProfileDTO) domain.PrivacySettings {
+	if user.Privacy == nil {
+		return domain.PrivacySettings{ProfileVisibility: domain.ProfilePublic, RealNameVisibility: domain.NameEveryone}
+	}
+	return *user.Privacy
+}
+
+func isSelfOrModerator(user domain.UserProfileDTO, viewerID uuid.UUID, viewerRole domain.Role) bool {
+	return (viewerID != uuid.Nil && viewerID == user.OID) || viewerRole >= domain.Moderator
+}
+
+func canSeeProfile(user domain.UserProfileDTO, viewerID uuid.UUID, viewerRole domain.Role) bool {
+	return privacyOf(user).ProfileVisibility != domain.ProfileHidden || isSelfOrModerator(user, viewerID, viewerRole)
+}
+
+func canSeeName(user domain.UserProfileDTO, viewerID uuid.UUID, viewerRole domain.Role) bool {
+	switch privacyOf(user).RealNameVisibility {
+	case domain.NameUsers:
+		return viewerID != uuid.Nil
+	case domain.NameSelf:
+		return isSelfOrModerator(user, viewerID, viewerRole)
+	}
+	return true
+}
+
+func canSeeRatingBreakdown(user domain.UserProfileDTO, viewerID uuid.UUID, viewerRole domain.Role) bool {
+	return !privacyOf(user).HideRatingBreakdown || isSelfOrModerator(user, viewerID, viewerRole)
+}
+
+// renderProfile strips the parts of user that the viewer isn't allowed to see.
+// Profiles are cached as they are stored, so this has to be applied after reading the cache as well.
+func renderProfile(user domain.UserProfileDTO, viewerID uuid.UUID, viewerRole domain.Role) domain.UserProfileDTO {
+	user.Attributes = VisibleAttributes(user, viewerID, viewerRole)
+	user.AttributesVisibility = nil
+	if !canSeeName(user, viewerID, viewerRole) {
+		user.FirstName = ""
+		user.LastName = ""
+	}
+	user.Privacy = nil
+	return user
+}
+
+// nameVisibilities lists real name visibilities, names with which can be matched by search of the viewer.
+func nameVisibilities(viewerID uuid.UUID, viewerRole domain.Role) []domain.NameVisibility {
+	switch {
+	case viewerRole >= domain.Moderator:
+		return []domain.NameVisibility{domain.NameEveryone, domain.NameUsers, domain.NameSelf}
+	case viewerID != uuid.Nil:
+		return []domain.NameVisibility{domain.NameEveryone, domain.NameUsers}
+	}
+	return []domain.NameVisibility{domain.NameEveryone}
+}
+
+func privacyFields(privacy domain.PrivacySettings) map[string]interface{} {
+	return map[string]interface{}{
+		"profile_visibility":    privacy.ProfileVisibility,
+		"real_name_visibility":  privacy.RealNameVisibility,
+		"hide_rating_breakdown": privacy.HideRatingBreakdown,
+	}
+}
+
+func checkPrivacy(privacy domain.PrivacySettings) error {
+	switch privacy.ProfileVisibility {
+	case domain.ProfilePublic, domain.ProfileUnlisted, domain.ProfileHidden:
+	default:
+		return fmt.Errorf("wrong profile visibility %q, should be one of: public, unlisted, hidden", privacy.ProfileVisibility)
+	}
+	switch privacy.RealNameVisibility {
+	case domain.NameEveryone, domain.NameUsers, domain.NameSelf:
+	default:
+		return fmt.Errorf("wrong real name visibility %q, should be one of: everyone, users, self", privacy.RealNameVisibility)
+	}
+	return nil
+}
+
+// @Summary Get privacy settings
+// @Description Get privacy settings of the profile
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Success 200 {object} domain.PrivacySettings
+// @Failure 400 {object} domain.ErrorResp "Wrong UserId"
+// @Failure 500 {object} domain.ErrorResp "Failed to get privacy settings"
+// @Router /users/{id}/privacy [get]
+func (a *API) HandleGetPrivacy(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetPrivacy: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to view privacy settings of other users."})
+	}
+
+	user, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleGetPrivacy: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get privacy settings"})
+	}
+
+	return c.JSON(http.StatusOK, privacyOf(user))
+}
+
+// @Summary Update privacy settings
+// @Description Set profile visibility, real name visibility and whether the rating breakdown is hidden from other users
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param privacy body domain.PrivacySettings true "Privacy settings"
+// @Success 200 {object} domain.MessageResp
+// @Failure 400 {object} domain.ErrorResp "Invalid request payload"
+// @Failure 500 {object} domain.ErrorResp "Failed to update privacy settings"
+// @Router /users/{id}/privacy [put]
+func (a *API) HandleUpdatePrivacy(c echo.Context) error {
+	tenant := tenantOf(c)
+	userRoleFromAuth := c.Get("role").(domain.Role)
+	userIDFromAuth := c.Get("oid").(uuid.UUID)
+
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleUpdatePrivacy: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	if userID != userIDFromAuth && userRoleFromAuth == domain.Usr {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "You are not permitted to update privacy settings of other users."})
+	}
+
+	var privacy domain.PrivacySettings
+	if err := c.Bind(&privacy); err != nil {
+		log.Warnf("HandleUpdatePrivacy - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+	if err := checkPrivacy(privacy); err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	currentUser, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleUpdatePrivacy: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update privacy settings"})
+	}
+
+	err = a.DB.UpdatePrivacy(tenant, privacy, userID)
+	if err != nil {
+		log.Warnf("HandleUpdatePrivacy: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to update privacy settings"})
+	}
+
+	var before map[string]interface{}
+	if currentUser.Privacy != nil {
+		before = privacyFields(*currentUser.Privacy)
+	}
+	a.audit(c, tenant, domain.AuditRecord{TargetOID: userID, Action: domain.AuditPrivacy, Before: before, After: privacyFields(privacy)})
+
+	// cached list pages hold the profile with its previous settings, so it could stay listed or named there
+	err = a.Cache.Delete(tenant, userID.String())
+	if err == nil {
+		err = a.Cache.InvalidateLists(tenant)
+	}
+	if err != nil {
+		log.Warnf("HandleUpdatePrivacy: unable to invalidate cache: %s", err)
+	}
+
+	log.Infof("Successfully updated privacy settings for user oid %s", userID.String())
+	return c.JSON(http.StatusOK, map[string]string{"message": "Privacy settings updated successfully."})
+}
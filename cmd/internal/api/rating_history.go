@@ -0,0 +1,121 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+const (
+	defaultHistoryBuckets = 30
+	maxHistoryBuckets     = 1000
+)
+
+// @Summary Get rating history
+// @Description Retrieve votes received by the user per interval, votes are counted by the time they were cast or last changed and intervals without votes are returned empty
+// @Tags vote
+// @Accept json
+// @Produce json
+// @Param id path string true "User ID"
+// @Param interval query string false "Bucket size: hour, day (default), week or month, weeks start on Monday"
+// @Param from query string false "Start of the period, RFC 3339, 30 intervals before the end by default"
+// @Param to query string false "End of the period, exclusive, RFC 3339, now by default"
+// @Param timezone query string false "IANA time zone the buckets are aligned to, UTC by default"
+// @Param Authorization header string false "Optional bearer token, the breakdown by emoji is rendered according to privacy settings of the user and identity of the viewer"
+// @Success 200 {object} domain.RatingHistory
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 404 {object} domain.ErrorResp "User not found"
+// @Failure 500 {object} domain.ErrorResp "Failed to get rating history"
+// @Router /users/{id}/rating/history [get]
+func (a *API) HandleGetRatingHistory(c echo.Context) error {
+	tenant := tenantOf(c)
+	userID, err := uuid.Parse(c.Param("id"))
+	if err != nil {
+		log.Warnf("HandleGetRatingHistory: unable to parse uuid: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong UserId"})
+	}
+
+	viewerID, viewerRole := viewer(c)
+
+	interval := domain.IntervalDay
+	if value := c.QueryParam("interval"); value != "" {
+		interval = domain.RatingInterval(value)
+		if !interval.Valid() {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong interval"})
+		}
+	}
+
+	loc := time.UTC
+	if value := c.QueryParam("timezone"); value != "" {
+		loc, err = time.LoadLocation(value)
+		if value == "Local" || err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong timezone"})
+		}
+	}
+
+	var from, to time.Time
+	for param, dest := range map[string]*time.Time{"from": &from, "to": &to} {
+		if value := c.QueryParam(param); value != "" {
+			t, err := time.Parse(time.RFC3339, value)
+			if err != nil {
+				return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong " + param + " time, should be RFC 3339"})
+			}
+			*dest = t.In(loc)
+		}
+	}
+	if to.IsZero() {
+		to = time.Now().In(loc)
+	}
+	if from.IsZero() {
+		from = interval.Start(to)
+		for i := 1; i < defaultHistoryBuckets; i++ {
+			from = interval.Start(from.Add(-time.Nanosecond))
+		}
+	}
+	if !from.Before(to) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Wrong period, from should be before to"})
+	}
+	buckets := 0
+	for bucket := interval.Start(from); bucket.Before(to); bucket = interval.Next(bucket) {
+		if buckets++; buckets > maxHistoryBuckets {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": "Too many intervals, narrow the period or use a larger interval"})
+		}
+	}
+
+	user, err := a.DB.GetUserById(tenant, userID)
+	if err != nil {
+		log.Warnf("HandleGetRatingHistory: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get rating history"})
+	}
+	if user.State == domain.Deleted || !canSeeProfile(user, viewerID, viewerRole) {
+		return c.JSON(http.StatusNotFound, map[string]string{"error": "User not found"})
+	}
+
+	var excluded []uuid.UUID
+	if a.Config.Block.ExcludeVotes {
+		excluded, err = a.Blocks.GetBlockedOIDs(tenant, user.OID)
+		if err != nil {
+			log.Warnf("HandleGetRatingHistory: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get rating history"})
+		}
+	}
+
+	history := domain.RatingHistory{Interval: interval, Timezone: loc.String(), From: from, To: to}
+	history.Buckets, err = a.Rating.GetRatingHistory(tenant, user.OID, excluded, interval, from, to)
+	if err != nil {
+		log.Warnf("HandleGetRatingHistory: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get rating history"})
+	}
+
+	// without access to the breakdown only totals per interval are shown
+	if !canSeeRatingBreakdown(user, viewerID, viewerRole) {
+		for i := range history.Buckets {
+			history.Buckets[i].Emojis = nil
+		}
+	}
+	return c.JSON(http.StatusOK, history)
+}
@@ -0,0 +1,79 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// @Summary Search users
+// @Description Full-text and fuzzy search by nickname, first name and last name, ranked by relevance
+// @Tags users
+// @Accept json
+// @Produce json
+// @Param q query string true "Search query, words are matched by prefix and with typo tolerance"
+// @Param page query int false "Page number"
+// @Param limit query int false "Items per page"
+// @Param Authorization header string false "Optional bearer token, real names are matched and shown according to privacy settings of the users and identity of the viewer"
+// @Success 200 {object} domain.SearchUsersResp "Paginated search results"
+// @Failure 400 {object} domain.ErrorResp "Empty search query"
+// @Failure 500 {object} domain.ErrorResp "Failed to search users"
+// @Router /users/search [get]
+func (a *API) HandleSearchUsers(c echo.Context) error {
+	tenant := tenantOf(c)
+	query := strings.TrimSpace(c.QueryParam("q"))
+	if query == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Empty search query"})
+	}
+
+	pageNumber, pageSize := pageParams(c)
+	offset := (pageNumber - 1) * pageSize
+	viewerID, viewerRole := viewer(c)
+	visibilities := nameVisibilities(viewerID, viewerRole)
+
+	results, err := a.DB.SearchUsers(tenant, query, visibilities, pageSize, offset)
+	if err != nil {
+		log.Warnf("HandleSearchUsers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search users"})
+	}
+
+	oids := make([]uuid.UUID, 0, len(results))
+	for _, result := range results {
+		oids = append(oids, result.OID)
+	}
+
+	if len(oids) > 0 {
+		ratings, err := a.Rating.GetRatingForList(tenant, oids)
+		if err != nil {
+			log.Warnf("HandleSearchUsers: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search users"})
+		}
+		for i, result := range results {
+			results[i].Rating = ratings[result.OID].Total
+		}
+	}
+
+	for i, result := range results {
+		if !canSeeName(result.UserProfileDTO, viewerID, viewerRole) {
+			delete(results[i].Highlights, "first_name")
+			delete(results[i].Highlights, "last_name")
+		}
+		results[i].UserProfileDTO = renderProfile(result.UserProfileDTO, viewerID, viewerRole)
+	}
+
+	totalUsers, err := a.DB.SearchUsersCount(tenant, query, visibilities)
+	if err != nil {
+		log.Warnf("HandleSearchUsers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to search users"})
+	}
+
+	return c.JSON(http.StatusOK, domain.Pagination[domain.SearchResultDTO]{
+		TotalItems:  &totalUsers,
+		CurrentPage: pageNumber,
+		Users:       results,
+	})
+}
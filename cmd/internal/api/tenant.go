@@ -0,0 +1,209 @@
+package api
+
+import (
+	"database/sql"
+	"errors"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// slugPattern allows slugs that can be used as a subdomain.
+var slugPattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// TenantMiddleware resolves the organization of the request from the tenant header or the subdomain of TENANT_BASE_DOMAIN,
+// falling back to the default organization. JWTMiddleware later replaces it with the organization of the token
+// if the request didn't name one explicitly.
+func (a *API) TenantMiddleware(next echo.HandlerFunc) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		slug, explicit := a.tenantSlug(c.Request())
+
+		org, err := a.organization(slug)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return c.JSON(http.StatusNotFound, map[string]string{"error": "Organization not found"})
+			}
+			log.Warnf("TenantMiddleware: %s", err)
+			return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to resolve organization"})
+		}
+
+		c.Set("tenant", org.ID)
+		c.Set("tenant_explicit", explicit)
+
+		return next(c)
+	}
+}
+
+// tenantSlug returns the slug of the organization named by the request, the second value is false if none was named.
+func (a *API) tenantSlug(r *http.Request) (string, bool) {
+	if slug := strings.ToLower(strings.TrimSpace(r.Header.Get(a.Config.Tenant.Header))); slug != "" {
+		return slug, true
+	}
+
+	if base := a.Config.Tenant.BaseDomain; base != "" {
+		host := strings.ToLower(r.Host)
+		if i := strings.LastIndexByte(host, ':'); i != -1 {
+			host = host[:i]
+		}
+		if sub, ok := strings.CutSuffix(host, "."+base); ok && sub != "" && !strings.Contains(sub, ".") {
+			return sub, true
+		}
+	}
+
+	return a.Config.Tenant.Default, false
+}
+
+// organization returns the organization by slug, organizations are never changed once created so they are kept in memory.
+func (a *API) organization(slug string) (domain.Organization, error) {
+	if org, ok := a.orgs.Load(slug); ok {
+		return org.(domain.Organization), nil
+	}
+
+	org, err := a.Orgs.GetOrganizationBySlug(slug)
+	if err != nil {
+		return domain.Organization{}, err
+	}
+	a.orgs.Store(slug, org)
+	return org, nil
+}
+
+// tenantOf returns the organization the request belongs to.
+func tenantOf(c echo.Context) uuid.UUID {
+	tenant, _ := c.Get("tenant").(uuid.UUID)
+	return tenant
+}
+
+// isDefaultTenantAdmin checks that the request is made by an admin of the default organization,
+// only they can manage organizations.
+func (a *API) isDefaultTenantAdmin(c echo.Context) bool {
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return false
+	}
+	org, err := a.organization(a.Config.Tenant.Default)
+	if err != nil {
+		log.Warnf("isDefaultTenantAdmin: %s", err)
+		return false
+	}
+	return tenantOf(c) == org.ID
+}
+
+// @Summary Create organization
+// @Description Create an organization along with its first admin. Only admins of the default organization are permitted to create organizations.
+// @Tags admin
+// @Accept json
+// @Produce json
+// @Param organization body domain.CreateOrganizationReq true "Organization and its admin"
+// @Success 201 {object} domain.Organization
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 409 {object} domain.ErrorResp "Organization already exists"
+// @Failure 500 {object} domain.ErrorResp
+// @Router /admin/organizations [post]
+func (a *API) HandleCreateOrganization(c echo.Context) error {
+	if !a.isDefaultTenantAdmin(c) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins of the default organization are permitted to create organizations."})
+	}
+
+	var req domain.CreateOrganizationReq
+	if err := c.Bind(&req); err != nil {
+		log.Warnf("HandleCreateOrganization - unable to decode JSON: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	}
+
+	req.Slug = strings.ToLower(strings.TrimSpace(req.Slug))
+	if !slugPattern.MatchString(req.Slug) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Slug should be up to 63 lowercase letters, digits and hyphens, not starting or ending with a hyphen"})
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Name is required"})
+	}
+
+	_, err := a.organization(req.Slug)
+	if err == nil {
+		return c.JSON(http.StatusConflict, map[string]string{"error": "Organization already exists"})
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		log.Warnf("HandleCreateOrganization: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create organization"})
+	}
+
+	if hasReservedName(req.Admin.FirstName, req.Admin.LastName) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "First and last names can't start with \"pii:\""})
+	}
+
+	err = CheckPassword(req.Admin.Password)
+	if err != nil {
+		log.Warnf("HandleCreateOrganization - user provided wrong password: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(req.Admin.Password), bcrypt.DefaultCost)
+	if err != nil {
+		log.Warnf("HandleCreateOrganization - unable to generate hash for password: %s", err)
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Unable to generate hash for password"})
+	}
+
+	org := domain.Organization{
+		ID:        uuid.New(),
+		Slug:      req.Slug,
+		Name:      strings.TrimSpace(req.Name),
+		CreatedAt: time.Now().UTC(),
+	}
+	admin := domain.UserProfileDTO{
+		OID:       uuid.New(),
+		Nickname:  req.Admin.Nickname,
+		FirstName: req.Admin.FirstName,
+		LastName:  req.Admin.LastName,
+		Password:  string(hash),
+	}
+
+	// the organization is empty, so the nickname is checked only against the policy
+	err = a.prepareNickname(org.ID, &admin, admin.OID)
+	if err != nil {
+		if isNicknamePolicyError(err) {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+		}
+		log.Warnf("HandleCreateOrganization: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create organization"})
+	}
+
+	err = a.Orgs.CreateOrganization(org, admin)
+	if err != nil {
+		log.Warnf("HandleCreateOrganization: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to create organization"})
+	}
+
+	log.Infof("Successfully created organization %s with id %s and admin %s", org.Slug, org.ID, admin.Nickname)
+	return c.JSON(http.StatusCreated, org)
+}
+
+// @Summary Get organizations
+// @Description List all organizations. Only admins of the default organization are permitted to list organizations.
+// @Tags admin
+// @Produce json
+// @Success 200 {array} domain.Organization
+// @Failure 400 {object} domain.ErrorResp
+// @Failure 500 {object} domain.ErrorResp
+// @Router /admin/organizations [get]
+func (a *API) HandleGetOrganizations(c echo.Context) error {
+	if !a.isDefaultTenantAdmin(c) {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins of the default organization are permitted to list organizations."})
+	}
+
+	orgs, err := a.Orgs.GetOrganizations()
+	if err != nil {
+		log.Warnf("HandleGetOrganizations: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to get organizations"})
+	}
+	if orgs == nil {
+		orgs = []domain.Organization{}
+	}
+
+	return c.JSON(http.StatusOK, orgs)
+}
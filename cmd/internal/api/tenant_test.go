@@ -0,0 +1,115 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	s := newTestServer(t)
+	a := s.store.addOrg("a")
+	def, _ := s.store.GetOrganizationBySlug("default")
+
+	for _, tc := range []struct {
+		name   string
+		slug   string
+		status int
+		tenant string
+	}{
+		{"header", "a", http.StatusOK, a.ID.String()},
+		{"header is case insensitive", " A ", http.StatusOK, a.ID.String()},
+		{"default organization", "", http.StatusOK, def.ID.String()},
+		{"unknown organization", "missing", http.StatusNotFound, ""},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			rec := s.do(http.MethodGet, "/tenant", tc.slug, "", "")
+			if rec.Code != tc.status {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tc.status, rec.Body)
+			}
+			if tc.tenant != "" && rec.Body.String() != tc.tenant {
+				t.Errorf("tenant = %s, want %s", rec.Body, tc.tenant)
+			}
+		})
+	}
+}
+
+func TestJWTOverridesTenant(t *testing.T) {
+	s := newTestServer(t)
+	a, b := s.store.addOrg("a"), s.store.addOrg("b")
+	user := s.store.addUser(a.ID, "alice", domain.Usr)
+	token := s.token(a.ID, user)
+
+	// without an explicit organization the one of the token is used
+	rec := s.do(http.MethodGet, "/tenant", "", token, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != a.ID.String() {
+		t.Errorf("without header: status = %d, tenant = %s, want %s", rec.Code, rec.Body, a.ID)
+	}
+
+	rec = s.do(http.MethodGet, "/tenant", "a", token, "")
+	if rec.Code != http.StatusOK || rec.Body.String() != a.ID.String() {
+		t.Errorf("same organization: status = %d, tenant = %s, want %s", rec.Code, rec.Body, a.ID)
+	}
+
+	// a token is never accepted in another organization
+	rec = s.do(http.MethodGet, "/tenant", "b", token, "")
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("other organization: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+	rec = s.do(http.MethodPost, "/api/vote", b.Slug, token, `{"oid":"`+user.OID.String()+`","emoji":1}`)
+	if rec.Code != http.StatusUnauthorized {
+		t.Errorf("vote in other organization: status = %d, want %d", rec.Code, http.StatusUnauthorized)
+	}
+}
+
+func TestCrossTenantRead(t *testing.T) {
+	s := newTestServer(t)
+	a, b := s.store.addOrg("a"), s.store.addOrg("b")
+	user := s.store.addUser(a.ID, "alice", domain.Usr)
+	admin := s.store.addUser(b.ID, "bob", domain.Admin)
+
+	rec := s.do(http.MethodGet, "/api/users/"+user.OID.String(), "a", "", "")
+	if rec.Code != http.StatusOK {
+		t.Fatalf("same organization: status = %d, want %d: %s", rec.Code, http.StatusOK, rec.Body)
+	}
+
+	for name, token := range map[string]string{"anonymous": "", "admin": s.token(b.ID, admin)} {
+		rec := s.do(http.MethodGet, "/api/users/"+user.OID.String(), "b", token, "")
+		if rec.Code == http.StatusOK {
+			t.Errorf("%s of other organization: status = %d, want an error", name, rec.Code)
+		}
+		if strings.Contains(rec.Body.String(), user.Nickname) {
+			t.Errorf("%s of other organization: response leaks the profile: %s", name, rec.Body)
+		}
+	}
+}
+
+func TestCrossTenantWrite(t *testing.T) {
+	s := newTestServer(t)
+	a, b := s.store.addOrg("a"), s.store.addOrg("b")
+	user := s.store.addUser(a.ID, "alice", domain.Usr)
+	admin := s.store.addUser(b.ID, "bob", domain.Admin)
+	voter := s.store.addUser(b.ID, "carol", domain.Usr)
+
+	rec := s.do(http.MethodPut, "/api/users/"+user.OID.String(), "", s.token(b.ID, admin),
+		`{"nickname":"mallory","first_name":"Mallory","last_name":"Mallory"}`)
+	if rec.Code == http.StatusOK {
+		t.Errorf("update by admin of other organization: status = %d, want an error", rec.Code)
+	}
+	if got, _ := s.store.user(a.ID, user.OID); got.Nickname != user.Nickname || got.FirstName != user.FirstName {
+		t.Errorf("profile changed by admin of other organization: %+v", got)
+	}
+	if records := s.store.auditRecords(a.ID); len(records) != 0 {
+		t.Errorf("audit records written to other organization: %+v", records)
+	}
+
+	rec = s.do(http.MethodPost, "/api/vote", "", s.token(b.ID, voter), `{"oid":"`+user.OID.String()+`","emoji":1}`)
+	if rec.Code != http.StatusNotFound {
+		t.Errorf("vote for user of other organization: status = %d, want %d", rec.Code, http.StatusNotFound)
+	}
+	if votes := len(s.rating.tenantVotes(a.ID)) + len(s.rating.tenantVotes(b.ID)); votes != 0 {
+		t.Errorf("%d votes recorded for user of other organization", votes)
+	}
+}
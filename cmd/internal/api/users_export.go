@@ -0,0 +1,161 @@
+package api
+
+import (
+	"compress/gzip"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/export"
+)
+
+// @Summary Export users
+// @Description Stream all users matching the list filters in CSV, NDJSON or Parquet along with their ratings.
+// @Description Users are read chunk by chunk in the order of creation, so the export isn't loaded into memory.
+// @Tags admin
+// @Accept json
+// @Produce text/csv
+// @Produce application/x-ndjson
+// @Produce application/vnd.apache.parquet
+// @Produce application/gzip
+// @Param format query string false "Export format, csv by default" Enums(csv, ndjson, parquet)
+// @Param gzip query bool false "Compress the export with gzip"
+// @Param attr.name query string false "Filter by public custom attribute, e.g. attr.location=Berlin"
+// @Param state query int false "Filter by state"
+// @Param role query int false "Filter by role"
+// @Param created_from query string false "Created at or after, RFC3339"
+// @Param created_to query string false "Created before, RFC3339"
+// @Param updated_from query string false "Updated at or after, RFC3339"
+// @Param updated_to query string false "Updated before, RFC3339"
+// @Param order query string false "Order by creation time" Enums(asc, desc)
+// @Success 200 {file} file "Users export"
+// @Failure 400 {object} domain.ErrorResp "Wrong filter or format"
+// @Failure 500 {object} domain.ErrorResp "Failed to export users"
+// @Router /admin/users/export [get]
+func (a *API) HandleExportUsers(c echo.Context) error {
+	tenant := tenantOf(c)
+	if c.Get("role").(domain.Role) != domain.Admin {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Only admins are permitted to export users."})
+	}
+
+	format := c.QueryParam("format")
+	if format == "" {
+		format = export.FormatCSV
+	}
+
+	var compress bool
+	if param := c.QueryParam("gzip"); param != "" {
+		var err error
+		compress, err = strconv.ParseBool(param)
+		if err != nil {
+			return c.JSON(http.StatusBadRequest, map[string]string{"error": fmt.Sprintf("wrong gzip %q, should be true or false", param)})
+		}
+	}
+
+	filter, err := usersFilter(c)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+	if filter.SortBy != "" {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": "Users export is ordered by created_at only"})
+	}
+
+	res := c.Response()
+	var out io.Writer = res
+	var gz *gzip.Writer
+	if compress {
+		gz = gzip.NewWriter(res)
+		out = gz
+	}
+
+	writer, err := export.NewUsersWriter(out, format)
+	if err != nil {
+		return c.JSON(http.StatusBadRequest, map[string]string{"error": err.Error()})
+	}
+
+	// The first chunk is read before anything is sent, so that the client gets a proper error if the export can't start.
+	users, err := a.getExportChunk(tenant, nil, filter)
+	if err != nil {
+		log.Warnf("HandleExportUsers: %s", err)
+		return c.JSON(http.StatusInternalServerError, map[string]string{"error": "Failed to export users"})
+	}
+
+	filename := "users." + format
+	contentType := export.ContentType(format)
+	if compress {
+		filename += ".gz"
+		contentType = "application/gzip"
+	}
+	res.Header().Set(echo.HeaderContentType, contentType)
+	res.Header().Set(echo.HeaderContentDisposition, fmt.Sprintf("attachment; filename=%q", filename))
+	res.WriteHeader(http.StatusOK)
+
+	var total int
+	for len(users) > 0 {
+		if err := writer.Write(users); err != nil {
+			abortExport(err)
+		}
+		res.Flush()
+		total += len(users)
+
+		if len(users) < a.Config.Export.UsersChunkSize {
+			break
+		}
+		last := users[len(users)-1]
+		users, err = a.getExportChunk(tenant, &domain.Cursor{CreatedAt: last.CreatedAt, OID: last.OID}, filter)
+		if err != nil {
+			abortExport(err)
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		abortExport(err)
+	}
+	if gz != nil {
+		if err := gz.Close(); err != nil {
+			abortExport(err)
+		}
+	}
+
+	log.Infof("Successfully exported %d users in %s", total, format)
+	return nil
+}
+
+// getExportChunk returns the chunk of users that follows cursor, with ratings and attributes visible to admins.
+func (a *API) getExportChunk(tenant uuid.UUID, cursor *domain.Cursor, filter domain.UsersFilter) ([]domain.UserProfileDTO, error) {
+	users, err := a.DB.GetUsersByCursor(tenant, a.Config.Export.UsersChunkSize, cursor, !filter.Desc, filter)
+	if err != nil {
+		return nil, err
+	}
+	if len(users) == 0 {
+		return users, nil
+	}
+
+	oids := make([]uuid.UUID, 0, len(users))
+	for _, user := range users {
+		oids = append(oids, user.OID)
+	}
+	ratings, err := a.Rating.GetRatingForList(tenant, oids)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, user := range users {
+		users[i].Rating = ratings[user.OID].Total
+		users[i].Attributes = VisibleAttributes(user, uuid.Nil, domain.Admin)
+		users[i].AttributesVisibility = nil
+	}
+	return users, nil
+}
+
+// abortExport is called when the export fails after the response has started. Status can't be changed at this point,
+// so the connection is dropped to let the client know that the file is incomplete.
+func abortExport(err error) {
+	log.Warnf("HandleExportUsers: export aborted: %s", err)
+	panic(http.ErrAbortHandler)
+}
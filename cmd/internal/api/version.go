@@ -0,0 +1,22 @@
+package api
+
+import (
+	"strconv"
+
+	"github.com/labstack/echo/v4"
+)
+
+// apiVersionHeader picks the version of responses whose format has changed, e.g. "X-API-Version: 1".
+const apiVersionHeader = "X-API-Version"
+
+// currentAPIVersion is the version of responses of requests that don't pick one.
+const currentAPIVersion = 2
+
+// apiVersion returns the version picked by the request, or the current one if it's missing or unknown.
+func apiVersion(c echo.Context) int {
+	version, err := strconv.Atoi(c.Request().Header.Get(apiVersionHeader))
+	if err != nil || version < 1 || version > currentAPIVersion {
+		return currentAPIVersion
+	}
+	return version
+}
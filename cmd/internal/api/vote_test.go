@@ -0,0 +1,26 @@
+package api
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+func TestVoteCopiesRating(t *testing.T) {
+	s := newTestServer(t)
+	org, _ := s.store.GetOrganizationBySlug("default")
+	user := s.store.addUser(org.ID, "alice", domain.Usr)
+
+	for _, nick := range []string{"bob", "carol"} {
+		voter := s.store.addUser(org.ID, nick, domain.Usr)
+		rec := s.do(http.MethodPost, "/api/vote", "", s.token(org.ID, voter), `{"oid":"`+user.OID.String()+`","emoji":1}`)
+		if rec.Code != http.StatusOK {
+			t.Fatalf("vote of %s: status = %d: %s", nick, rec.Code, rec.Body)
+		}
+	}
+
+	if got, _ := s.store.user(org.ID, user.OID); got.Rating != 2 {
+		t.Errorf("rating copied to the profile = %d, want 2", got.Rating)
+	}
+}
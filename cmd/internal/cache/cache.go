@@ -1,64 +1,139 @@
-package cache
-
-import (
-	"context"
-	"encoding/json"
-	"fmt"
-	"time"
-
-	"github.com/redis/go-redis/v9"
-	"github.com/sosshik/rest-user-management/cmd/internal/domain"
-)
-
-type Redis struct {
-	Client         *redis.Client
-	expTimeSeconds time.Duration
-}
-
-func NewRedis(addr string, db int, expTime int) *Redis {
-	client := redis.NewClient(&redis.Options{
-		Addr:     addr,
-		Password: "",
-		DB:       db,
-	})
-	return &Redis{Client: client, expTimeSeconds: time.Duration(expTime) * time.Second}
-}
-
-func (r *Redis) Set(key string, value interface{}) error {
-	json, err := json.Marshal(value)
-	if err != nil {
-		return fmt.Errorf("Set: unable to marshall JSON: %w", err)
-	}
-	r.Client.Set(context.Background(), key, json, r.expTimeSeconds)
-	return nil
-}
-
-func (r *Redis) GetUser(key string) (domain.UserProfileDTO, error) {
-	res, err := r.Client.Get(context.Background(), key).Result()
-	if err != nil || res == "" {
-		return domain.UserProfileDTO{}, err
-	}
-	var user domain.UserProfileDTO
-	err = json.Unmarshal([]byte(res), &user)
-	if err != nil {
-		return domain.UserProfileDTO{}, fmt.Errorf("getUser: unable to decode JSON: %w", err)
-	}
-	return user, nil
-}
-
-func (r *Redis) GetUsersList(key string) (domain.Pagination[domain.UserProfileDTO], error) {
-	res, err := r.Client.Get(context.Background(), key).Result()
-	if err != nil || res == "" {
-		return domain.Pagination[domain.UserProfileDTO]{}, err
-	}
-
-	var usersList domain.Pagination[domain.UserProfileDTO]
-	err = json.Unmarshal([]byte(res), &usersList)
-	if err != nil {
-		return domain.Pagination[domain.UserProfileDTO]{}, fmt.Errorf("getUser: unable to decode JSON: %w", err)
-	}
-	return usersList, nil
-}
-func (r *Redis) MakeKey(pageSize int, offset int) string {
-	return fmt.Sprintf("pageSize:%d,offset:%d", pageSize, offset)
-}
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+)
+
+type Redis struct {
+	Client         *redis.Client
+	expTimeSeconds time.Duration
+	// keyring encrypts cached values as they hold names, they are cached in plaintext if it's nil.
+	keyring *pii.Keyring
+}
+
+func NewRedis(addr string, db int, expTime int, keyring *pii.Keyring) *Redis {
+	client := redis.NewClient(&redis.Options{
+		Addr:     addr,
+		Password: "",
+		DB:       db,
+	})
+	return &Redis{Client: client, expTimeSeconds: time.Duration(expTime) * time.Second, keyring: keyring}
+}
+
+// listsVersionKey holds the version of cached users list pages of a tenant, it's a part of their keys.
+const listsVersionKey = "lists:version"
+
+// tenantKey keeps keys of different organizations apart, so that equal pages or oids never share an entry.
+func tenantKey(tenant uuid.UUID, key string) string {
+	return fmt.Sprintf("tenant:%s:%s", tenant, key)
+}
+
+func (r *Redis) Set(tenant uuid.UUID, key string, value interface{}) error {
+	return r.SetWithTTL(tenant, key, value, r.expTimeSeconds)
+}
+
+// SetWithTTL saves value for ttl instead of the default expiration time.
+func (r *Redis) SetWithTTL(tenant uuid.UUID, key string, value interface{}, ttl time.Duration) error {
+	json, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("Set: unable to marshall JSON: %w", err)
+	}
+	json, err = r.keyring.Encrypt(json)
+	if err != nil {
+		return fmt.Errorf("Set: %w", err)
+	}
+	r.Client.Set(context.Background(), tenantKey(tenant, key), json, ttl)
+	return nil
+}
+
+func (r *Redis) GetUser(tenant uuid.UUID, key string) (domain.UserProfileDTO, error) {
+	res, err := r.Client.Get(context.Background(), tenantKey(tenant, key)).Result()
+	if err != nil || res == "" {
+		return domain.UserProfileDTO{}, err
+	}
+	decrypted, err := r.keyring.Decrypt([]byte(res))
+	if err != nil {
+		return domain.UserProfileDTO{}, fmt.Errorf("getUser: %w", err)
+	}
+	var user domain.UserProfileDTO
+	err = json.Unmarshal(decrypted, &user)
+	if err != nil {
+		return domain.UserProfileDTO{}, fmt.Errorf("getUser: unable to decode JSON: %w", err)
+	}
+	return user, nil
+}
+
+func (r *Redis) GetUsersList(tenant uuid.UUID, key string) (domain.Pagination[domain.UserProfileDTO], error) {
+	res, err := r.Client.Get(context.Background(), tenantKey(tenant, key)).Result()
+	if err != nil || res == "" {
+		return domain.Pagination[domain.UserProfileDTO]{}, err
+	}
+	decrypted, err := r.keyring.Decrypt([]byte(res))
+	if err != nil {
+		return domain.Pagination[domain.UserProfileDTO]{}, fmt.Errorf("getUsersList: %w", err)
+	}
+
+	var usersList domain.Pagination[domain.UserProfileDTO]
+	err = json.Unmarshal(decrypted, &usersList)
+	if err != nil {
+		return domain.Pagination[domain.UserProfileDTO]{}, fmt.Errorf("getUser: unable to decode JSON: %w", err)
+	}
+	return usersList, nil
+}
+func (r *Redis) GetLeaderboard(tenant uuid.UUID, key string) (domain.Leaderboard, error) {
+	res, err := r.Client.Get(context.Background(), tenantKey(tenant, key)).Result()
+	if err != nil || res == "" {
+		return domain.Leaderboard{}, err
+	}
+	decrypted, err := r.keyring.Decrypt([]byte(res))
+	if err != nil {
+		return domain.Leaderboard{}, fmt.Errorf("getLeaderboard: %w", err)
+	}
+
+	var leaderboard domain.Leaderboard
+	err = json.Unmarshal(decrypted, &leaderboard)
+	if err != nil {
+		return domain.Leaderboard{}, fmt.Errorf("getLeaderboard: unable to decode JSON: %w", err)
+	}
+	return leaderboard, nil
+}
+
+// MakeKey returns the key of a users list page. Keys include the current lists version of the tenant,
+// so that InvalidateLists makes every cached page unreachable at once.
+func (r *Redis) MakeKey(tenant uuid.UUID, page domain.PageQuery, filter domain.UsersFilter) (string, error) {
+	version, err := r.Client.Get(context.Background(), tenantKey(tenant, listsVersionKey)).Int64()
+	if err != nil && err != redis.Nil {
+		return "", fmt.Errorf("MakeKey: unable to get lists version: %w", err)
+	}
+	// json.Marshal keeps struct field order and sorts map keys, so equal filters always produce equal keys.
+	// Page and filter hold only values parsed from query params, so they can always be marshalled.
+	encodedPage, _ := json.Marshal(page)
+	encodedFilter, _ := json.Marshal(filter)
+	return fmt.Sprintf("lists:%d:page:%s,filter:%s", version, encodedPage, encodedFilter), nil
+}
+
+// InvalidateLists drops all cached users list pages of the tenant, e.g. after a profile stops being listed.
+// Pages cached under previous versions are left to expire.
+func (r *Redis) InvalidateLists(tenant uuid.UUID) error {
+	err := r.Client.Incr(context.Background(), tenantKey(tenant, listsVersionKey)).Err()
+	if err != nil {
+		return fmt.Errorf("InvalidateLists: unable to bump lists version: %w", err)
+	}
+	return nil
+}
+
+func (r *Redis) Delete(tenant uuid.UUID, key string) error {
+	err := r.Client.Del(context.Background(), tenantKey(tenant, key)).Err()
+	if err != nil {
+		return fmt.Errorf("Delete: unable to delete key: %w", err)
+	}
+	return nil
+}
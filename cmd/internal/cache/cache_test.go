@@ -0,0 +1,236 @@
+package cache
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// fakeRedis serves the few commands the cache uses over RESP2, keys are kept as they are sent.
+type fakeRedis struct {
+	mu   sync.Mutex
+	data map[string]string
+}
+
+// newTestRedis starts fakeRedis on a loopback port and returns the cache connected to it.
+func newTestRedis(t *testing.T) (*Redis, *fakeRedis) {
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Skipf("unable to listen on loopback: %s", err)
+	}
+	server := &fakeRedis{data: make(map[string]string)}
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go server.serve(conn)
+		}
+	}()
+
+	r := NewRedis(ln.Addr().String(), 0, 60, nil)
+	t.Cleanup(func() {
+		r.Client.Close()
+		ln.Close()
+	})
+	return r, server
+}
+
+func (s *fakeRedis) serve(conn net.Conn) {
+	defer conn.Close()
+	rd, w := bufio.NewReader(conn), bufio.NewWriter(conn)
+	for {
+		args, err := readCommand(rd)
+		if err != nil {
+			return
+		}
+		s.reply(w, args)
+		if w.Flush() != nil {
+			return
+		}
+	}
+}
+
+func (s *fakeRedis) reply(w *bufio.Writer, args []string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	switch strings.ToUpper(args[0]) {
+	case "GET":
+		value, ok := s.data[args[1]]
+		if !ok {
+			w.WriteString("$-1\r\n")
+			return
+		}
+		fmt.Fprintf(w, "$%d\r\n%s\r\n", len(value), value)
+	case "SET":
+		// expiration options are ignored, entries never expire during a test
+		s.data[args[1]] = args[2]
+		w.WriteString("+OK\r\n")
+	case "DEL":
+		deleted := 0
+		for _, key := range args[1:] {
+			if _, ok := s.data[key]; ok {
+				delete(s.data, key)
+				deleted++
+			}
+		}
+		fmt.Fprintf(w, ":%d\r\n", deleted)
+	case "INCR":
+		value, _ := strconv.ParseInt(s.data[args[1]], 10, 64)
+		value++
+		s.data[args[1]] = strconv.FormatInt(value, 10)
+		fmt.Fprintf(w, ":%d\r\n", value)
+	default:
+		// HELLO and CLIENT SETINFO are refused, so the client falls back to RESP2
+		fmt.Fprintf(w, "-ERR unknown command '%s'\r\n", args[0])
+	}
+}
+
+func (s *fakeRedis) keys() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	keys := make([]string, 0, len(s.data))
+	for key := range s.data {
+		keys = append(keys, key)
+	}
+	return keys
+}
+
+// readCommand reads a command sent as an array of bulk strings.
+func readCommand(rd *bufio.Reader) ([]string, error) {
+	line, err := readLine(rd)
+	if err != nil {
+		return nil, err
+	}
+	if !strings.HasPrefix(line, "*") {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	n, err := strconv.Atoi(line[1:])
+	if err != nil || n < 1 {
+		return nil, fmt.Errorf("unexpected command %q", line)
+	}
+	args := make([]string, n)
+	for i := range args {
+		line, err := readLine(rd)
+		if err != nil {
+			return nil, err
+		}
+		size, err := strconv.Atoi(strings.TrimPrefix(line, "$"))
+		if err != nil {
+			return nil, fmt.Errorf("unexpected argument %q", line)
+		}
+		buf := make([]byte, size+2)
+		if _, err := io.ReadFull(rd, buf); err != nil {
+			return nil, err
+		}
+		args[i] = string(buf[:size])
+	}
+	return args, nil
+}
+
+func readLine(rd *bufio.Reader) (string, error) {
+	line, err := rd.ReadString('\n')
+	return strings.TrimRight(line, "\r\n"), err
+}
+
+func TestTenantKey(t *testing.T) {
+	a, b := uuid.New(), uuid.New()
+	if tenantKey(a, "key") == tenantKey(b, "key") {
+		t.Errorf("equal keys of different tenants share an entry: %s", tenantKey(a, "key"))
+	}
+	if !strings.Contains(tenantKey(a, "key"), a.String()) {
+		t.Errorf("key %s doesn't include the tenant %s", tenantKey(a, "key"), a)
+	}
+}
+
+func TestCacheTenantIsolation(t *testing.T) {
+	r, server := newTestRedis(t)
+	a, b := uuid.New(), uuid.New()
+	user := domain.UserProfileDTO{OID: uuid.New(), Nickname: "alice"}
+	key := user.OID.String()
+
+	if err := r.Set(a, key, user); err != nil {
+		t.Fatal(err)
+	}
+	for _, stored := range server.keys() {
+		if !strings.HasPrefix(stored, "tenant:"+a.String()+":") {
+			t.Errorf("key %q is stored outside of the tenant", stored)
+		}
+	}
+
+	// reads of another tenant miss
+	if got, err := r.GetUser(b, key); err != redis.Nil {
+		t.Errorf("GetUser of other tenant = %+v, %v, want redis.Nil", got, err)
+	}
+	if got, err := r.GetUser(a, key); err != nil || got.Nickname != user.Nickname {
+		t.Fatalf("GetUser = %+v, %v, want %+v", got, err, user)
+	}
+
+	// writes of another tenant don't touch the entry
+	if err := r.Set(b, key, domain.UserProfileDTO{OID: user.OID, Nickname: "mallory"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Delete(b, key); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := r.GetUser(a, key); err != nil || got.Nickname != user.Nickname {
+		t.Errorf("GetUser after writes of other tenant = %+v, %v, want %+v", got, err, user)
+	}
+
+	if err := r.Delete(a, key); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := r.GetUser(a, key); err != redis.Nil {
+		t.Errorf("GetUser after Delete: err = %v, want redis.Nil", err)
+	}
+}
+
+func TestInvalidateListsTenantIsolation(t *testing.T) {
+	r, _ := newTestRedis(t)
+	a, b := uuid.New(), uuid.New()
+	page, filter := domain.PageQuery{}, domain.UsersFilter{}
+
+	keyA, err := r.MakeKey(a, page, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyB, err := r.MakeKey(b, page, filter)
+	if err != nil {
+		t.Fatal(err)
+	}
+	list := domain.Pagination[domain.UserProfileDTO]{Users: []domain.UserProfileDTO{{Nickname: "alice"}}}
+	if err := r.Set(a, keyA, list); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := r.GetUsersList(b, keyB); err != redis.Nil {
+		t.Errorf("GetUsersList of other tenant: err = %v, want redis.Nil", err)
+	}
+
+	if err := r.InvalidateLists(b); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := r.MakeKey(a, page, filter); err != nil || key != keyA {
+		t.Errorf("MakeKey after InvalidateLists of other tenant = %q, %v, want %q", key, err, keyA)
+	}
+	if got, err := r.GetUsersList(a, keyA); err != nil || len(got.Users) != 1 {
+		t.Errorf("GetUsersList after InvalidateLists of other tenant = %+v, %v", got, err)
+	}
+
+	if err := r.InvalidateLists(a); err != nil {
+		t.Fatal(err)
+	}
+	if key, err := r.MakeKey(a, page, filter); err != nil || key == keyA {
+		t.Errorf("MakeKey after InvalidateLists = %q, %v, want a new key", key, err)
+	}
+}
@@ -0,0 +1,133 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// WriteAudit appends records to the audit log in a single transaction.
+func (d *Database) WriteAudit(tenant uuid.UUID, records []domain.AuditRecord) error {
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("WriteAudit: unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, record := range records {
+		if record.Before, err = d.encryptFields(record.Before); err != nil {
+			return fmt.Errorf("WriteAudit: %w", err)
+		}
+		if record.After, err = d.encryptFields(record.After); err != nil {
+			return fmt.Errorf("WriteAudit: %w", err)
+		}
+		before, err := encodeAuditFields(record.Before)
+		if err != nil {
+			return fmt.Errorf("WriteAudit: %w", err)
+		}
+		after, err := encodeAuditFields(record.After)
+		if err != nil {
+			return fmt.Errorf("WriteAudit: %w", err)
+		}
+
+		_, err = tx.Exec(`
+			CALL public.write_audit($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		`, tenant, record.ActorOID, record.TargetOID, record.Action, before, after, record.IP, record.RequestID, record.CreatedAt)
+		if err != nil {
+			return fmt.Errorf("WriteAudit: unable to execute query to DB: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("WriteAudit: unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+// GetAudit returns audit records matching filter, most recent first.
+func (d *Database) GetAudit(tenant uuid.UUID, filter domain.AuditFilter, pageSize int, offset int) ([]domain.AuditRecord, error) {
+	args := append([]interface{}{tenant}, encodeAuditFilter(filter)...)
+	args = append(args, pageSize, offset)
+
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_audit($1,$2,$3,$4,$5,$6,$7,$8);
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetAudit: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var records []domain.AuditRecord
+	for rows.Next() {
+		var record domain.AuditRecord
+		var before, after []byte
+		var ip, requestID sql.NullString
+		err := rows.Scan(&record.ID, &record.ActorOID, &record.TargetOID, &record.Action, &before, &after, &ip, &requestID, &record.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("GetAudit: unable to scan row from DB: %w", err)
+		}
+		if before != nil {
+			if err := json.Unmarshal(before, &record.Before); err != nil {
+				return nil, fmt.Errorf("GetAudit: unable to decode fields: %w", err)
+			}
+		}
+		if after != nil {
+			if err := json.Unmarshal(after, &record.After); err != nil {
+				return nil, fmt.Errorf("GetAudit: unable to decode fields: %w", err)
+			}
+		}
+		if err := d.decryptFields(record.Before); err != nil {
+			return nil, fmt.Errorf("GetAudit: %w", err)
+		}
+		if err := d.decryptFields(record.After); err != nil {
+			return nil, fmt.Errorf("GetAudit: %w", err)
+		}
+		record.IP = ip.String
+		record.RequestID = requestID.String
+		records = append(records, record)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetAudit: unable to read rows from DB: %w", err)
+	}
+	return records, nil
+}
+
+func (d *Database) GetAuditCount(tenant uuid.UUID, filter domain.AuditFilter) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`
+		SELECT public.get_audit_count($1,$2,$3,$4,$5,$6);
+	`, append([]interface{}{tenant}, encodeAuditFilter(filter)...)...).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetAuditCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+func encodeAuditFilter(filter domain.AuditFilter) []interface{} {
+	var actor, target, action interface{}
+	if filter.Actor != nil {
+		actor = *filter.Actor
+	}
+	if filter.Target != nil {
+		target = *filter.Target
+	}
+	if filter.Action != "" {
+		action = string(filter.Action)
+	}
+	return []interface{}{actor, target, action, nullTime(filter.From), nullTime(filter.To)}
+}
+
+// encodeAuditFields encodes fields as JSONB, no fields are stored as NULL.
+func encodeAuditFields(fields map[string]interface{}) (interface{}, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+	encoded, err := json.Marshal(fields)
+	if err != nil {
+		return nil, fmt.Errorf("unable to encode fields: %w", err)
+	}
+	return string(encoded), nil
+}
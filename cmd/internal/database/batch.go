@@ -0,0 +1,83 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// GetBatchTargets returns oid, state and role of users listed in oids or, if oids are empty, of users matching filter.
+// Deleted users are never returned.
+func (d *Database) GetBatchTargets(tenant uuid.UUID, oids []uuid.UUID, filter *domain.UsersFilter, limit int) ([]domain.UserProfileDTO, error) {
+	if filter == nil {
+		filter = &domain.UsersFilter{}
+	}
+	filterArgs, err := encodeFilter(*filter)
+	if err != nil {
+		return nil, err
+	}
+
+	args := append([]interface{}{tenant, uuidArray(oids)}, filterArgs...)
+	args = append(args, limit)
+
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_batch_targets($1,$2::uuid[],$3,$4,$5,$6,$7,$8,$9,$10,$11);
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetBatchTargets: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.UserProfileDTO
+	for rows.Next() {
+		var user domain.UserProfileDTO
+		if err := rows.Scan(&user.OID, &user.State, &user.Role); err != nil {
+			return nil, fmt.Errorf("GetBatchTargets: unable to scan row from DB: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetBatchTargets: unable to read rows from DB: %w", err)
+	}
+	return users, nil
+}
+
+// ApplyBatchAction applies action to users in a single transaction and writes an audit record for each of them.
+// It returns oids of users that were actually changed, users that already are in the requested state are left as is.
+func (d *Database) ApplyBatchAction(tenant uuid.UUID, action domain.BatchAction, role domain.Role, oids []uuid.UUID, source domain.AuditSource, releaseNickname bool) ([]uuid.UUID, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.apply_batch_action($1,$2::uuid[],$3,$4,$5,$6,$7,$8,$9);
+	`, tenant, uuidArray(oids), action, role, source.Actor, time.Now().UTC(), releaseNickname, source.IP, source.RequestID)
+	if err != nil {
+		return nil, fmt.Errorf("ApplyBatchAction: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var affected []uuid.UUID
+	for rows.Next() {
+		var oid uuid.UUID
+		if err := rows.Scan(&oid); err != nil {
+			return nil, fmt.Errorf("ApplyBatchAction: unable to scan row from DB: %w", err)
+		}
+		affected = append(affected, oid)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("ApplyBatchAction: unable to read rows from DB: %w", err)
+	}
+	return affected, nil
+}
+
+// uuidArray converts oids to a value that can be passed as uuid[], empty oids are passed as NULL.
+func uuidArray(oids []uuid.UUID) interface{} {
+	if len(oids) == 0 {
+		return nil
+	}
+	strs := make([]string, 0, len(oids))
+	for _, oid := range oids {
+		strs = append(strs, oid.String())
+	}
+	return pq.Array(strs)
+}
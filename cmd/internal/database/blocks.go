@@ -0,0 +1,87 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// Block makes blocker block blocked and removes follows between them, blocking twice is a no-op.
+// It returns false if blocked doesn't exist.
+func (d *Database) Block(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) (bool, error) {
+	var found bool
+	err := d.DB.QueryRow(`
+		SELECT public.block_user($1,$2,$3,$4);
+	`, tenant, blocker, blocked, time.Now().UTC()).Scan(&found)
+	if err != nil {
+		return false, fmt.Errorf("Block: unable to execute query to DB: %w", err)
+	}
+	return found, nil
+}
+
+func (d *Database) Unblock(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) error {
+	_, err := d.DB.Exec(`
+	CALL public.unblock_user($1,$2,$3)
+	`, tenant, blocker, blocked)
+	if err != nil {
+		return fmt.Errorf("Unblock: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) IsBlocked(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) (bool, error) {
+	var isBlocked bool
+	err := d.DB.QueryRow(`SELECT public.is_blocked($1,$2,$3);`, tenant, blocker, blocked).Scan(&isBlocked)
+	if err != nil {
+		return false, fmt.Errorf("IsBlocked: unable to execute query to DB: %w", err)
+	}
+	return isBlocked, nil
+}
+
+// GetBlockedUsers returns users blocked by oid that aren't deleted, most recently blocked first.
+func (d *Database) GetBlockedUsers(tenant uuid.UUID, oid uuid.UUID, pageSize int, offset int) ([]domain.UserProfileDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_blocked_users($1,$2,$3,$4);
+	`, tenant, oid, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockedUsers: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanUsers(rows)
+}
+
+func (d *Database) GetBlockedUsersCount(tenant uuid.UUID, oid uuid.UUID) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT public.get_blocked_users_count($1,$2);`, tenant, oid).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetBlockedUsersCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+// GetBlockedOIDs returns oids of all users blocked by oid, including deleted ones.
+func (d *Database) GetBlockedOIDs(tenant uuid.UUID, oid uuid.UUID) ([]uuid.UUID, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_blocked_oids($1,$2);
+	`, tenant, oid)
+	if err != nil {
+		return nil, fmt.Errorf("GetBlockedOIDs: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var oids []uuid.UUID
+	for rows.Next() {
+		var blocked uuid.UUID
+		if err := rows.Scan(&blocked); err != nil {
+			return nil, fmt.Errorf("GetBlockedOIDs: scan the row: %w", err)
+		}
+		oids = append(oids, blocked)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetBlockedOIDs: %w", err)
+	}
+	return oids, nil
+}
@@ -1,225 +1,646 @@
-package database
-
-import (
-	"database/sql"
-	"fmt"
-	"sync"
-	"time"
-
-	"github.com/google/uuid"
-	log "github.com/sirupsen/logrus"
-	"github.com/sosshik/rest-user-management/cmd/internal/domain"
-	"github.com/sosshik/rest-user-management/pkg/config"
-)
-
-type DBConfig struct {
-	DbUrl       string `env:"DATABASE_URL"`
-	ReconnTime  int    `env:"RECONN_TIME" envDefault:"5"`
-	ConnCheck   bool   `env:"CONN_CHECK" envDefault:"true"`
-	ReconnTries int    `env:"RECONN_TRIES" envDefault:"5"`
-}
-
-type Database struct {
-	config *DBConfig
-	DB     *sql.DB
-}
-
-var once sync.Once
-
-var dbinstance *Database
-
-func NewDatabase(cfg *config.Config) (*Database, error) {
-
-	if dbinstance == nil {
-		once.Do(func() {
-			db, err := sql.Open("postgres", cfg.DbUrl)
-			if err != nil {
-				log.Warnf("unable to create db instance: %s", err)
-			}
-
-			dbinstance = &Database{&DBConfig{cfg.DbUrl, cfg.ReconnTime, cfg.ConnCheck, cfg.ReconnTries}, db}
-
-			if cfg.ConnCheck {
-				go dbinstance.connectionCheck(cfg.DbUrl)
-			}
-		})
-
-	}
-
-	return dbinstance, nil
-}
-
-func (d *Database) connectionCheck(conn string) {
-	log.Info("Connection check started")
-	var i int
-	for {
-		time.Sleep(time.Duration(d.config.ReconnTime) * time.Second)
-		if err := d.DB.Ping(); err != nil {
-			log.Warnf("Lost connection to Database. Attempting to reconnect.")
-			if err := d.DB.Close(); err != nil {
-				log.Warnf("Error while disconecting: %s", err)
-				continue
-			}
-			if i <= d.config.ReconnTries {
-				d.DB, err = sql.Open("postgres", conn)
-				if err != nil {
-					log.Warnf("Failed to reconnect: %s", err)
-					i++
-				} else {
-					log.Infof("Reconnected to PostgreSQL!")
-					i = 0
-				}
-			} else {
-				break
-			}
-
-		}
-	}
-}
-
-func (d *Database) GetPassword(username string) (string, error) {
-	var passwordHash string
-	err := d.DB.QueryRow(`
-		CALL  public.get_user_password($1,$2)
-	`, username, &passwordHash).Scan(&passwordHash)
-	if err != nil {
-		return "", fmt.Errorf("auth: unable to execute query to DB: %w", err)
-	}
-	return passwordHash, nil
-}
-
-func (d *Database) CreateUserProfile(user domain.UserProfileDTO) error {
-
-	_, err := d.DB.Exec(`
-		CALL public.create_profile($1, $2, $3, $4, $5, $6, $7, $8, $9)
-	`, user.OID, user.Nickname, user.FirstName, user.LastName, user.Password, user.CreatedAt, user.UpdatedAt, user.State, user.Role)
-	if err != nil {
-		return fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return nil
-}
-
-func (d *Database) UpdateUserProfile(user domain.UserProfileDTO, userID uuid.UUID) error {
-
-	_, err := d.DB.Exec(`
-		CALL public.update_profile($1,$2,$3,$4,$5)
-	`, user.Nickname, user.FirstName, user.LastName, user.UpdatedAt, userID)
-	if err != nil {
-		return fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return nil
-}
-
-func (d *Database) UpdatePassword(newPass string, userID uuid.UUID) error {
-
-	_, err := d.DB.Exec(`
-		CALL public.update_password($1,$2,$3)
-	`, newPass, time.Now().UTC(), userID)
-	if err != nil {
-		return fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return nil
-
-}
-
-func (d *Database) GetUserById(userID uuid.UUID) (domain.UserProfileDTO, error) {
-	var user UserProfile
-	err := d.DB.QueryRow(`
-		CALL public.get_user($1, $2, $3, $4, $5, $6, $7,$8);
-	`, userID, &user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role).Scan(&user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role)
-	if err != nil {
-		return domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return domain.UserProfileDTO{
-		OID:       userID,
-		Nickname:  user.Nickname,
-		FirstName: user.FirstName,
-		LastName:  user.LastName,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-		State:     user.State,
-		Role:      user.Role,
-		Rating:    user.Rating,
-	}, nil
-}
-
-func (d *Database) GetUsersList(pageSize int, offset int) ([]domain.UserProfileDTO, error) {
-	rows, err := d.DB.Query(`
-		SELECT * FROM public.get_all_users($1,$2);
-	`, pageSize, offset)
-	if err != nil {
-		return []domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	defer rows.Close()
-
-	var users []domain.UserProfileDTO
-
-	for rows.Next() {
-		var user UserProfile
-		err := rows.Scan(&user.OID, &user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role)
-		if err != nil {
-			return []domain.UserProfileDTO{}, fmt.Errorf("unable to scan row from DB: %w", err)
-		}
-
-		users = append(users, domain.UserProfileDTO{
-			OID:       user.OID,
-			Nickname:  user.Nickname,
-			FirstName: user.FirstName,
-			LastName:  user.LastName,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-			State:     user.State,
-			Role:      user.Role,
-			Rating:    user.Rating,
-		})
-	}
-	return users, nil
-}
-
-func (d *Database) GetUsersCount() (int, error) {
-	var totalUsers int
-	err := d.DB.QueryRow(`CALL public.get_count($1);`, &totalUsers).Scan(&totalUsers)
-	if err != nil {
-		return 0, fmt.Errorf("GetUsersCount: unable to execute query to DB: %w", err)
-	}
-	return totalUsers, nil
-}
-
-func (d *Database) GetUserForToken(nickname string) (domain.UserProfileDTO, error) {
-
-	var user UserProfile
-
-	err := d.DB.QueryRow(`
-	CALL public.get_user_for_token($1,$2,$3,$4,$5)
-	`, nickname, &user.OID, &user.Nickname, &user.Role, &user.State).Scan(&user.OID, &user.Nickname, &user.Role, &user.State)
-	if err != nil {
-		return domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return domain.UserProfileDTO{
-		OID:      user.OID,
-		Nickname: user.Nickname,
-		Role:     user.Role,
-		State:    user.State,
-	}, nil
-}
-
-func (d *Database) GetUserState(oid uuid.UUID) (int, error) {
-	var state int
-	err := d.DB.QueryRow(`
-	CALL public.get_user_state($1,$2)
-	`, oid, &state).Scan(&state)
-	if err != nil {
-		return 0, fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return state, nil
-}
-
-func (d *Database) DeleteUser(oid uuid.UUID) error {
-	_, err := d.DB.Exec(`
-	CALL public.delete_user($1)
-	`, oid)
-	if err != nil {
-		return fmt.Errorf("unable to execute query to DB: %w", err)
-	}
-	return nil
-}
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+type DBConfig struct {
+	DbUrl       string `env:"DATABASE_URL"`
+	ReconnTime  int    `env:"RECONN_TIME" envDefault:"5"`
+	ConnCheck   bool   `env:"CONN_CHECK" envDefault:"true"`
+	ReconnTries int    `env:"RECONN_TRIES" envDefault:"5"`
+}
+
+type Database struct {
+	config *DBConfig
+	DB     *sql.DB
+	// PII encrypts first and last names, they are stored in plaintext if it's nil.
+	PII *pii.Keyring
+}
+
+var once sync.Once
+
+var dbinstance *Database
+
+func NewDatabase(cfg *config.Config) (*Database, error) {
+
+	if dbinstance == nil {
+		once.Do(func() {
+			db, err := sql.Open("postgres", cfg.DbUrl)
+			if err != nil {
+				log.Warnf("unable to create db instance: %s", err)
+			}
+
+			dbinstance = &Database{config: &DBConfig{cfg.DbUrl, cfg.ReconnTime, cfg.ConnCheck, cfg.ReconnTries}, DB: db}
+
+			if cfg.ConnCheck {
+				go dbinstance.connectionCheck(cfg.DbUrl)
+			}
+		})
+
+	}
+
+	return dbinstance, nil
+}
+
+func (d *Database) connectionCheck(conn string) {
+	log.Info("Connection check started")
+	var i int
+	for {
+		time.Sleep(time.Duration(d.config.ReconnTime) * time.Second)
+		if err := d.DB.Ping(); err != nil {
+			log.Warnf("Lost connection to Database. Attempting to reconnect.")
+			if err := d.DB.Close(); err != nil {
+				log.Warnf("Error while disconecting: %s", err)
+				continue
+			}
+			if i <= d.config.ReconnTries {
+				d.DB, err = sql.Open("postgres", conn)
+				if err != nil {
+					log.Warnf("Failed to reconnect: %s", err)
+					i++
+				} else {
+					log.Infof("Reconnected to PostgreSQL!")
+					i = 0
+				}
+			} else {
+				break
+			}
+
+		}
+	}
+}
+
+func (d *Database) GetPassword(tenant uuid.UUID, username string) (string, error) {
+	var passwordHash string
+	err := d.DB.QueryRow(`
+		CALL  public.get_user_password($1,$2,$3)
+	`, tenant, username, &passwordHash).Scan(&passwordHash)
+	if err != nil {
+		return "", fmt.Errorf("auth: unable to execute query to DB: %w", err)
+	}
+	return passwordHash, nil
+}
+
+func (d *Database) CreateUserProfile(tenant uuid.UUID, user domain.UserProfileDTO) error {
+	names, err := d.encryptNames(user.FirstName, user.LastName)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.DB.Exec(`
+		CALL public.create_profile($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+	`, tenant, user.OID, user.Nickname, names.FirstName, names.LastName, user.Password, user.CreatedAt, user.UpdatedAt, user.State, user.Role, user.NicknameNormalized, user.NicknameSkeleton,
+		names.FirstNameIndex, names.LastNameIndex)
+	if err != nil {
+		return fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+// CreateUserProfiles creates all users in a single transaction, none of them are created if any fails.
+func (d *Database) CreateUserProfiles(tenant uuid.UUID, users []domain.UserProfileDTO) error {
+	tx, err := d.DB.Begin()
+	if err != nil {
+		return fmt.Errorf("CreateUserProfiles: unable to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	for _, user := range users {
+		names, err := d.encryptNames(user.FirstName, user.LastName)
+		if err != nil {
+			return fmt.Errorf("CreateUserProfiles: profile %q: %w", user.Nickname, err)
+		}
+
+		_, err = tx.Exec(`
+			CALL public.create_profile($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+		`, tenant, user.OID, user.Nickname, names.FirstName, names.LastName, user.Password, user.CreatedAt, user.UpdatedAt, user.State, user.Role, user.NicknameNormalized, user.NicknameSkeleton,
+			names.FirstNameIndex, names.LastNameIndex)
+		if err != nil {
+			return fmt.Errorf("CreateUserProfiles: unable to create profile %q: %w", user.Nickname, err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("CreateUserProfiles: unable to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) UpdateUserProfile(tenant uuid.UUID, user domain.UserProfileDTO, userID uuid.UUID) error {
+	names, err := d.encryptNames(user.FirstName, user.LastName)
+	if err != nil {
+		return err
+	}
+
+	_, err = d.DB.Exec(`
+		CALL public.update_profile($1,$2,$3,$4,$5,$6,$7,$8,$9,$10)
+	`, tenant, user.Nickname, names.FirstName, names.LastName, user.UpdatedAt, userID, user.NicknameNormalized, user.NicknameSkeleton, names.FirstNameIndex, names.LastNameIndex)
+	if err != nil {
+		return fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) UpdatePassword(tenant uuid.UUID, newPass string, userID uuid.UUID) error {
+
+	_, err := d.DB.Exec(`
+		CALL public.update_password($1,$2,$3,$4)
+	`, tenant, newPass, time.Now().UTC(), userID)
+	if err != nil {
+		return fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return nil
+
+}
+
+func (d *Database) GetUserById(tenant uuid.UUID, userID uuid.UUID) (domain.UserProfileDTO, error) {
+	var user UserProfile
+	var attributes, visibility []byte
+	err := d.DB.QueryRow(`
+		CALL public.get_user($1, $2, $3, $4, $5, $6, $7, $8, $9, NULL, NULL, NULL, NULL, NULL);
+	`, tenant, userID, &user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role).Scan(&user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role, &attributes, &visibility,
+		&user.Privacy.ProfileVisibility, &user.Privacy.RealNameVisibility, &user.Privacy.HideRatingBreakdown)
+	if err != nil {
+		return domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	if err := user.decodeAttributes(attributes, visibility); err != nil {
+		return domain.UserProfileDTO{}, err
+	}
+	user.OID = userID
+	if err := d.decryptNames(&user); err != nil {
+		return domain.UserProfileDTO{}, err
+	}
+	return domain.UserProfileDTO{
+		OID:                  userID,
+		Nickname:             user.Nickname,
+		FirstName:            user.FirstName,
+		LastName:             user.LastName,
+		CreatedAt:            user.CreatedAt,
+		UpdatedAt:            user.UpdatedAt,
+		State:                user.State,
+		Role:                 user.Role,
+		Rating:               user.Rating,
+		Attributes:           user.Attributes,
+		AttributesVisibility: user.AttributesVisibility,
+		Privacy:              &user.Privacy,
+	}, nil
+}
+
+func (d *Database) GetUsersList(tenant uuid.UUID, pageSize int, offset int, filter domain.UsersFilter) ([]domain.UserProfileDTO, error) {
+	filterArgs, err := encodeFilter(filter)
+	if err != nil {
+		return []domain.UserProfileDTO{}, err
+	}
+
+	args := append([]interface{}{tenant, pageSize, offset}, filterArgs...)
+	args = append(args, filter.SortBy, filter.Desc)
+
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_all_users($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13);
+	`, args...)
+	if err != nil {
+		return []domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanUsers(rows)
+}
+
+func (d *Database) GetUsersByCursor(tenant uuid.UUID, pageSize int, cursor *domain.Cursor, ascending bool, filter domain.UsersFilter) ([]domain.UserProfileDTO, error) {
+	filterArgs, err := encodeFilter(filter)
+	if err != nil {
+		return []domain.UserProfileDTO{}, err
+	}
+
+	var cursorCreatedAt, cursorOID interface{}
+	if cursor != nil {
+		cursorCreatedAt, cursorOID = cursor.CreatedAt, cursor.OID
+	}
+
+	args := append([]interface{}{tenant, pageSize}, filterArgs...)
+	args = append(args, cursorCreatedAt, cursorOID, ascending)
+
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_users_by_cursor($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13);
+	`, args...)
+	if err != nil {
+		return []domain.UserProfileDTO{}, fmt.Errorf("GetUsersByCursor: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanUsers(rows)
+}
+
+func (d *Database) scanUsers(rows *sql.Rows) ([]domain.UserProfileDTO, error) {
+	var users []domain.UserProfileDTO
+
+	for rows.Next() {
+		user, err := d.scanUser(rows)
+		if err != nil {
+			return []domain.UserProfileDTO{}, err
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return []domain.UserProfileDTO{}, fmt.Errorf("unable to read rows from DB: %w", err)
+	}
+	return users, nil
+}
+
+// scanUser scans the profile columns of the current row followed by extra columns into dest.
+func (d *Database) scanUser(rows *sql.Rows, dest ...interface{}) (domain.UserProfileDTO, error) {
+	var user UserProfile
+	var attributes, visibility []byte
+	err := rows.Scan(append([]interface{}{&user.OID, &user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role, &attributes, &visibility,
+		&user.Privacy.ProfileVisibility, &user.Privacy.RealNameVisibility, &user.Privacy.HideRatingBreakdown}, dest...)...)
+	if err != nil {
+		return domain.UserProfileDTO{}, fmt.Errorf("unable to scan row from DB: %w", err)
+	}
+	if err := user.decodeAttributes(attributes, visibility); err != nil {
+		return domain.UserProfileDTO{}, err
+	}
+	if err := d.decryptNames(&user); err != nil {
+		return domain.UserProfileDTO{}, err
+	}
+
+	return domain.UserProfileDTO{
+		OID:                  user.OID,
+		Nickname:             user.Nickname,
+		FirstName:            user.FirstName,
+		LastName:             user.LastName,
+		CreatedAt:            user.CreatedAt,
+		UpdatedAt:            user.UpdatedAt,
+		State:                user.State,
+		Role:                 user.Role,
+		Rating:               user.Rating,
+		Attributes:           user.Attributes,
+		AttributesVisibility: user.AttributesVisibility,
+		Privacy:              &user.Privacy,
+	}, nil
+}
+
+func (d *Database) GetUsersCount(tenant uuid.UUID, filter domain.UsersFilter) (int, error) {
+	filterArgs, err := encodeFilter(filter)
+	if err != nil {
+		return 0, err
+	}
+
+	var totalUsers int
+	err = d.DB.QueryRow(`SELECT public.get_users_count($1,$2,$3,$4,$5,$6,$7,$8,$9);`, append([]interface{}{tenant}, filterArgs...)...).Scan(&totalUsers)
+	if err != nil {
+		return 0, fmt.Errorf("GetUsersCount: unable to execute query to DB: %w", err)
+	}
+	return totalUsers, nil
+}
+
+func (d *Database) GetUserForToken(tenant uuid.UUID, nickname string) (domain.UserProfileDTO, error) {
+
+	var user UserProfile
+
+	err := d.DB.QueryRow(`
+	CALL public.get_user_for_token($1,$2,$3,$4,$5,$6)
+	`, tenant, nickname, &user.OID, &user.Nickname, &user.Role, &user.State).Scan(&user.OID, &user.Nickname, &user.Role, &user.State)
+	if err != nil {
+		return domain.UserProfileDTO{}, fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return domain.UserProfileDTO{
+		OID:      user.OID,
+		Nickname: user.Nickname,
+		Role:     user.Role,
+		State:    user.State,
+	}, nil
+}
+
+// GetUserState returns Deleted for users that don't exist in the tenant.
+func (d *Database) GetUserState(tenant uuid.UUID, oid uuid.UUID) (int, error) {
+	var state sql.NullInt64
+	err := d.DB.QueryRow(`
+	CALL public.get_user_state($1,$2,NULL)
+	`, tenant, oid).Scan(&state)
+	if err != nil {
+		return 0, fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	if !state.Valid {
+		return int(domain.Deleted), nil
+	}
+	return int(state.Int64), nil
+}
+
+// SetRating stores the rating of the user counted by ClickHouse, lists are sorted by it.
+func (d *Database) SetRating(tenant uuid.UUID, oid uuid.UUID, rating int) error {
+	_, err := d.DB.Exec(`
+	CALL public.set_user_rating($1,$2,$3)
+	`, tenant, oid, rating)
+	if err != nil {
+		return fmt.Errorf("SetRating: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+// SyncRatings stores ratings of all users of the organization, users missing from ratings have no votes.
+// It returns the number of users whose rating changed.
+func (d *Database) SyncRatings(tenant uuid.UUID, ratings map[uuid.UUID]int) (int, error) {
+	oids := make([]string, 0, len(ratings))
+	values := make([]int64, 0, len(ratings))
+	for oid, rating := range ratings {
+		oids = append(oids, oid.String())
+		values = append(values, int64(rating))
+	}
+
+	var updated int
+	err := d.DB.QueryRow(`
+		SELECT public.sync_user_ratings($1,$2,$3);
+	`, tenant, pq.Array(oids), pq.Array(values)).Scan(&updated)
+	if err != nil {
+		return 0, fmt.Errorf("SyncRatings: unable to execute query to DB: %w", err)
+	}
+	return updated, nil
+}
+
+func (d *Database) DeleteUser(tenant uuid.UUID, oid uuid.UUID, releaseNickname bool) error {
+	_, err := d.DB.Exec(`
+	CALL public.delete_user($1,$2,$3,$4)
+	`, tenant, oid, time.Now().UTC(), releaseNickname)
+	if err != nil {
+		return fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) UpdateAttributes(tenant uuid.UUID, attributes map[string]interface{}, visibility map[string]domain.Visibility, oid uuid.UUID) error {
+	attributesJSON, err := json.Marshal(attributes)
+	if err != nil {
+		return fmt.Errorf("UpdateAttributes: unable to marshal attributes: %w", err)
+	}
+	visibilityJSON, err := json.Marshal(visibility)
+	if err != nil {
+		return fmt.Errorf("UpdateAttributes: unable to marshal visibility: %w", err)
+	}
+
+	_, err = d.DB.Exec(`
+	CALL public.update_attributes($1,$2,$3,$4,$5)
+	`, tenant, string(attributesJSON), string(visibilityJSON), time.Now().UTC(), oid)
+	if err != nil {
+		return fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) UpdatePrivacy(tenant uuid.UUID, privacy domain.PrivacySettings, oid uuid.UUID) error {
+	_, err := d.DB.Exec(`
+	CALL public.update_privacy($1,$2,$3,$4,$5,$6)
+	`, tenant, privacy.ProfileVisibility, privacy.RealNameVisibility, privacy.HideRatingBreakdown, time.Now().UTC(), oid)
+	if err != nil {
+		return fmt.Errorf("UpdatePrivacy: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetAttributesSchema(tenant uuid.UUID) ([]byte, error) {
+	var schema []byte
+	err := d.DB.QueryRow(`
+	CALL public.get_attributes_schema($1,NULL)
+	`, tenant).Scan(&schema)
+	if err != nil {
+		return nil, fmt.Errorf("GetAttributesSchema: unable to execute query to DB: %w", err)
+	}
+	return schema, nil
+}
+
+func (d *Database) SetAttributesSchema(tenant uuid.UUID, schema []byte) error {
+	_, err := d.DB.Exec(`
+	CALL public.set_attributes_schema($1,$2,$3)
+	`, tenant, string(schema), time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("SetAttributesSchema: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+// encodeFilter returns filter as arguments for get_all_users and get_users_count following the tenant,
+// unset filters are passed as NULL.
+func encodeFilter(filter domain.UsersFilter) ([]interface{}, error) {
+	var attributes interface{}
+	if len(filter.Attributes) > 0 {
+		encoded, err := json.Marshal(filter.Attributes)
+		if err != nil {
+			return nil, fmt.Errorf("unable to marshal attributes filter: %w", err)
+		}
+		attributes = string(encoded)
+	}
+
+	var state, role interface{}
+	if filter.State != nil {
+		state = int(*filter.State)
+	}
+	if filter.Role != nil {
+		role = int(*filter.Role)
+	}
+
+	return []interface{}{attributes, state, role, nullTime(filter.CreatedFrom), nullTime(filter.CreatedTo), nullTime(filter.UpdatedFrom), nullTime(filter.UpdatedTo), filter.Listed}, nil
+}
+
+func nameVisibilitiesArray(visibilities []domain.NameVisibility) interface{} {
+	strs := make([]string, 0, len(visibilities))
+	for _, v := range visibilities {
+		strs = append(strs, string(v))
+	}
+	return pq.Array(strs)
+}
+
+func nullTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
+
+func (d *Database) GetLastNicknameChange(tenant uuid.UUID, oid uuid.UUID) (time.Time, error) {
+	var changedAt sql.NullTime
+	err := d.DB.QueryRow(`
+	CALL public.get_last_nickname_change($1,$2,NULL)
+	`, tenant, oid).Scan(&changedAt)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("GetLastNicknameChange: unable to execute query to DB: %w", err)
+	}
+	return changedAt.Time, nil
+}
+
+func (d *Database) GetNicknameReservation(tenant uuid.UUID, nickname string, since time.Time) (uuid.UUID, error) {
+	var oid uuid.NullUUID
+	err := d.DB.QueryRow(`
+	CALL public.get_nickname_reservation($1,$2,$3,NULL)
+	`, tenant, nickname, since).Scan(&oid)
+	if err != nil {
+		return uuid.Nil, fmt.Errorf("GetNicknameReservation: unable to execute query to DB: %w", err)
+	}
+	return oid.UUID, nil
+}
+
+func (d *Database) ResolveNickname(tenant uuid.UUID, nickname string) (domain.NicknameDTO, error) {
+	var resolved domain.NicknameDTO
+	var changedAt sql.NullTime
+	err := d.DB.QueryRow(`
+	SELECT * FROM public.resolve_nickname($1,$2);
+	`, tenant, nickname).Scan(&resolved.OID, &resolved.Nickname, &changedAt, &resolved.Visibility)
+	if err != nil {
+		return domain.NicknameDTO{}, fmt.Errorf("ResolveNickname: unable to execute query to DB: %w", err)
+	}
+	if changedAt.Valid {
+		resolved.Former = true
+		resolved.ChangedAt = &changedAt.Time
+	}
+	return resolved, nil
+}
+
+func (d *Database) GetNicknameConflict(tenant uuid.UUID, oid uuid.UUID, normalized string, skeleton string) (string, error) {
+	var conflict sql.NullString
+	err := d.DB.QueryRow(`
+	CALL public.get_nickname_conflict($1,$2,$3,$4,NULL)
+	`, tenant, oid, normalized, skeleton).Scan(&conflict)
+	if err != nil {
+		return "", fmt.Errorf("GetNicknameConflict: unable to execute query to DB: %w", err)
+	}
+	return conflict.String, nil
+}
+
+func (d *Database) RestoreUser(tenant uuid.UUID, oid uuid.UUID) (string, error) {
+	var nickname string
+	err := d.DB.QueryRow(`
+	CALL public.restore_user($1,$2,$3,NULL)
+	`, tenant, oid, time.Now().UTC()).Scan(&nickname)
+	if err != nil {
+		return "", fmt.Errorf("RestoreUser: unable to execute query to DB: %w", err)
+	}
+	return nickname, nil
+}
+
+func (d *Database) GetUsersToPurge(tenant uuid.UUID, deletedBefore time.Time) ([]uuid.UUID, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_users_to_purge($1,$2);
+	`, tenant, deletedBefore)
+	if err != nil {
+		return nil, fmt.Errorf("GetUsersToPurge: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var oids []uuid.UUID
+	for rows.Next() {
+		var oid uuid.UUID
+		if err := rows.Scan(&oid); err != nil {
+			return nil, fmt.Errorf("GetUsersToPurge: unable to scan row from DB: %w", err)
+		}
+		oids = append(oids, oid)
+	}
+	return oids, nil
+}
+
+func (d *Database) PurgeUser(tenant uuid.UUID, oid uuid.UUID) error {
+	_, err := d.DB.Exec(`
+	CALL public.purge_user($1,$2)
+	`, tenant, oid)
+	if err != nil {
+		return fmt.Errorf("PurgeUser: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetNicknameHistory(tenant uuid.UUID, oid uuid.UUID) ([]domain.NicknameChangeDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_nickname_history($1,$2);
+	`, tenant, oid)
+	if err != nil {
+		return nil, fmt.Errorf("GetNicknameHistory: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var history []domain.NicknameChangeDTO
+	for rows.Next() {
+		var change domain.NicknameChangeDTO
+		if err := rows.Scan(&change.OldNickname, &change.NewNickname, &change.ChangedAt); err != nil {
+			return nil, fmt.Errorf("GetNicknameHistory: unable to scan row from DB: %w", err)
+		}
+		history = append(history, change)
+	}
+	return history, nil
+}
+
+func (d *Database) SearchUsers(tenant uuid.UUID, query string, nameVisibilities []domain.NameVisibility, pageSize int, offset int) ([]domain.SearchResultDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.search_users($1,$2,$3,$4,$5,$6);
+	`, tenant, query, nameVisibilitiesArray(nameVisibilities), d.nameIndexes(query), pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("SearchUsers: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var results []domain.SearchResultDTO
+	for rows.Next() {
+		var user UserProfile
+		var attributes, visibility []byte
+		var rank float32
+		var nicknameHighlight, firstNameHighlight, lastNameHighlight string
+		err := rows.Scan(&user.OID, &user.Nickname, &user.FirstName, &user.LastName, &user.CreatedAt, &user.UpdatedAt, &user.State, &user.Role, &attributes, &visibility,
+			&user.Privacy.ProfileVisibility, &user.Privacy.RealNameVisibility, &user.Privacy.HideRatingBreakdown, &rank, &nicknameHighlight, &firstNameHighlight, &lastNameHighlight)
+		if err != nil {
+			return nil, fmt.Errorf("SearchUsers: unable to scan row from DB: %w", err)
+		}
+		if err := user.decodeAttributes(attributes, visibility); err != nil {
+			return nil, err
+		}
+		if pii.IsEncrypted([]byte(user.FirstName)) || pii.IsEncrypted([]byte(user.LastName)) {
+			if err := d.decryptNames(&user); err != nil {
+				return nil, fmt.Errorf("SearchUsers: %w", err)
+			}
+			firstNameHighlight = d.nameHighlight(user.FirstName, query)
+			lastNameHighlight = d.nameHighlight(user.LastName, query)
+		}
+
+		results = append(results, domain.SearchResultDTO{
+			UserProfileDTO: domain.UserProfileDTO{
+				OID:                  user.OID,
+				Nickname:             user.Nickname,
+				FirstName:            user.FirstName,
+				LastName:             user.LastName,
+				CreatedAt:            user.CreatedAt,
+				UpdatedAt:            user.UpdatedAt,
+				State:                user.State,
+				Role:                 user.Role,
+				Attributes:           user.Attributes,
+				AttributesVisibility: user.AttributesVisibility,
+				Privacy:              &user.Privacy,
+			},
+			Rank: rank,
+			Highlights: map[string]string{
+				"nickname":   nicknameHighlight,
+				"first_name": firstNameHighlight,
+				"last_name":  lastNameHighlight,
+			},
+		})
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("SearchUsers: %w", err)
+	}
+	return results, nil
+}
+
+func (d *Database) SearchUsersCount(tenant uuid.UUID, query string, nameVisibilities []domain.NameVisibility) (int, error) {
+	var totalUsers int
+	err := d.DB.QueryRow(`SELECT public.search_users_count($1,$2,$3,$4);`, tenant, query, nameVisibilitiesArray(nameVisibilities), d.nameIndexes(query)).Scan(&totalUsers)
+	if err != nil {
+		return 0, fmt.Errorf("SearchUsersCount: unable to execute query to DB: %w", err)
+	}
+	return totalUsers, nil
+}
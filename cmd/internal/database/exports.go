@@ -0,0 +1,82 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+func (d *Database) CreateExport(tenant uuid.UUID, export domain.ExportDTO) error {
+	_, err := d.DB.Exec(`
+	CALL public.create_export($1,$2,$3,$4,$5,$6,$7)
+	`, tenant, export.OID, export.UserOID, export.RequestedBy, export.Status, export.CreatedAt, export.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("CreateExport: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) UpdateExport(tenant uuid.UUID, export domain.ExportDTO) error {
+	_, err := d.DB.Exec(`
+	CALL public.update_export($1,$2,$3,$4,$5,$6)
+	`, tenant, export.OID, export.Status, export.FilePath, export.CompletedAt, export.ExpiresAt)
+	if err != nil {
+		return fmt.Errorf("UpdateExport: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetExport(tenant uuid.UUID, oid uuid.UUID) (domain.ExportDTO, error) {
+	export := domain.ExportDTO{OID: oid}
+	var filePath sql.NullString
+	var completedAt, expiresAt sql.NullTime
+	err := d.DB.QueryRow(`
+	CALL public.get_export($1,$2,NULL,NULL,NULL,NULL,NULL,NULL,NULL)
+	`, tenant, oid).Scan(&export.UserOID, &export.RequestedBy, &export.Status, &filePath, &export.CreatedAt, &completedAt, &expiresAt)
+	if err != nil {
+		return domain.ExportDTO{}, fmt.Errorf("GetExport: unable to execute query to DB: %w", err)
+	}
+	export.FilePath = filePath.String
+	if completedAt.Valid {
+		export.CompletedAt = &completedAt.Time
+	}
+	if expiresAt.Valid {
+		export.ExpiresAt = &expiresAt.Time
+	}
+	return export, nil
+}
+
+func (d *Database) GetExpiredExports(tenant uuid.UUID, now time.Time) ([]domain.ExportDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_expired_exports($1,$2);
+	`, tenant, now)
+	if err != nil {
+		return nil, fmt.Errorf("GetExpiredExports: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var exports []domain.ExportDTO
+	for rows.Next() {
+		var export domain.ExportDTO
+		var filePath sql.NullString
+		if err := rows.Scan(&export.OID, &filePath); err != nil {
+			return nil, fmt.Errorf("GetExpiredExports: unable to scan row from DB: %w", err)
+		}
+		export.FilePath = filePath.String
+		exports = append(exports, export)
+	}
+	return exports, nil
+}
+
+func (d *Database) DeleteExport(tenant uuid.UUID, oid uuid.UUID) error {
+	_, err := d.DB.Exec(`
+	CALL public.delete_export($1,$2)
+	`, tenant, oid)
+	if err != nil {
+		return fmt.Errorf("DeleteExport: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
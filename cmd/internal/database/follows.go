@@ -0,0 +1,78 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// Follow makes follower follow followee, following twice is a no-op.
+// It returns false if followee doesn't exist, isn't active or is hidden while includeHidden isn't set.
+func (d *Database) Follow(tenant uuid.UUID, follower uuid.UUID, followee uuid.UUID, includeHidden bool) (bool, error) {
+	var followed bool
+	err := d.DB.QueryRow(`
+		SELECT public.follow_user($1,$2,$3,$4,$5);
+	`, tenant, follower, followee, includeHidden, time.Now().UTC()).Scan(&followed)
+	if err != nil {
+		return false, fmt.Errorf("Follow: unable to execute query to DB: %w", err)
+	}
+	return followed, nil
+}
+
+func (d *Database) Unfollow(tenant uuid.UUID, follower uuid.UUID, followee uuid.UUID) error {
+	_, err := d.DB.Exec(`
+	CALL public.unfollow_user($1,$2,$3)
+	`, tenant, follower, followee)
+	if err != nil {
+		return fmt.Errorf("Unfollow: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+// GetFollows returns active followers or followed users of oid, most recent first. Only public profiles are returned unless all is set.
+func (d *Database) GetFollows(tenant uuid.UUID, oid uuid.UUID, list domain.FollowList, all bool, pageSize int, offset int) ([]domain.UserProfileDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_follows($1,$2,$3,$4,$5,$6);
+	`, tenant, oid, list, all, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetFollows: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanUsers(rows)
+}
+
+func (d *Database) GetFollowsCount(tenant uuid.UUID, oid uuid.UUID, list domain.FollowList, all bool) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT public.get_follows_count($1,$2,$3,$4);`, tenant, oid, list, all).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetFollowsCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+// GetFollowCounts returns the amount of followers and followed users of oid.
+func (d *Database) GetFollowCounts(tenant uuid.UUID, oid uuid.UUID) (int, int, error) {
+	var followers, following int
+	err := d.DB.QueryRow(`
+	CALL public.get_follow_counts($1,$2,NULL,NULL)
+	`, tenant, oid).Scan(&followers, &following)
+	if err != nil {
+		return 0, 0, fmt.Errorf("GetFollowCounts: unable to execute query to DB: %w", err)
+	}
+	return followers, following, nil
+}
+
+func (d *Database) GetFollowRelation(tenant uuid.UUID, oid uuid.UUID, other uuid.UUID, includeHidden bool) (domain.FollowRelationDTO, error) {
+	relation := domain.FollowRelationDTO{OID: oid, OtherOID: other}
+	err := d.DB.QueryRow(`
+	CALL public.get_follow_relation($1,$2,$3,$4,NULL,NULL)
+	`, tenant, oid, other, includeHidden).Scan(&relation.Follows, &relation.FollowedBy)
+	if err != nil {
+		return domain.FollowRelationDTO{}, fmt.Errorf("GetFollowRelation: unable to execute query to DB: %w", err)
+	}
+	relation.Mutual = relation.Follows && relation.FollowedBy
+	return relation, nil
+}
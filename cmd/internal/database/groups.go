@@ -0,0 +1,214 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// CreateGroup creates the group with owner as its first member, it returns false if the name is taken.
+func (d *Database) CreateGroup(tenant uuid.UUID, group domain.GroupDTO, owner uuid.UUID) (bool, error) {
+	var created bool
+	err := d.DB.QueryRow(`
+		SELECT public.create_group($1,$2,$3,$4,$5,$6);
+	`, tenant, group.OID, group.Name, group.Description, owner, group.CreatedAt).Scan(&created)
+	if err != nil {
+		return false, fmt.Errorf("CreateGroup: unable to execute query to DB: %w", err)
+	}
+	return created, nil
+}
+
+func (d *Database) GetGroup(tenant uuid.UUID, oid uuid.UUID) (domain.GroupDTO, error) {
+	var group domain.GroupDTO
+	err := d.DB.QueryRow(`
+		SELECT * FROM public.get_group($1,$2);
+	`, tenant, oid).Scan(&group.OID, &group.Name, &group.Description, &group.MembersCount, &group.CreatedAt, &group.UpdatedAt)
+	if err != nil {
+		return domain.GroupDTO{}, fmt.Errorf("GetGroup: unable to execute query to DB: %w", err)
+	}
+	return group, nil
+}
+
+func (d *Database) GetGroups(tenant uuid.UUID, pageSize int, offset int) ([]domain.GroupDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_groups($1,$2,$3);
+	`, tenant, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroups: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []domain.GroupDTO
+	for rows.Next() {
+		var group domain.GroupDTO
+		if err := rows.Scan(&group.OID, &group.Name, &group.Description, &group.MembersCount, &group.CreatedAt, &group.UpdatedAt); err != nil {
+			return nil, fmt.Errorf("GetGroups: unable to scan row from DB: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetGroups: %w", err)
+	}
+	return groups, nil
+}
+
+func (d *Database) GetGroupsCount(tenant uuid.UUID) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT public.get_groups_count($1);`, tenant).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetGroupsCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+// UpdateGroup changes the name and the description of the group, it returns false if the name is taken by another group.
+func (d *Database) UpdateGroup(tenant uuid.UUID, group domain.GroupDTO) (bool, error) {
+	var updated bool
+	err := d.DB.QueryRow(`
+		SELECT public.update_group($1,$2,$3,$4,$5);
+	`, tenant, group.OID, group.Name, group.Description, group.UpdatedAt).Scan(&updated)
+	if err != nil {
+		return false, fmt.Errorf("UpdateGroup: unable to execute query to DB: %w", err)
+	}
+	return updated, nil
+}
+
+func (d *Database) DeleteGroup(tenant uuid.UUID, oid uuid.UUID) error {
+	_, err := d.DB.Exec(`
+	CALL public.delete_group($1,$2)
+	`, tenant, oid)
+	if err != nil {
+		return fmt.Errorf("DeleteGroup: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+// AddGroupMember adds user to the group with status or changes their role in it, active members stay active.
+// editGranted lets owners of the group edit the profile of user, it's never revoked by adding them again.
+// It returns false if that would leave the group without an owner.
+func (d *Database) AddGroupMember(tenant uuid.UUID, group uuid.UUID, user uuid.UUID, role domain.GroupRole, status domain.GroupMemberStatus, editGranted bool) (bool, error) {
+	var added bool
+	err := d.DB.QueryRow(`
+		SELECT public.add_group_member($1,$2,$3,$4,$5,$6,$7);
+	`, tenant, group, user, role, status, editGranted, time.Now().UTC()).Scan(&added)
+	if err != nil {
+		return false, fmt.Errorf("AddGroupMember: unable to execute query to DB: %w", err)
+	}
+	return added, nil
+}
+
+// AcceptGroupInvitation makes the user an active member of the group they were invited to, granting owners of the group
+// to edit their profile. It returns false if there is no such invitation.
+func (d *Database) AcceptGroupInvitation(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (bool, error) {
+	var accepted bool
+	err := d.DB.QueryRow(`
+		SELECT public.accept_group_invitation($1,$2,$3,$4);
+	`, tenant, group, user, time.Now().UTC()).Scan(&accepted)
+	if err != nil {
+		return false, fmt.Errorf("AcceptGroupInvitation: unable to execute query to DB: %w", err)
+	}
+	return accepted, nil
+}
+
+// RemoveGroupMember removes user from the group, it returns false if user is the last owner of the group.
+func (d *Database) RemoveGroupMember(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (bool, error) {
+	var removed bool
+	err := d.DB.QueryRow(`
+		SELECT public.remove_group_member($1,$2,$3);
+	`, tenant, group, user).Scan(&removed)
+	if err != nil {
+		return false, fmt.Errorf("RemoveGroupMember: unable to execute query to DB: %w", err)
+	}
+	return removed, nil
+}
+
+// GetGroupMembers returns members of the group that aren't deleted, owners first.
+func (d *Database) GetGroupMembers(tenant uuid.UUID, group uuid.UUID, pageSize int, offset int) ([]domain.GroupMemberDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_group_members($1,$2,$3,$4);
+	`, tenant, group, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetGroupMembers: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var members []domain.GroupMemberDTO
+	for rows.Next() {
+		var member domain.GroupMemberDTO
+		member.User, err = d.scanUser(rows, &member.Role, &member.JoinedAt)
+		if err != nil {
+			return nil, fmt.Errorf("GetGroupMembers: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetGroupMembers: %w", err)
+	}
+	return members, nil
+}
+
+func (d *Database) GetGroupMembersCount(tenant uuid.UUID, group uuid.UUID) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT public.get_group_members_count($1,$2);`, tenant, group).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetGroupMembersCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+// GetUserGroups returns groups the user has the membership status in along with their role in each, most recently joined first.
+func (d *Database) GetUserGroups(tenant uuid.UUID, user uuid.UUID, status domain.GroupMemberStatus, pageSize int, offset int) ([]domain.GroupDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_user_groups($1,$2,$3,$4,$5);
+	`, tenant, user, status, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetUserGroups: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var groups []domain.GroupDTO
+	for rows.Next() {
+		var group domain.GroupDTO
+		if err := rows.Scan(&group.OID, &group.Name, &group.Description, &group.MembersCount, &group.CreatedAt, &group.UpdatedAt, &group.Role); err != nil {
+			return nil, fmt.Errorf("GetUserGroups: unable to scan row from DB: %w", err)
+		}
+		groups = append(groups, group)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetUserGroups: %w", err)
+	}
+	return groups, nil
+}
+
+func (d *Database) GetUserGroupsCount(tenant uuid.UUID, user uuid.UUID, status domain.GroupMemberStatus) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT public.get_user_groups_count($1,$2,$3);`, tenant, user, status).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetUserGroupsCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+// GetGroupRole returns the role of user in the group, it's empty if user isn't an active member.
+func (d *Database) GetGroupRole(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (domain.GroupRole, error) {
+	var role sql.NullString
+	err := d.DB.QueryRow(`SELECT public.get_group_role($1,$2,$3);`, tenant, group, user).Scan(&role)
+	if err != nil {
+		return "", fmt.Errorf("GetGroupRole: unable to execute query to DB: %w", err)
+	}
+	return domain.GroupRole(role.String), nil
+}
+
+// IsGroupOwnerOf checks that owner owns any group member belongs to and granted its owners to edit their profile,
+// it's always false for members that are moderators or admins.
+func (d *Database) IsGroupOwnerOf(tenant uuid.UUID, owner uuid.UUID, member uuid.UUID) (bool, error) {
+	var isOwner bool
+	err := d.DB.QueryRow(`SELECT public.is_group_owner_of($1,$2,$3);`, tenant, owner, member).Scan(&isOwner)
+	if err != nil {
+		return false, fmt.Errorf("IsGroupOwnerOf: unable to execute query to DB: %w", err)
+	}
+	return isOwner, nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+func (d *Database) CreateInvite(tenant uuid.UUID, invite domain.InviteDTO) error {
+	_, err := d.DB.Exec(`
+	CALL public.create_invite($1,$2,$3,$4,$5,$6,$7,$8)
+	`, tenant, invite.Code, invite.CreatedBy, invite.Role, invite.GroupOID, invite.MaxUses, invite.ExpiresAt, invite.CreatedAt)
+	if err != nil {
+		return fmt.Errorf("CreateInvite: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetInvite(tenant uuid.UUID, code string) (domain.InviteDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_invite($1,$2);
+	`, tenant, code)
+	if err != nil {
+		return domain.InviteDTO{}, fmt.Errorf("GetInvite: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	invites, err := scanInvites(rows)
+	if err != nil {
+		return domain.InviteDTO{}, fmt.Errorf("GetInvite: %w", err)
+	}
+	if len(invites) == 0 {
+		return domain.InviteDTO{}, fmt.Errorf("GetInvite: %w", sql.ErrNoRows)
+	}
+	return invites[0], nil
+}
+
+// GetInvites returns invites created by createdBy, or all invites of the organization if all is set, most recent first.
+func (d *Database) GetInvites(tenant uuid.UUID, createdBy uuid.UUID, all bool, pageSize int, offset int) ([]domain.InviteDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_invites($1,$2,$3,$4,$5);
+	`, tenant, createdBy, all, pageSize, offset)
+	if err != nil {
+		return nil, fmt.Errorf("GetInvites: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	invites, err := scanInvites(rows)
+	if err != nil {
+		return nil, fmt.Errorf("GetInvites: %w", err)
+	}
+	return invites, nil
+}
+
+func (d *Database) GetInvitesCount(tenant uuid.UUID, createdBy uuid.UUID, all bool) (int, error) {
+	var count int
+	err := d.DB.QueryRow(`SELECT public.get_invites_count($1,$2,$3);`, tenant, createdBy, all).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("GetInvitesCount: unable to execute query to DB: %w", err)
+	}
+	return count, nil
+}
+
+func (d *Database) DeleteInvite(tenant uuid.UUID, code string) error {
+	_, err := d.DB.Exec(`
+	CALL public.delete_invite($1,$2)
+	`, tenant, code)
+	if err != nil {
+		return fmt.Errorf("DeleteInvite: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+// CreateUserProfileWithInvite uses the invite and creates the user with the role and the group of the invite.
+// It returns false without creating the user if the invite doesn't exist, is expired or used up.
+func (d *Database) CreateUserProfileWithInvite(tenant uuid.UUID, user domain.UserProfileDTO, code string) (bool, error) {
+	names, err := d.encryptNames(user.FirstName, user.LastName)
+	if err != nil {
+		return false, fmt.Errorf("CreateUserProfileWithInvite: %w", err)
+	}
+
+	var created bool
+	err = d.DB.QueryRow(`
+		SELECT public.create_profile_with_invite($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13);
+	`, tenant, code, user.OID, user.Nickname, names.FirstName, names.LastName, user.Password, user.CreatedAt, user.State, user.NicknameNormalized, user.NicknameSkeleton,
+		names.FirstNameIndex, names.LastNameIndex).Scan(&created)
+	if err != nil {
+		return false, fmt.Errorf("CreateUserProfileWithInvite: unable to execute query to DB: %w", err)
+	}
+	return created, nil
+}
+
+func scanInvites(rows *sql.Rows) ([]domain.InviteDTO, error) {
+	var invites []domain.InviteDTO
+	for rows.Next() {
+		var invite domain.InviteDTO
+		var createdBy, groupOID uuid.NullUUID
+		err := rows.Scan(&invite.Code, &createdBy, &invite.Role, &groupOID, &invite.MaxUses, &invite.Uses, &invite.ExpiresAt, &invite.CreatedAt)
+		if err != nil {
+			return nil, fmt.Errorf("unable to scan row from DB: %w", err)
+		}
+		if createdBy.Valid {
+			invite.CreatedBy = &createdBy.UUID
+		}
+		if groupOID.Valid {
+			invite.GroupOID = &groupOID.UUID
+		}
+		invites = append(invites, invite)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("unable to read rows from DB: %w", err)
+	}
+	return invites, nil
+}
@@ -0,0 +1,21 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// GetLeaderboardUsers returns users of oids that are active and have public profiles, in no particular order.
+func (d *Database) GetLeaderboardUsers(tenant uuid.UUID, oids []uuid.UUID) ([]domain.UserProfileDTO, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_leaderboard_users($1,$2::uuid[]);
+	`, tenant, uuidArray(oids))
+	if err != nil {
+		return nil, fmt.Errorf("GetLeaderboardUsers: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	return d.scanUsers(rows)
+}
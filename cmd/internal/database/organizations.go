@@ -0,0 +1,55 @@
+package database
+
+import (
+	"fmt"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// CreateOrganization creates org and its first admin in a single transaction.
+func (d *Database) CreateOrganization(org domain.Organization, admin domain.UserProfileDTO) error {
+	names, err := d.encryptNames(admin.FirstName, admin.LastName)
+	if err != nil {
+		return fmt.Errorf("CreateOrganization: %w", err)
+	}
+
+	_, err = d.DB.Exec(`
+	CALL public.create_organization($1,$2,$3,$4,$5,$6,$7,$8,$9,$10,$11,$12,$13)
+	`, org.ID, org.Slug, org.Name, org.CreatedAt, admin.OID, admin.Nickname, names.FirstName, names.LastName, admin.Password, admin.NicknameNormalized, admin.NicknameSkeleton,
+		names.FirstNameIndex, names.LastNameIndex)
+	if err != nil {
+		return fmt.Errorf("CreateOrganization: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
+
+func (d *Database) GetOrganizationBySlug(slug string) (domain.Organization, error) {
+	var org domain.Organization
+	err := d.DB.QueryRow(`
+		SELECT * FROM public.get_organization_by_slug($1);
+	`, slug).Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt)
+	if err != nil {
+		return domain.Organization{}, fmt.Errorf("GetOrganizationBySlug: unable to execute query to DB: %w", err)
+	}
+	return org, nil
+}
+
+func (d *Database) GetOrganizations() ([]domain.Organization, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_organizations();
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("GetOrganizations: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var orgs []domain.Organization
+	for rows.Next() {
+		var org domain.Organization
+		if err := rows.Scan(&org.ID, &org.Slug, &org.Name, &org.CreatedAt); err != nil {
+			return nil, fmt.Errorf("GetOrganizations: unable to scan row from DB: %w", err)
+		}
+		orgs = append(orgs, org)
+	}
+	return orgs, nil
+}
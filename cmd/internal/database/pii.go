@@ -0,0 +1,196 @@
+package database
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+)
+
+// storedNames are first and last names the way they are stored: encrypted, along with their blind indexes.
+type storedNames struct {
+	FirstName      string
+	LastName       string
+	FirstNameIndex string
+	LastNameIndex  string
+}
+
+// encryptNames encrypts the names with the active key, they are left as is if encryption is off.
+// Names that look encrypted are refused, they are validated by handlers already.
+func (d *Database) encryptNames(firstName, lastName string) (storedNames, error) {
+	if pii.IsReserved(firstName) || pii.IsReserved(lastName) {
+		return storedNames{}, fmt.Errorf("unable to store names: %w", pii.ErrReserved)
+	}
+	first, err := d.PII.EncryptString(firstName)
+	if err != nil {
+		return storedNames{}, fmt.Errorf("unable to encrypt first name: %w", err)
+	}
+	last, err := d.PII.EncryptString(lastName)
+	if err != nil {
+		return storedNames{}, fmt.Errorf("unable to encrypt last name: %w", err)
+	}
+	return storedNames{
+		FirstName:      first,
+		LastName:       last,
+		FirstNameIndex: d.PII.BlindIndex(firstName),
+		LastNameIndex:  d.PII.BlindIndex(lastName),
+	}, nil
+}
+
+// decryptNames decrypts the names of user in place, names stored in plaintext are kept as is.
+func (d *Database) decryptNames(user *UserProfile) error {
+	var err error
+	if user.FirstName, err = d.PII.DecryptString(user.FirstName); err != nil {
+		return fmt.Errorf("unable to decrypt first name of %s: %w", user.OID, err)
+	}
+	if user.LastName, err = d.PII.DecryptString(user.LastName); err != nil {
+		return fmt.Errorf("unable to decrypt last name of %s: %w", user.OID, err)
+	}
+	return nil
+}
+
+// nameIndexes returns blind indexes of the whole query and of each of its words, so that encrypted
+// names can be found by an exact match. It's empty if encryption is off.
+func (d *Database) nameIndexes(query string) interface{} {
+	indexes := []string{}
+	if d.PII == nil {
+		return pq.Array(indexes)
+	}
+
+	seen := make(map[string]bool)
+	for _, value := range append([]string{query}, strings.Fields(query)...) {
+		index := d.PII.BlindIndex(value)
+		if !seen[index] {
+			seen[index] = true
+			indexes = append(indexes, index)
+		}
+	}
+	return pq.Array(indexes)
+}
+
+// nameHighlight highlights a decrypted name as a whole if it was found by its blind index,
+// ts_headline can't highlight encrypted names.
+func (d *Database) nameHighlight(name string, query string) string {
+	for _, value := range append([]string{query}, strings.Fields(query)...) {
+		if d.PII.BlindIndex(value) == d.PII.BlindIndex(name) {
+			return "<mark>" + name + "</mark>"
+		}
+	}
+	return name
+}
+
+// ReencryptNames encrypts names of all users of the organization with the active key and refreshes
+// their blind indexes, names that are already encrypted with it are skipped. If decrypt is true names
+// are stored in plaintext instead, so that encryption can be turned off.
+// It returns the number of updated users.
+func (d *Database) ReencryptNames(tenant uuid.UUID, chunkSize int, decrypt bool) (int, error) {
+	var updated int
+	var after uuid.NullUUID
+	for {
+		rows, err := d.DB.Query(`
+			SELECT * FROM public.get_stored_names($1,$2,$3);
+		`, tenant, after, chunkSize)
+		if err != nil {
+			return updated, fmt.Errorf("ReencryptNames: unable to execute query to DB: %w", err)
+		}
+
+		var users []UserProfile
+		for rows.Next() {
+			var user UserProfile
+			if err := rows.Scan(&user.OID, &user.FirstName, &user.LastName); err != nil {
+				rows.Close()
+				return updated, fmt.Errorf("ReencryptNames: unable to scan row from DB: %w", err)
+			}
+			users = append(users, user)
+		}
+		rows.Close()
+		if err := rows.Err(); err != nil {
+			return updated, fmt.Errorf("ReencryptNames: %w", err)
+		}
+
+		for _, user := range users {
+			changed, err := d.reencryptUserNames(tenant, user, decrypt)
+			if err != nil {
+				return updated, fmt.Errorf("ReencryptNames: %w", err)
+			}
+			if changed {
+				updated++
+			}
+		}
+
+		if len(users) < chunkSize {
+			return updated, nil
+		}
+		after = uuid.NullUUID{UUID: users[len(users)-1].OID, Valid: true}
+	}
+}
+
+// reencryptUserNames stores names of user the way ReencryptNames asks, it returns false if they are already stored that way.
+func (d *Database) reencryptUserNames(tenant uuid.UUID, user UserProfile, decrypt bool) (bool, error) {
+	if decrypt && !pii.IsEncrypted([]byte(user.FirstName)) && !pii.IsEncrypted([]byte(user.LastName)) {
+		return false, nil
+	}
+	if !decrypt && !d.PII.NeedsReencryption(user.FirstName) && !d.PII.NeedsReencryption(user.LastName) {
+		return false, nil
+	}
+
+	if err := d.decryptNames(&user); err != nil {
+		return false, err
+	}
+	names := storedNames{FirstName: user.FirstName, LastName: user.LastName}
+	if decrypt && (pii.IsReserved(names.FirstName) || pii.IsReserved(names.LastName)) {
+		return false, fmt.Errorf("unable to store names of %s in plaintext: %w", user.OID, pii.ErrReserved)
+	}
+	if !decrypt {
+		var err error
+		if names, err = d.encryptNames(user.FirstName, user.LastName); err != nil {
+			return false, err
+		}
+	}
+
+	_, err := d.DB.Exec(`
+	CALL public.set_stored_names($1,$2,$3,$4,$5,$6)
+	`, tenant, user.OID, names.FirstName, names.LastName, names.FirstNameIndex, names.LastNameIndex)
+	if err != nil {
+		return false, fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	return true, nil
+}
+
+// piiFields are fields of audit records that hold names.
+var piiFields = []string{"first_name", "last_name"}
+
+// encryptFields returns a copy of audit record fields with names encrypted.
+func (d *Database) encryptFields(fields map[string]interface{}) (map[string]interface{}, error) {
+	if d.PII == nil || len(fields) == 0 {
+		return fields, nil
+	}
+	encrypted := make(map[string]interface{}, len(fields))
+	for name, value := range fields {
+		encrypted[name] = value
+	}
+	for _, name := range piiFields {
+		if value, ok := fields[name].(string); ok {
+			var err error
+			if encrypted[name], err = d.PII.EncryptString(value); err != nil {
+				return nil, fmt.Errorf("unable to encrypt %s: %w", name, err)
+			}
+		}
+	}
+	return encrypted, nil
+}
+
+// decryptFields decrypts names in audit record fields in place.
+func (d *Database) decryptFields(fields map[string]interface{}) error {
+	for _, name := range piiFields {
+		if value, ok := fields[name].(string); ok {
+			var err error
+			if fields[name], err = d.PII.DecryptString(value); err != nil {
+				return fmt.Errorf("unable to decrypt %s: %w", name, err)
+			}
+		}
+	}
+	return nil
+}
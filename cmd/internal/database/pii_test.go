@@ -0,0 +1,45 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+)
+
+// TestReservedNamesNotStored checks that a name looking encrypted never reaches the database in plaintext,
+// reading it back would fail every list that includes the user.
+func TestReservedNamesNotStored(t *testing.T) {
+	d := newTestDatabase(t)
+	org := newTestOrganization(t, d)
+
+	forged := newTestUser("forged"+org.Slug[5:], domain.Usr)
+	forged.FirstName = "pii:v1:k1:AAAA:BBBB"
+	if err := d.CreateUserProfile(org.ID, forged); !errors.Is(err, pii.ErrReserved) {
+		t.Errorf("CreateUserProfile with reserved name: %v, want %v", err, pii.ErrReserved)
+	}
+
+	user := newTestUser("alice"+org.Slug[5:], domain.Usr)
+	if err := d.CreateUserProfile(org.ID, user); err != nil {
+		t.Fatal(err)
+	}
+	update := user
+	update.LastName = "pii:v1:"
+	if err := d.UpdateUserProfile(org.ID, update, user.OID); !errors.Is(err, pii.ErrReserved) {
+		t.Errorf("UpdateUserProfile with reserved name: %v, want %v", err, pii.ErrReserved)
+	}
+
+	users, err := d.GetUsersList(org.ID, 100, 0, domain.UsersFilter{})
+	if err != nil {
+		t.Fatalf("GetUsersList: %s", err)
+	}
+	for _, listed := range users {
+		if listed.OID == forged.OID {
+			t.Errorf("user with reserved name is listed: %+v", listed)
+		}
+		if listed.OID == user.OID && listed.LastName != user.LastName {
+			t.Errorf("last name = %q, want %q", listed.LastName, user.LastName)
+		}
+	}
+}
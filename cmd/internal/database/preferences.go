@@ -0,0 +1,46 @@
+package database
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// GetPreferences returns preferences saved by the user, the second value is false if there are none.
+func (d *Database) GetPreferences(tenant uuid.UUID, oid uuid.UUID) (domain.Preferences, bool, error) {
+	rows, err := d.DB.Query(`
+		SELECT * FROM public.get_preferences($1,$2);
+	`, tenant, oid)
+	if err != nil {
+		return domain.Preferences{}, false, fmt.Errorf("GetPreferences: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return domain.Preferences{}, false, fmt.Errorf("GetPreferences: %w", err)
+		}
+		return domain.Preferences{}, false, nil
+	}
+
+	var preferences domain.Preferences
+	err = rows.Scan(&preferences.Locale, &preferences.Timezone, &preferences.DateFormat,
+		&preferences.Notifications.Votes, &preferences.Notifications.Follows, &preferences.Notifications.Newsletter)
+	if err != nil {
+		return domain.Preferences{}, false, fmt.Errorf("GetPreferences: scan the row: %w", err)
+	}
+	return preferences, true, nil
+}
+
+func (d *Database) UpdatePreferences(tenant uuid.UUID, oid uuid.UUID, preferences domain.Preferences) error {
+	_, err := d.DB.Exec(`
+	CALL public.set_preferences($1,$2,$3,$4,$5,$6,$7,$8,$9)
+	`, tenant, oid, preferences.Locale, preferences.Timezone, preferences.DateFormat,
+		preferences.Notifications.Votes, preferences.Notifications.Follows, preferences.Notifications.Newsletter, time.Now().UTC())
+	if err != nil {
+		return fmt.Errorf("UpdatePreferences: unable to execute query to DB: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,116 @@
+package database
+
+import (
+	"database/sql"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+)
+
+// newTestDatabase connects to the migrated database at TEST_DATABASE_URL, tests only add new organizations with random slugs.
+func newTestDatabase(t *testing.T) *Database {
+	url := os.Getenv("TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("TEST_DATABASE_URL is not set")
+	}
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return &Database{DB: db}
+}
+
+func newTestUser(nick string, role domain.Role) domain.UserProfileDTO {
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	return domain.UserProfileDTO{
+		OID:                uuid.New(),
+		Nickname:           nick,
+		FirstName:          "First",
+		LastName:           "Last",
+		Password:           "hash-of-" + nick,
+		CreatedAt:          now,
+		UpdatedAt:          now,
+		State:              domain.Active,
+		Role:               role,
+		NicknameNormalized: nickname.Key(nick),
+		NicknameSkeleton:   nickname.Skeleton(nick),
+	}
+}
+
+func newTestOrganization(t *testing.T, d *Database) domain.Organization {
+	slug := "test-" + strings.ReplaceAll(uuid.NewString(), "-", "")[:12]
+	org := domain.Organization{ID: uuid.New(), Slug: slug, Name: slug, CreatedAt: time.Now().UTC()}
+	if err := d.CreateOrganization(org, newTestUser("admin"+slug[5:], domain.Admin)); err != nil {
+		t.Fatal(err)
+	}
+	return org
+}
+
+func TestTenantIsolation(t *testing.T) {
+	d := newTestDatabase(t)
+	a, b := newTestOrganization(t, d), newTestOrganization(t, d)
+
+	user := newTestUser("alice"+a.Slug[5:], domain.Usr)
+	if err := d.CreateUserProfile(a.ID, user); err != nil {
+		t.Fatal(err)
+	}
+
+	// reads of the other organization don't find the user
+	if got, err := d.GetUserById(b.ID, user.OID); err == nil {
+		t.Errorf("GetUserById in other organization = %+v, want an error", got)
+	}
+	if _, err := d.GetPassword(b.ID, user.Nickname); err == nil {
+		t.Error("GetPassword in other organization: want an error")
+	}
+	if state, err := d.GetUserState(b.ID, user.OID); err != nil || domain.State(state) != domain.Deleted {
+		t.Errorf("GetUserState in other organization = %d, %v, want %d", state, err, domain.Deleted)
+	}
+	users, err := d.GetUsersList(b.ID, 100, 0, domain.UsersFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, listed := range users {
+		if listed.OID == user.OID {
+			t.Error("GetUsersList in other organization lists the user")
+		}
+	}
+	if count, err := d.GetUsersCount(b.ID, domain.UsersFilter{}); err != nil || count != 1 {
+		t.Errorf("GetUsersCount in other organization = %d, %v, want only its admin", count, err)
+	}
+
+	// writes of the other organization don't change the user
+	update := user
+	update.Nickname, update.FirstName, update.LastName = "mallory"+a.Slug[5:], "Mallory", "Mallory"
+	update.NicknameNormalized, update.NicknameSkeleton = nickname.Key(update.Nickname), nickname.Skeleton(update.Nickname)
+	if err := d.UpdateUserProfile(b.ID, update, user.OID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.UpdatePassword(b.ID, "hash-of-mallory", user.OID); err != nil {
+		t.Fatal(err)
+	}
+	if err := d.DeleteUser(b.ID, user.OID, true); err != nil {
+		t.Fatal(err)
+	}
+	got, err := d.GetUserById(a.ID, user.OID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Nickname != user.Nickname || got.FirstName != user.FirstName || got.State != domain.Active {
+		t.Errorf("user changed by other organization: %+v", got)
+	}
+	if hash, err := d.GetPassword(a.ID, user.Nickname); err != nil || hash != user.Password {
+		t.Errorf("GetPassword = %q, %v, want the password set in its organization", hash, err)
+	}
+
+	// nicknames are unique within an organization only
+	same := newTestUser(user.Nickname, domain.Usr)
+	if err := d.CreateUserProfile(b.ID, same); err != nil {
+		t.Errorf("CreateUserProfile with nickname taken in other organization: %s", err)
+	}
+}
@@ -1,96 +1,750 @@
-package domain
-
-import (
-	"time"
-
-	"github.com/google/uuid"
-)
-
-type Role int
-
-type State int
-
-const (
-	Usr Role = iota + 1
-	Moderator
-	Admin
-)
-
-const (
-	Deleted State = iota - 1
-	Banned
-	Active
-)
-
-type UserProfileManager interface {
-	CreateUserProfile(user UserProfileDTO) error
-	UpdateUserProfile(user UserProfileDTO, oid uuid.UUID) error
-	UpdatePassword(newPass string, oid uuid.UUID) error
-	GetUserById(userID uuid.UUID) (UserProfileDTO, error)
-	GetUserForToken(nickname string) (UserProfileDTO, error)
-	GetUsersList(pageSize int, offset int) ([]UserProfileDTO, error)
-	DeleteUser(oid uuid.UUID) error
-	GetPassword(nickname string) (string, error)
-	GetUsersCount() (int, error)
-	GetUserState(oid uuid.UUID) (int, error)
-}
-
-type StatsManager interface {
-	RateProfile(vote VoteDTO) error
-	GetVote(vote VoteDTO) (VoteDTO, bool, error)
-	LastVotedAt(vote VoteDTO) (time.Time, error)
-	UpdateProfileRating(vote VoteDTO, oldRating int32) error
-	GetRating(userId uuid.UUID) (int, error)
-	GetRatingSeparately(userId uuid.UUID) (string, error)
-	GetRatingForList(oids []uuid.UUID) (map[uuid.UUID]int, error)
-}
-
-type DomainInterface interface {
-	UserProfileManager
-	StatsManager
-}
-
-type CacheInterface interface {
-	Set(key string, value interface{}) error
-	GetUser(key string) (UserProfileDTO, error)
-	GetUsersList(key string) (Pagination[UserProfileDTO], error)
-	MakeKey(pageSize int, offset int) string
-}
-
-type UserProfileDTO struct {
-	OID       uuid.UUID `json:"oid"`
-	Nickname  string    `json:"nickname"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	Password  string    `json:"password"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	State     State     `json:"state"`
-	Role      Role      `json:"user_role"`
-	Rating    int       `json:"rating"`
-}
-
-type GetProfileDTO struct {
-	OID       uuid.UUID `json:"oid"`
-	Nickname  string    `json:"nickname"`
-	FirstName string    `json:"first_name"`
-	LastName  string    `json:"last_name"`
-	CreatedAt time.Time `json:"created_at"`
-	UpdatedAt time.Time `json:"updated_at"`
-	State     State     `json:"state"`
-	Role      Role      `json:"user_role"`
-	Rating    string    `json:"rating"`
-}
-
-type VoteDTO struct {
-	FromOID uuid.UUID `json:"from_oid"`
-	ToOID   uuid.UUID `json:"oid"`
-	EmojiId int32     `json:"emoji"`
-	VotedAt time.Time `json:"voted_at"`
-}
-
-type Pagination[T any] struct {
-	TotalItems  int `json:"total_items"`
-	CurrentPage int `json:"current_page"`
-	Users       []T `json:"users"`
-}
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+type Role int
+
+type State int
+
+const (
+	Usr Role = iota + 1
+	Moderator
+	Admin
+)
+
+const (
+	Deleted State = iota - 1
+	Banned
+	Active
+)
+
+type Visibility string
+
+type ProfileVisibility string
+
+type NameVisibility string
+
+type ExportStatus string
+
+type FollowList string
+
+type DateFormat string
+
+type GroupRole string
+
+// GroupMemberStatus is invited until the user accepts the invitation to the group.
+type GroupMemberStatus string
+
+const (
+	VisibilityPublic     Visibility = "public"
+	VisibilitySelf       Visibility = "self"
+	VisibilityModerators Visibility = "moderators"
+)
+
+const (
+	ProfilePublic   ProfileVisibility = "public"
+	ProfileUnlisted ProfileVisibility = "unlisted"
+	ProfileHidden   ProfileVisibility = "hidden"
+)
+
+const (
+	NameEveryone NameVisibility = "everyone"
+	NameUsers    NameVisibility = "users"
+	NameSelf     NameVisibility = "self"
+)
+
+const (
+	DateFormatISO DateFormat = "iso"
+	DateFormatDMY DateFormat = "dmy"
+	DateFormatMDY DateFormat = "mdy"
+)
+
+const (
+	GroupOwner  GroupRole = "owner"
+	GroupMember GroupRole = "member"
+)
+
+const (
+	GroupInvited GroupMemberStatus = "invited"
+	GroupActive  GroupMemberStatus = "active"
+)
+
+const (
+	FollowersList FollowList = "followers"
+	FollowingList FollowList = "following"
+)
+
+const (
+	SortByNickname  = "nickname"
+	SortByCreatedAt = "created_at"
+	SortByRating    = "rating"
+)
+
+type ImportStatus string
+
+type BatchAction string
+
+type AuditAction string
+
+const (
+	ImportCreated ImportStatus = "created"
+	ImportSkipped ImportStatus = "skipped"
+	ImportFailed  ImportStatus = "failed"
+)
+
+const (
+	BatchBan     BatchAction = "ban"
+	BatchUnban   BatchAction = "unban"
+	BatchDelete  BatchAction = "delete"
+	BatchSetRole BatchAction = "set_role"
+)
+
+// Batch actions are audited under their own names.
+const (
+	AuditCreate      AuditAction = "create"
+	AuditUpdate      AuditAction = "update"
+	AuditPassword    AuditAction = "password"
+	AuditDelete      AuditAction = "delete"
+	AuditRestore     AuditAction = "restore"
+	AuditPrivacy     AuditAction = "privacy"
+	AuditAttributes  AuditAction = "attributes"
+	AuditImport      AuditAction = "import"
+	AuditPurge       AuditAction = "purge"
+	AuditPreferences AuditAction = "preferences"
+
+	// group records target the group, member records target the member
+	AuditGroupCreate       AuditAction = "group_create"
+	AuditGroupUpdate       AuditAction = "group_update"
+	AuditGroupDelete       AuditAction = "group_delete"
+	AuditGroupMemberAdd    AuditAction = "group_member_add"
+	AuditGroupMemberRemove AuditAction = "group_member_remove"
+
+	// invite records target the user that created the invite
+	AuditInviteCreate AuditAction = "invite_create"
+	AuditInviteRevoke AuditAction = "invite_revoke"
+)
+
+const (
+	ExportPending ExportStatus = "pending"
+	ExportReady   ExportStatus = "ready"
+	ExportFailed  ExportStatus = "failed"
+)
+
+type UserProfileManager interface {
+	CreateUserProfile(tenant uuid.UUID, user UserProfileDTO) error
+	CreateUserProfiles(tenant uuid.UUID, users []UserProfileDTO) error
+	GetBatchTargets(tenant uuid.UUID, oids []uuid.UUID, filter *UsersFilter, limit int) ([]UserProfileDTO, error)
+	GetLeaderboardUsers(tenant uuid.UUID, oids []uuid.UUID) ([]UserProfileDTO, error)
+	ApplyBatchAction(tenant uuid.UUID, action BatchAction, role Role, oids []uuid.UUID, source AuditSource, releaseNickname bool) ([]uuid.UUID, error)
+	UpdateUserProfile(tenant uuid.UUID, user UserProfileDTO, oid uuid.UUID) error
+	UpdatePassword(tenant uuid.UUID, newPass string, oid uuid.UUID) error
+	GetUserById(tenant uuid.UUID, userID uuid.UUID) (UserProfileDTO, error)
+	GetUserForToken(tenant uuid.UUID, nickname string) (UserProfileDTO, error)
+	GetUsersList(tenant uuid.UUID, pageSize int, offset int, filter UsersFilter) ([]UserProfileDTO, error)
+	GetUsersByCursor(tenant uuid.UUID, pageSize int, cursor *Cursor, ascending bool, filter UsersFilter) ([]UserProfileDTO, error)
+	DeleteUser(tenant uuid.UUID, oid uuid.UUID, releaseNickname bool) error
+	RestoreUser(tenant uuid.UUID, oid uuid.UUID) (string, error)
+	GetUsersToPurge(tenant uuid.UUID, deletedBefore time.Time) ([]uuid.UUID, error)
+	PurgeUser(tenant uuid.UUID, oid uuid.UUID) error
+	GetPassword(tenant uuid.UUID, nickname string) (string, error)
+	GetUsersCount(tenant uuid.UUID, filter UsersFilter) (int, error)
+	GetUserState(tenant uuid.UUID, oid uuid.UUID) (int, error)
+	SetRating(tenant uuid.UUID, oid uuid.UUID, rating int) error
+	SyncRatings(tenant uuid.UUID, ratings map[uuid.UUID]int) (int, error)
+	UpdateAttributes(tenant uuid.UUID, attributes map[string]interface{}, visibility map[string]Visibility, oid uuid.UUID) error
+	UpdatePrivacy(tenant uuid.UUID, privacy PrivacySettings, oid uuid.UUID) error
+	GetAttributesSchema(tenant uuid.UUID) ([]byte, error)
+	SetAttributesSchema(tenant uuid.UUID, schema []byte) error
+	GetLastNicknameChange(tenant uuid.UUID, oid uuid.UUID) (time.Time, error)
+	GetNicknameReservation(tenant uuid.UUID, nickname string, since time.Time) (uuid.UUID, error)
+	ResolveNickname(tenant uuid.UUID, nickname string) (NicknameDTO, error)
+	GetNicknameConflict(tenant uuid.UUID, oid uuid.UUID, normalized string, skeleton string) (string, error)
+	GetNicknameHistory(tenant uuid.UUID, oid uuid.UUID) ([]NicknameChangeDTO, error)
+	SearchUsers(tenant uuid.UUID, query string, nameVisibilities []NameVisibility, pageSize int, offset int) ([]SearchResultDTO, error)
+	SearchUsersCount(tenant uuid.UUID, query string, nameVisibilities []NameVisibility) (int, error)
+}
+
+type ExportManager interface {
+	CreateExport(tenant uuid.UUID, export ExportDTO) error
+	UpdateExport(tenant uuid.UUID, export ExportDTO) error
+	GetExport(tenant uuid.UUID, oid uuid.UUID) (ExportDTO, error)
+	GetExpiredExports(tenant uuid.UUID, now time.Time) ([]ExportDTO, error)
+	DeleteExport(tenant uuid.UUID, oid uuid.UUID) error
+}
+
+type StatsManager interface {
+	RateProfile(tenant uuid.UUID, vote VoteDTO) error
+	GetVote(tenant uuid.UUID, vote VoteDTO) (VoteDTO, bool, error)
+	LastVotedAt(tenant uuid.UUID, vote VoteDTO) (time.Time, error)
+	UpdateProfileRating(tenant uuid.UUID, vote VoteDTO, oldVote VoteDTO) error
+	GetRating(tenant uuid.UUID, userId uuid.UUID) (int, error)
+	GetRatingBreakdown(tenant uuid.UUID, userId uuid.UUID, excluded []uuid.UUID) (Rating, error)
+	GetRatingForList(tenant uuid.UUID, oids []uuid.UUID) (map[uuid.UUID]Rating, error)
+	GetTopRated(tenant uuid.UUID, emojiID int32, since *time.Time, limit int, offset int) ([]RatedUser, error)
+	GetRatingHistory(tenant uuid.UUID, userId uuid.UUID, excluded []uuid.UUID, interval RatingInterval, from time.Time, to time.Time) ([]RatingBucket, error)
+	GetAllRatings(tenant uuid.UUID) (map[uuid.UUID]int, error)
+	AnonymizeVotes(tenant uuid.UUID, userId uuid.UUID) error
+	GetVotesGiven(tenant uuid.UUID, userId uuid.UUID) ([]VoteDTO, error)
+	GetVotesReceived(tenant uuid.UUID, userId uuid.UUID) ([]VoteDTO, error)
+}
+
+type FollowManager interface {
+	Follow(tenant uuid.UUID, follower uuid.UUID, followee uuid.UUID, includeHidden bool) (bool, error)
+	Unfollow(tenant uuid.UUID, follower uuid.UUID, followee uuid.UUID) error
+	GetFollows(tenant uuid.UUID, oid uuid.UUID, list FollowList, all bool, pageSize int, offset int) ([]UserProfileDTO, error)
+	GetFollowsCount(tenant uuid.UUID, oid uuid.UUID, list FollowList, all bool) (int, error)
+	GetFollowCounts(tenant uuid.UUID, oid uuid.UUID) (int, int, error)
+	GetFollowRelation(tenant uuid.UUID, oid uuid.UUID, other uuid.UUID, includeHidden bool) (FollowRelationDTO, error)
+}
+
+type BlockManager interface {
+	Block(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) (bool, error)
+	Unblock(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) error
+	IsBlocked(tenant uuid.UUID, blocker uuid.UUID, blocked uuid.UUID) (bool, error)
+	GetBlockedUsers(tenant uuid.UUID, oid uuid.UUID, pageSize int, offset int) ([]UserProfileDTO, error)
+	GetBlockedUsersCount(tenant uuid.UUID, oid uuid.UUID) (int, error)
+	GetBlockedOIDs(tenant uuid.UUID, oid uuid.UUID) ([]uuid.UUID, error)
+}
+
+type PreferencesManager interface {
+	GetPreferences(tenant uuid.UUID, oid uuid.UUID) (Preferences, bool, error)
+	UpdatePreferences(tenant uuid.UUID, oid uuid.UUID, preferences Preferences) error
+}
+
+type AuditManager interface {
+	WriteAudit(tenant uuid.UUID, records []AuditRecord) error
+	GetAudit(tenant uuid.UUID, filter AuditFilter, pageSize int, offset int) ([]AuditRecord, error)
+	GetAuditCount(tenant uuid.UUID, filter AuditFilter) (int, error)
+}
+
+type GroupManager interface {
+	CreateGroup(tenant uuid.UUID, group GroupDTO, owner uuid.UUID) (bool, error)
+	GetGroup(tenant uuid.UUID, oid uuid.UUID) (GroupDTO, error)
+	GetGroups(tenant uuid.UUID, pageSize int, offset int) ([]GroupDTO, error)
+	GetGroupsCount(tenant uuid.UUID) (int, error)
+	UpdateGroup(tenant uuid.UUID, group GroupDTO) (bool, error)
+	DeleteGroup(tenant uuid.UUID, oid uuid.UUID) error
+	AddGroupMember(tenant uuid.UUID, group uuid.UUID, user uuid.UUID, role GroupRole, status GroupMemberStatus, editGranted bool) (bool, error)
+	AcceptGroupInvitation(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (bool, error)
+	RemoveGroupMember(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (bool, error)
+	GetGroupMembers(tenant uuid.UUID, group uuid.UUID, pageSize int, offset int) ([]GroupMemberDTO, error)
+	GetGroupMembersCount(tenant uuid.UUID, group uuid.UUID) (int, error)
+	GetUserGroups(tenant uuid.UUID, user uuid.UUID, status GroupMemberStatus, pageSize int, offset int) ([]GroupDTO, error)
+	GetUserGroupsCount(tenant uuid.UUID, user uuid.UUID, status GroupMemberStatus) (int, error)
+	GetGroupRole(tenant uuid.UUID, group uuid.UUID, user uuid.UUID) (GroupRole, error)
+	IsGroupOwnerOf(tenant uuid.UUID, owner uuid.UUID, member uuid.UUID) (bool, error)
+}
+
+type InviteManager interface {
+	CreateInvite(tenant uuid.UUID, invite InviteDTO) error
+	GetInvite(tenant uuid.UUID, code string) (InviteDTO, error)
+	GetInvites(tenant uuid.UUID, createdBy uuid.UUID, all bool, pageSize int, offset int) ([]InviteDTO, error)
+	GetInvitesCount(tenant uuid.UUID, createdBy uuid.UUID, all bool) (int, error)
+	DeleteInvite(tenant uuid.UUID, code string) error
+	CreateUserProfileWithInvite(tenant uuid.UUID, user UserProfileDTO, code string) (bool, error)
+}
+
+type OrganizationManager interface {
+	CreateOrganization(org Organization, admin UserProfileDTO) error
+	GetOrganizationBySlug(slug string) (Organization, error)
+	GetOrganizations() ([]Organization, error)
+}
+
+type DomainInterface interface {
+	OrganizationManager
+	UserProfileManager
+	ExportManager
+	StatsManager
+	FollowManager
+	BlockManager
+	PreferencesManager
+	GroupManager
+	InviteManager
+	AuditManager
+}
+
+type CacheInterface interface {
+	Set(tenant uuid.UUID, key string, value interface{}) error
+	GetUser(tenant uuid.UUID, key string) (UserProfileDTO, error)
+	GetUsersList(tenant uuid.UUID, key string) (Pagination[UserProfileDTO], error)
+	GetLeaderboard(tenant uuid.UUID, key string) (Leaderboard, error)
+	SetWithTTL(tenant uuid.UUID, key string, value interface{}, ttl time.Duration) error
+	MakeKey(tenant uuid.UUID, page PageQuery, filter UsersFilter) (string, error)
+	Delete(tenant uuid.UUID, key string) error
+	InvalidateLists(tenant uuid.UUID) error
+}
+
+// Organization is a tenant, users, their votes and everything related to them belong to exactly one organization.
+type Organization struct {
+	ID        uuid.UUID `json:"id"`
+	Slug      string    `json:"slug"`
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// CreateOrganizationReq creates an organization together with its first admin.
+type CreateOrganizationReq struct {
+	Slug  string        `json:"slug"`
+	Name  string        `json:"name"`
+	Admin CreateUserReq `json:"admin"`
+}
+
+// GroupDTO is a group of users within an organization, Role is the role of the user whose groups are listed.
+type GroupDTO struct {
+	OID          uuid.UUID `json:"oid"`
+	Name         string    `json:"name"`
+	Description  string    `json:"description"`
+	MembersCount int       `json:"members_count"`
+	Role         GroupRole `json:"role,omitempty"`
+	CreatedAt    time.Time `json:"created_at"`
+	UpdatedAt    time.Time `json:"updated_at"`
+}
+
+type GroupReq struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type GroupMemberReq struct {
+	UserOID uuid.UUID `json:"user_oid"`
+	Role    GroupRole `json:"role"`
+}
+
+type GroupMemberDTO struct {
+	User     UserProfileDTO `json:"user"`
+	Role     GroupRole      `json:"role"`
+	JoinedAt time.Time      `json:"joined_at"`
+}
+
+type GroupsPage struct {
+	TotalItems  int        `json:"total_items"`
+	CurrentPage int        `json:"current_page"`
+	Groups      []GroupDTO `json:"groups"`
+}
+
+type GroupMembersPage struct {
+	TotalItems  int              `json:"total_items"`
+	CurrentPage int              `json:"current_page"`
+	Members     []GroupMemberDTO `json:"members"`
+}
+
+// AuditRecord tells who changed a profile, a group or an invite and how, Before and After hold only the fields that changed.
+type AuditRecord struct {
+	ID        int64                  `json:"id"`
+	ActorOID  uuid.UUID              `json:"actor_oid"`
+	TargetOID uuid.UUID              `json:"target_oid"`
+	Action    AuditAction            `json:"action"`
+	Before    map[string]interface{} `json:"before,omitempty"`
+	After     map[string]interface{} `json:"after,omitempty"`
+	IP        string                 `json:"ip,omitempty"`
+	RequestID string                 `json:"request_id,omitempty"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// SecretAuditFields are never written to the audit log as is, only the fact that they changed.
+var SecretAuditFields = map[string]bool{
+	"password":    true,
+	"invite_code": true,
+}
+
+const AuditRedacted = "[redacted]"
+
+// RedactSecrets replaces values of SecretAuditFields in fields.
+func RedactSecrets(fields map[string]interface{}) {
+	for field := range SecretAuditFields {
+		if _, ok := fields[field]; ok {
+			fields[field] = AuditRedacted
+		}
+	}
+}
+
+// AuditSource tells who made a change and which request it came with.
+type AuditSource struct {
+	Actor     uuid.UUID
+	IP        string
+	RequestID string
+}
+
+// AuditFilter narrows down audit records, empty fields match any record. To is exclusive.
+type AuditFilter struct {
+	Actor  *uuid.UUID
+	Target *uuid.UUID
+	Action AuditAction
+	From   *time.Time
+	To     *time.Time
+}
+
+type AuditPage struct {
+	TotalItems  int           `json:"total_items"`
+	CurrentPage int           `json:"current_page"`
+	Records     []AuditRecord `json:"records"`
+}
+
+// InviteDTO is an invite code that can be used to register MaxUses times until ExpiresAt,
+// users registered with it get Role and become members of GroupOID.
+type InviteDTO struct {
+	Code      string     `json:"code"`
+	CreatedBy *uuid.UUID `json:"created_by,omitempty"`
+	Role      Role       `json:"user_role"`
+	GroupOID  *uuid.UUID `json:"group_oid,omitempty"`
+	MaxUses   int        `json:"max_uses"`
+	Uses      int        `json:"uses"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+type CreateInviteReq struct {
+	Role           Role       `json:"user_role,omitempty"`
+	GroupOID       *uuid.UUID `json:"group_oid,omitempty"`
+	MaxUses        int        `json:"max_uses,omitempty"`
+	ExpiresInHours int        `json:"expires_in_hours,omitempty"`
+}
+
+type InvitesPage struct {
+	TotalItems  int         `json:"total_items"`
+	CurrentPage int         `json:"current_page"`
+	Invites     []InviteDTO `json:"invites"`
+}
+
+type UserProfileDTO struct {
+	OID       uuid.UUID `json:"oid"`
+	Nickname  string    `json:"nickname"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	Password  string    `json:"password"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	State     State     `json:"state"`
+	Role      Role      `json:"user_role"`
+	Rating    int       `json:"rating"`
+
+	Attributes           map[string]interface{} `json:"attributes,omitempty"`
+	AttributesVisibility map[string]Visibility  `json:"attributes_visibility,omitempty"`
+
+	// Privacy is kept in cache along with the rest of the profile, but never rendered to other users
+	Privacy *PrivacySettings `json:"privacy,omitempty"`
+
+	// InviteCode is only read on registration
+	InviteCode string `json:"invite_code,omitempty"`
+
+	NicknameNormalized string `json:"-"`
+	NicknameSkeleton   string `json:"-"`
+}
+
+// PrivacySettings control who can see a profile and its parts. Unlisted profiles are left out of lists and search,
+// hidden profiles are additionally visible only to their owners and moderators.
+type PrivacySettings struct {
+	ProfileVisibility   ProfileVisibility `json:"profile_visibility"`
+	RealNameVisibility  NameVisibility    `json:"real_name_visibility"`
+	HideRatingBreakdown bool              `json:"hide_rating_breakdown"`
+}
+
+type GetProfileDTO struct {
+	OID       uuid.UUID `json:"oid"`
+	Nickname  string    `json:"nickname"`
+	FirstName string    `json:"first_name"`
+	LastName  string    `json:"last_name"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+	State     State     `json:"state"`
+	Role      Role      `json:"user_role"`
+	Rating    Rating    `json:"rating"`
+
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+
+	Attributes map[string]interface{} `json:"attributes,omitempty"`
+}
+
+// GetProfileV1DTO is the profile of API version 1, where the rating is a string.
+type GetProfileV1DTO struct {
+	GetProfileDTO
+	Rating string `json:"rating"`
+}
+
+// Rating is the amount of votes received by a user, Emojis is empty if the breakdown is hidden.
+type Rating struct {
+	Total  int           `json:"total"`
+	Emojis []EmojiRating `json:"emojis,omitempty"`
+}
+
+// EmojiRating is the amount of votes of a single emoji, Share is its part of the total from 0 to 1.
+type EmojiRating struct {
+	EmojiID int32   `json:"emoji_id"`
+	Emoji   string  `json:"emoji"`
+	Count   int     `json:"count"`
+	Share   float64 `json:"share"`
+}
+
+// String renders the rating the way API version 1 does: the total if the breakdown is hidden, otherwise "❤️:3; 😂:1; ".
+func (r Rating) String() string {
+	if len(r.Emojis) == 0 {
+		return fmt.Sprint(r.Total)
+	}
+	var builder strings.Builder
+	for _, emoji := range r.Emojis {
+		builder.WriteString(fmt.Sprintf("%s:%d; ", emoji.Emoji, emoji.Count))
+	}
+	return builder.String()
+}
+
+type RatingInterval string
+
+const (
+	IntervalHour  RatingInterval = "hour"
+	IntervalDay   RatingInterval = "day"
+	IntervalWeek  RatingInterval = "week"
+	IntervalMonth RatingInterval = "month"
+)
+
+func (i RatingInterval) Valid() bool {
+	switch i {
+	case IntervalHour, IntervalDay, IntervalWeek, IntervalMonth:
+		return true
+	}
+	return false
+}
+
+// Start returns the start of the interval t belongs to in the location of t, weeks start on Monday.
+func (i RatingInterval) Start(t time.Time) time.Time {
+	year, month, day := t.Date()
+	switch i {
+	case IntervalHour:
+		return time.Date(year, month, day, t.Hour(), 0, 0, 0, t.Location())
+	case IntervalWeek:
+		return time.Date(year, month, day-(int(t.Weekday())+6)%7, 0, 0, 0, 0, t.Location())
+	case IntervalMonth:
+		return time.Date(year, month, 1, 0, 0, 0, 0, t.Location())
+	}
+	return time.Date(year, month, day, 0, 0, 0, 0, t.Location())
+}
+
+// Next returns the start of the interval following the one starting at start.
+func (i RatingInterval) Next(start time.Time) time.Time {
+	switch i {
+	case IntervalHour:
+		return start.Add(time.Hour)
+	case IntervalWeek:
+		return start.AddDate(0, 0, 7)
+	case IntervalMonth:
+		return start.AddDate(0, 1, 0)
+	}
+	return start.AddDate(0, 0, 1)
+}
+
+// RatingBucket is the rating received within the interval starting at Start.
+type RatingBucket struct {
+	Start time.Time `json:"start"`
+	Rating
+}
+
+type RatingHistory struct {
+	Interval RatingInterval `json:"interval"`
+	Timezone string         `json:"timezone"`
+	From     time.Time      `json:"from"`
+	To       time.Time      `json:"to"`
+	Buckets  []RatingBucket `json:"buckets"`
+}
+
+// RatedUser is the amount of votes received by a user.
+type RatedUser struct {
+	OID   uuid.UUID
+	Votes int
+}
+
+type LeaderboardWindow string
+
+const (
+	WindowDay   LeaderboardWindow = "day"
+	WindowWeek  LeaderboardWindow = "week"
+	WindowMonth LeaderboardWindow = "month"
+	WindowAll   LeaderboardWindow = "all"
+)
+
+// LeaderboardQuery picks users with the most votes of the emoji, or of any emoji if EmojiID is 0,
+// cast or changed within the window.
+type LeaderboardQuery struct {
+	EmojiID int32             `json:"emoji_id,omitempty"`
+	Window  LeaderboardWindow `json:"window"`
+	Limit   int               `json:"limit"`
+}
+
+type LeaderboardEntry struct {
+	Place int            `json:"place"`
+	Votes int            `json:"votes"`
+	User  UserProfileDTO `json:"user"`
+}
+
+type Leaderboard struct {
+	LeaderboardQuery
+	Entries   []LeaderboardEntry `json:"entries"`
+	UpdatedAt time.Time          `json:"updated_at"`
+}
+
+type VoteDTO struct {
+	FromOID uuid.UUID `json:"from_oid"`
+	ToOID   uuid.UUID `json:"oid"`
+	EmojiId int32     `json:"emoji"`
+	VotedAt time.Time `json:"voted_at"`
+}
+
+// Preferences are personal settings of a user. DateFormat is applied by clients, Locale and Timezone are used by the API as well.
+type Preferences struct {
+	Locale        string               `json:"locale"`
+	Timezone      string               `json:"timezone"`
+	DateFormat    DateFormat           `json:"date_format"`
+	Notifications NotificationSettings `json:"notifications"`
+}
+
+type NotificationSettings struct {
+	Votes      bool `json:"votes"`
+	Follows    bool `json:"follows"`
+	Newsletter bool `json:"newsletter"`
+}
+
+// FollowRelationDTO describes follows between OID and OtherOID, Follows is true if OID follows OtherOID.
+type FollowRelationDTO struct {
+	OID        uuid.UUID `json:"oid"`
+	OtherOID   uuid.UUID `json:"other_oid"`
+	Follows    bool      `json:"follows"`
+	FollowedBy bool      `json:"followed_by"`
+	Mutual     bool      `json:"mutual"`
+}
+
+type NicknameDTO struct {
+	OID       uuid.UUID  `json:"oid"`
+	Nickname  string     `json:"nickname"`
+	Former    bool       `json:"former"`
+	ChangedAt *time.Time `json:"changed_at,omitempty"`
+
+	// Visibility is the profile visibility of the user, hidden profiles are resolved only for their owners and moderators
+	Visibility ProfileVisibility `json:"-"`
+}
+
+type SearchResultDTO struct {
+	UserProfileDTO
+	Rank       float32           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
+}
+
+type NicknameChangeDTO struct {
+	OldNickname string    `json:"old_nickname"`
+	NewNickname string    `json:"new_nickname"`
+	ChangedAt   time.Time `json:"changed_at"`
+}
+
+type ExportDTO struct {
+	OID         uuid.UUID    `json:"oid"`
+	UserOID     uuid.UUID    `json:"user_oid"`
+	RequestedBy uuid.UUID    `json:"requested_by"`
+	Status      ExportStatus `json:"status"`
+	FilePath    string       `json:"-"`
+	CreatedAt   time.Time    `json:"created_at"`
+	CompletedAt *time.Time   `json:"completed_at,omitempty"`
+	ExpiresAt   *time.Time   `json:"expires_at,omitempty"`
+	DownloadURL string       `json:"download_url,omitempty"`
+}
+
+// ImportUserReq is a single record of a bulk import, it has either a plain Password or a bcrypt PasswordHash.
+type ImportUserReq struct {
+	Nickname     string `json:"nickname"`
+	FirstName    string `json:"first_name"`
+	LastName     string `json:"last_name"`
+	Password     string `json:"password,omitempty"`
+	PasswordHash string `json:"password_hash,omitempty"`
+	Role         Role   `json:"user_role,omitempty"`
+}
+
+type ImportRowResult struct {
+	Row      int          `json:"row"`
+	Nickname string       `json:"nickname"`
+	OID      *uuid.UUID   `json:"oid,omitempty"`
+	Status   ImportStatus `json:"status"`
+	Error    string       `json:"error,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun  bool              `json:"dry_run"`
+	Created int               `json:"created"`
+	Skipped int               `json:"skipped"`
+	Failed  int               `json:"failed"`
+	Rows    []ImportRowResult `json:"rows"`
+	// Error tells why the input couldn't be read to the end, rows created before that are kept.
+	Error string `json:"error,omitempty"`
+}
+
+// BatchReq targets either a list of users or users matching a filter. Chunked batches are applied
+// chunk by chunk, each in its own transaction, otherwise the whole batch is a single transaction.
+type BatchReq struct {
+	Action  BatchAction  `json:"action"`
+	Role    Role         `json:"role,omitempty"`
+	OIDs    []uuid.UUID  `json:"oids,omitempty"`
+	Filter  *UsersFilter `json:"filter,omitempty"`
+	Chunked bool         `json:"chunked,omitempty"`
+}
+
+type BatchSkippedDTO struct {
+	OID    uuid.UUID `json:"oid"`
+	Reason string    `json:"reason"`
+}
+
+type BatchChunkDTO struct {
+	Chunk    int    `json:"chunk"`
+	Users    int    `json:"users"`
+	Affected int    `json:"affected"`
+	Error    string `json:"error,omitempty"`
+}
+
+type BatchResultDTO struct {
+	Action   BatchAction       `json:"action"`
+	Matched  int               `json:"matched"`
+	Affected []uuid.UUID       `json:"affected"`
+	Skipped  []BatchSkippedDTO `json:"skipped"`
+	Chunks   []BatchChunkDTO   `json:"chunks"`
+}
+
+type UsersFilter struct {
+	Attributes  map[string]interface{} `json:"attributes,omitempty"`
+	State       *State                 `json:"state,omitempty"`
+	Role        *Role                  `json:"role,omitempty"`
+	CreatedFrom *time.Time             `json:"created_from,omitempty"`
+	CreatedTo   *time.Time             `json:"created_to,omitempty"`
+	UpdatedFrom *time.Time             `json:"updated_from,omitempty"`
+	UpdatedTo   *time.Time             `json:"updated_to,omitempty"`
+	SortBy      string                 `json:"sort,omitempty"`
+	Desc        bool                   `json:"desc,omitempty"`
+
+	// Listed leaves out profiles that are unlisted or hidden by their owners
+	Listed bool `json:"listed,omitempty"`
+}
+
+// Cursor points at a user in the users list ordered by creation time, oid orders users created at the same time.
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	OID       uuid.UUID `json:"o"`
+}
+
+// PageQuery describes the requested page of a list, either by offset or, in keyset mode, relative to a cursor.
+// Keyset mode without a cursor starts from the first page, or from the last one when going backward.
+type PageQuery struct {
+	Size         int     `json:"size"`
+	Offset       int     `json:"offset"`
+	Keyset       bool    `json:"keyset,omitempty"`
+	Cursor       *Cursor `json:"cursor,omitempty"`
+	Backward     bool    `json:"backward,omitempty"`
+	IncludeTotal bool    `json:"include_total,omitempty"`
+}
+
+type Pagination[T any] struct {
+	TotalItems  *int   `json:"total_items,omitempty"`
+	CurrentPage int    `json:"current_page,omitempty"`
+	Next        string `json:"next,omitempty"`
+	Prev        string `json:"prev,omitempty"`
+	Users       []T    `json:"users"`
+}
@@ -0,0 +1,272 @@
+package export
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+const (
+	cleanupInterval = time.Hour
+	// readPageSize is the amount of audit records and follows read at once while building an archive.
+	readPageSize = 1000
+)
+
+// Exporter builds ZIP archives with everything that is stored about a user.
+type Exporter struct {
+	Orgs        domain.OrganizationManager
+	DB          domain.UserProfileManager
+	Exports     domain.ExportManager
+	Rating      domain.StatsManager
+	Audit       domain.AuditManager
+	Follows     domain.FollowManager
+	Blocks      domain.BlockManager
+	Preferences domain.PreferencesManager
+	dir         string
+	retention   time.Duration
+}
+
+func NewExporter(cfg *config.Config, orgs domain.OrganizationManager, db domain.UserProfileManager, exports domain.ExportManager, rating domain.StatsManager,
+	audit domain.AuditManager, follows domain.FollowManager, blocks domain.BlockManager, preferences domain.PreferencesManager) *Exporter {
+	return &Exporter{
+		Orgs:        orgs,
+		DB:          db,
+		Exports:     exports,
+		Rating:      rating,
+		Audit:       audit,
+		Follows:     follows,
+		Blocks:      blocks,
+		Preferences: preferences,
+		dir:         cfg.Export.Dir,
+		retention:   time.Duration(cfg.Export.RetentionHours) * time.Hour,
+	}
+}
+
+// relation is a user on the other end of a follow, only the oid and the nickname are exported
+// as the rest of their profile belongs to them.
+type relation struct {
+	OID      uuid.UUID `json:"oid"`
+	Nickname string    `json:"nickname"`
+}
+
+// Request registers a new export of userOID in tenant and starts building it in the background.
+func (e *Exporter) Request(tenant uuid.UUID, userOID uuid.UUID, requestedBy uuid.UUID) (domain.ExportDTO, error) {
+	now := time.Now().UTC()
+	expiresAt := now.Add(e.retention)
+	export := domain.ExportDTO{
+		OID:         uuid.New(),
+		UserOID:     userOID,
+		RequestedBy: requestedBy,
+		Status:      domain.ExportPending,
+		CreatedAt:   now,
+		ExpiresAt:   &expiresAt,
+	}
+
+	if err := e.Exports.CreateExport(tenant, export); err != nil {
+		return domain.ExportDTO{}, err
+	}
+
+	go e.build(tenant, export)
+
+	return export, nil
+}
+
+func (e *Exporter) build(tenant uuid.UUID, export domain.ExportDTO) {
+	path := filepath.Join(e.dir, export.OID.String()+".zip")
+
+	err := e.writeArchive(path, tenant, export.UserOID)
+	if err != nil {
+		log.Warnf("Export: unable to build export %s: %s", export.OID, err)
+		os.Remove(path)
+		export.Status = domain.ExportFailed
+	} else {
+		export.Status = domain.ExportReady
+		export.FilePath = path
+	}
+
+	completedAt := time.Now().UTC()
+	expiresAt := completedAt.Add(e.retention)
+	export.CompletedAt = &completedAt
+	export.ExpiresAt = &expiresAt
+
+	if err := e.Exports.UpdateExport(tenant, export); err != nil {
+		log.Warnf("Export: %s", err)
+		return
+	}
+	log.Infof("Export %s of user with oid %s finished with status %s", export.OID, export.UserOID, export.Status)
+}
+
+func (e *Exporter) writeArchive(path string, tenant uuid.UUID, oid uuid.UUID) error {
+	user, err := e.DB.GetUserById(tenant, oid)
+	if err != nil {
+		return err
+	}
+	nicknames, err := e.DB.GetNicknameHistory(tenant, oid)
+	if err != nil {
+		return err
+	}
+	votesGiven, err := e.Rating.GetVotesGiven(tenant, oid)
+	if err != nil {
+		return err
+	}
+	votesReceived, err := e.Rating.GetVotesReceived(tenant, oid)
+	if err != nil {
+		return err
+	}
+	audit, err := e.readAudit(tenant, oid)
+	if err != nil {
+		return err
+	}
+	followers, err := e.readFollows(tenant, oid, domain.FollowersList)
+	if err != nil {
+		return err
+	}
+	following, err := e.readFollows(tenant, oid, domain.FollowingList)
+	if err != nil {
+		return err
+	}
+	blocked, err := e.Blocks.GetBlockedOIDs(tenant, oid)
+	if err != nil {
+		return err
+	}
+	preferences, _, err := e.Preferences.GetPreferences(tenant, oid)
+	if err != nil {
+		return err
+	}
+	user.OID = oid
+
+	if err := os.MkdirAll(e.dir, 0o700); err != nil {
+		return fmt.Errorf("unable to create export directory: %w", err)
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0o600)
+	if err != nil {
+		return fmt.Errorf("unable to create export file: %w", err)
+	}
+	defer file.Close()
+
+	archive := zip.NewWriter(file)
+	parts := []struct {
+		name    string
+		content interface{}
+	}{
+		{"profile.json", user},
+		{"nickname_history.json", nicknames},
+		{"votes_given.json", votesGiven},
+		{"votes_received.json", votesReceived},
+		{"audit.json", audit},
+		{"followers.json", followers},
+		{"following.json", following},
+		{"blocked.json", blocked},
+		{"preferences.json", preferences},
+	}
+	for _, part := range parts {
+		if err := writeJSON(archive, part.name, part.content); err != nil {
+			return err
+		}
+	}
+
+	if err := archive.Close(); err != nil {
+		return fmt.Errorf("unable to write export archive: %w", err)
+	}
+	return file.Close()
+}
+
+// readAudit returns all audit records of changes made to the user, most recent first.
+// Names are decrypted by the audit manager, secrets are redacted once more in case a record kept them.
+func (e *Exporter) readAudit(tenant uuid.UUID, oid uuid.UUID) ([]domain.AuditRecord, error) {
+	filter := domain.AuditFilter{Target: &oid}
+	records := []domain.AuditRecord{}
+	for offset := 0; ; offset += readPageSize {
+		page, err := e.Audit.GetAudit(tenant, filter, readPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range page {
+			domain.RedactSecrets(record.Before)
+			domain.RedactSecrets(record.After)
+			records = append(records, record)
+		}
+		if len(page) < readPageSize {
+			return records, nil
+		}
+	}
+}
+
+// readFollows returns all users of the follow list of the user, including hidden profiles.
+func (e *Exporter) readFollows(tenant uuid.UUID, oid uuid.UUID, list domain.FollowList) ([]relation, error) {
+	relations := []relation{}
+	for offset := 0; ; offset += readPageSize {
+		page, err := e.Follows.GetFollows(tenant, oid, list, true, readPageSize, offset)
+		if err != nil {
+			return nil, err
+		}
+		for _, user := range page {
+			relations = append(relations, relation{OID: user.OID, Nickname: user.Nickname})
+		}
+		if len(page) < readPageSize {
+			return relations, nil
+		}
+	}
+}
+
+func writeJSON(archive *zip.Writer, name string, content interface{}) error {
+	w, err := archive.Create(name)
+	if err != nil {
+		return fmt.Errorf("unable to add %s to export archive: %w", name, err)
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(content); err != nil {
+		return fmt.Errorf("unable to write %s to export archive: %w", name, err)
+	}
+	return nil
+}
+
+// Run periodically removes expired exports and their archives.
+func (e *Exporter) Run() {
+	log.Info("Export cleanup started")
+	for {
+		e.cleanup()
+		time.Sleep(cleanupInterval)
+	}
+}
+
+func (e *Exporter) cleanup() {
+	orgs, err := e.Orgs.GetOrganizations()
+	if err != nil {
+		log.Warnf("Export cleanup: %s", err)
+		return
+	}
+
+	for _, org := range orgs {
+		e.cleanupTenant(org.ID)
+	}
+}
+
+func (e *Exporter) cleanupTenant(tenant uuid.UUID) {
+	exports, err := e.Exports.GetExpiredExports(tenant, time.Now().UTC())
+	if err != nil {
+		log.Warnf("Export cleanup: %s", err)
+		return
+	}
+
+	for _, export := range exports {
+		if export.FilePath != "" {
+			if err := os.Remove(export.FilePath); err != nil && !os.IsNotExist(err) {
+				log.Warnf("Export cleanup: unable to remove %s: %s", export.FilePath, err)
+				continue
+			}
+		}
+		if err := e.Exports.DeleteExport(tenant, export.OID); err != nil {
+			log.Warnf("Export cleanup: %s", err)
+		}
+	}
+}
@@ -0,0 +1,182 @@
+package export
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+const (
+	FormatCSV     = "csv"
+	FormatNDJSON  = "ndjson"
+	FormatParquet = "parquet"
+)
+
+var usersCSVHeader = []string{"oid", "nickname", "first_name", "last_name", "created_at", "updated_at", "state", "user_role", "rating", "attributes"}
+
+// UserRecord is a row of users export. It is flat, so that it maps to CSV columns and Parquet fields the same way.
+type UserRecord struct {
+	OID        string          `json:"oid" parquet:"oid"`
+	Nickname   string          `json:"nickname" parquet:"nickname"`
+	FirstName  string          `json:"first_name" parquet:"first_name"`
+	LastName   string          `json:"last_name" parquet:"last_name"`
+	CreatedAt  time.Time       `json:"created_at" parquet:"created_at,timestamp(microsecond)"`
+	UpdatedAt  time.Time       `json:"updated_at" parquet:"updated_at,timestamp(microsecond)"`
+	State      int32           `json:"state" parquet:"state"`
+	Role       int32           `json:"user_role" parquet:"user_role"`
+	Rating     int64           `json:"rating" parquet:"rating"`
+	Attributes json.RawMessage `json:"attributes" parquet:"attributes,json"`
+}
+
+func newUserRecord(user domain.UserProfileDTO) (UserRecord, error) {
+	attributes, err := json.Marshal(user.Attributes)
+	if err != nil {
+		return UserRecord{}, fmt.Errorf("unable to encode attributes of user %s: %w", user.OID, err)
+	}
+	return UserRecord{
+		OID:        user.OID.String(),
+		Nickname:   user.Nickname,
+		FirstName:  user.FirstName,
+		LastName:   user.LastName,
+		CreatedAt:  user.CreatedAt.UTC(),
+		UpdatedAt:  user.UpdatedAt.UTC(),
+		State:      int32(user.State),
+		Role:       int32(user.Role),
+		Rating:     int64(user.Rating),
+		Attributes: attributes,
+	}, nil
+}
+
+// UsersWriter writes users export chunk by chunk, Close should be called once all users are written.
+// It doesn't close the underlying writer.
+type UsersWriter interface {
+	Write(users []domain.UserProfileDTO) error
+	Close() error
+}
+
+func NewUsersWriter(w io.Writer, format string) (UsersWriter, error) {
+	switch format {
+	case FormatCSV:
+		return &csvUsersWriter{w: csv.NewWriter(w)}, nil
+	case FormatNDJSON:
+		return &ndjsonUsersWriter{encoder: json.NewEncoder(w)}, nil
+	case FormatParquet:
+		return &parquetUsersWriter{w: parquet.NewGenericWriter[UserRecord](w)}, nil
+	}
+	return nil, fmt.Errorf("wrong format %q, should be one of: csv, ndjson, parquet", format)
+}
+
+// ContentType returns the media type of an uncompressed users export in format.
+func ContentType(format string) string {
+	switch format {
+	case FormatCSV:
+		return "text/csv"
+	case FormatNDJSON:
+		return "application/x-ndjson"
+	case FormatParquet:
+		return "application/vnd.apache.parquet"
+	}
+	return "application/octet-stream"
+}
+
+type csvUsersWriter struct {
+	w             *csv.Writer
+	headerWritten bool
+}
+
+func (c *csvUsersWriter) Write(users []domain.UserProfileDTO) error {
+	if !c.headerWritten {
+		if err := c.w.Write(usersCSVHeader); err != nil {
+			return fmt.Errorf("unable to write CSV header: %w", err)
+		}
+		c.headerWritten = true
+	}
+
+	for _, user := range users {
+		record, err := newUserRecord(user)
+		if err != nil {
+			return err
+		}
+		err = c.w.Write([]string{
+			record.OID,
+			record.Nickname,
+			record.FirstName,
+			record.LastName,
+			record.CreatedAt.Format(time.RFC3339Nano),
+			record.UpdatedAt.Format(time.RFC3339Nano),
+			strconv.Itoa(int(record.State)),
+			strconv.Itoa(int(record.Role)),
+			strconv.FormatInt(record.Rating, 10),
+			string(record.Attributes),
+		})
+		if err != nil {
+			return fmt.Errorf("unable to write CSV row: %w", err)
+		}
+	}
+
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvUsersWriter) Close() error {
+	// header is written even if there are no users, so that the file is still a valid CSV
+	return c.Write(nil)
+}
+
+type ndjsonUsersWriter struct {
+	encoder *json.Encoder
+}
+
+func (n *ndjsonUsersWriter) Write(users []domain.UserProfileDTO) error {
+	for _, user := range users {
+		record, err := newUserRecord(user)
+		if err != nil {
+			return err
+		}
+		if err := n.encoder.Encode(record); err != nil {
+			return fmt.Errorf("unable to write NDJSON row: %w", err)
+		}
+	}
+	return nil
+}
+
+func (n *ndjsonUsersWriter) Close() error {
+	return nil
+}
+
+// parquetUsersWriter writes every chunk as a separate row group, so that only a single chunk is kept in memory.
+type parquetUsersWriter struct {
+	w *parquet.GenericWriter[UserRecord]
+}
+
+func (p *parquetUsersWriter) Write(users []domain.UserProfileDTO) error {
+	records := make([]UserRecord, 0, len(users))
+	for _, user := range users {
+		record, err := newUserRecord(user)
+		if err != nil {
+			return err
+		}
+		records = append(records, record)
+	}
+
+	if _, err := p.w.Write(records); err != nil {
+		return fmt.Errorf("unable to write Parquet rows: %w", err)
+	}
+	if err := p.w.Flush(); err != nil {
+		return fmt.Errorf("unable to write Parquet row group: %w", err)
+	}
+	return nil
+}
+
+func (p *parquetUsersWriter) Close() error {
+	if err := p.w.Close(); err != nil {
+		return fmt.Errorf("unable to write Parquet footer: %w", err)
+	}
+	return nil
+}
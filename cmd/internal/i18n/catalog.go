@@ -0,0 +1,257 @@
+package i18n
+
+// catalog maps error messages to their translations by locale.
+var catalog = map[string]map[string]string{
+	"uk": {
+		"Invalid request payload":                     "Некоректний запит",
+		"Wrong UserId":                                "Некоректний ідентифікатор користувача",
+		"User not found":                              "Користувача не знайдено",
+		"Wrong GroupId":                               "Некоректний ідентифікатор групи",
+		"Group not found":                             "Групу не знайдено",
+		"Registration is closed":                      "Реєстрацію закрито",
+		"Invite code is required":                     "Потрібен код запрошення",
+		"Invite code is invalid or expired":           "Код запрошення недійсний або прострочений",
+		"Wrong window":                                "Некоректний період",
+		"Failed to get leaderboard":                   "Не вдалося отримати рейтинг лідерів",
+		"Wrong interval":                              "Некоректний інтервал",
+		"Wrong timezone":                              "Некоректний часовий пояс",
+		"Failed to get rating history":                "Не вдалося отримати історію рейтингу",
+		"Nickname not found":                          "Нікнейм не знайдено",
+		"Missing token":                               "Відсутній токен",
+		"Invalid token":                               "Недійсний токен",
+		"Your profile is banned or deleted":           "Ваш профіль заблоковано або видалено",
+		"Error with authentication, please re-login.": "Помилка автентифікації, будь ласка, увійдіть знову.",
+		"User is not permitted to change other profiles except his own.": "Користувач може змінювати лише власний профіль.",
+		"Failed to create user profile":                                  "Не вдалося створити профіль",
+		"Failed to update user profile":                                  "Не вдалося оновити профіль",
+		"Failed to get user profile":                                     "Не вдалося отримати профіль",
+		"Failed to get users list":                                       "Не вдалося отримати список користувачів",
+		"Failed to search users":                                         "Не вдалося виконати пошук користувачів",
+		"Failed to log in":                                               "Не вдалося увійти",
+		"Empty search query":                                             "Порожній пошуковий запит",
+		"Wrong value":                                                    "Некоректне значення",
+		"You can't rate yourself":                                        "Ви не можете оцінювати себе",
+		"You already rated this user":                                    "Ви вже оцінили цього користувача",
+		"You already voted in last hour, users can vote only one time per hour": "Ви вже голосували протягом останньої години, голосувати можна лише раз на годину",
+		"Vote is the same as before":                                     "Голос не відрізняється від попереднього",
+		"You are not permitted to rate this user":                        "Ви не можете оцінювати цього користувача",
+		"You can't follow yourself":                                      "Ви не можете підписатися на себе",
+		"You can't block yourself":                                       "Ви не можете заблокувати себе",
+		"Failed to get preferences":                                      "Не вдалося отримати налаштування",
+		"Failed to update preferences":                                   "Не вдалося оновити налаштування",
+		"You are not permitted to view preferences of other users.":      "Ви не можете переглядати налаштування інших користувачів.",
+		"You are not permitted to update preferences of other users.":    "Ви не можете змінювати налаштування інших користувачів.",
+		"Cursor pagination is supported only when sorting by created_at": "Пагінація курсором підтримується лише при сортуванні за created_at",
+		"Either oids or filter should be set":                            "Потрібно вказати oids або фільтр",
+		"Error happaned, unable to delete profile":                       "Сталася помилка, не вдалося видалити профіль",
+		"Export is not available":                                        "Експорт недоступний",
+		"Failed to add group member":                                     "Не вдалося додати учасника групи",
+		"Failed to apply batch action":                                   "Не вдалося виконати пакетну дію",
+		"Failed to block user":                                           "Не вдалося заблокувати користувача",
+		"Failed to create group":                                         "Не вдалося створити групу",
+		"Failed to create invite":                                        "Не вдалося створити запрошення",
+		"Failed to create organization":                                  "Не вдалося створити організацію",
+		"Failed to delete group":                                         "Не вдалося видалити групу",
+		"Failed to export users":                                         "Не вдалося експортувати користувачів",
+		"Failed to follow user":                                          "Не вдалося підписатися на користувача",
+		"Failed to get attributes schema":                                "Не вдалося отримати схему атрибутів",
+		"Failed to get audit log":                                        "Не вдалося отримати журнал аудиту",
+		"Failed to get blocked users":                                    "Не вдалося отримати заблокованих користувачів",
+		"Failed to get follow relationship":                              "Не вдалося отримати зв'язок підписки",
+		"Failed to get group":                                            "Не вдалося отримати групу",
+		"Failed to get group members":                                    "Не вдалося отримати учасників групи",
+		"Failed to get groups":                                           "Не вдалося отримати групи",
+		"Failed to get invites":                                          "Не вдалося отримати запрошення",
+		"Failed to get organizations":                                    "Не вдалося отримати організації",
+		"Failed to get privacy settings":                                 "Не вдалося отримати налаштування приватності",
+		"Failed to get user attributes":                                  "Не вдалося отримати атрибути користувача",
+		"Failed to get user groups":                                      "Не вдалося отримати групи користувача",
+		"Failed to get users amount":                                     "Не вдалося отримати кількість користувачів",
+		"Failed to remove group member":                                  "Не вдалося видалити учасника групи",
+		"Failed to resolve nickname":                                     "Не вдалося знайти нікнейм",
+		"Failed to resolve organization":                                 "Не вдалося визначити організацію",
+		"Failed to revoke invite":                                        "Не вдалося відкликати запрошення",
+		"Failed to unblock user":                                         "Не вдалося розблокувати користувача",
+		"Failed to unfollow user":                                        "Не вдалося відписатися від користувача",
+		"Failed to update attributes schema":                             "Не вдалося оновити схему атрибутів",
+		"Failed to update group":                                         "Не вдалося оновити групу",
+		"Failed to update privacy settings":                              "Не вдалося оновити налаштування приватності",
+		"Failed to update user attributes":                               "Не вдалося оновити атрибути користувача",
+		"Filter should have at least one condition":                      "Фільтр повинен містити хоча б одну умову",
+		"Group already exists":                                           "Група вже існує",
+		"Group should have an owner":                                     "Група повинна мати власника",
+		"Invalid or expired download link":                               "Посилання для завантаження недійсне або прострочене",
+		"Invite not found":                                               "Запрошення не знайдено",
+		"Max uses and expiration should be positive":                     "Кількість використань і термін дії повинні бути додатними",
+		"Name is required":                                               "Потрібна назва",
+		"Only admins and moderators are permitted to run batch actions.": "Лише адміністратори та модератори можуть виконувати пакетні дії.",
+		"Only admins are permitted to change roles.":                     "Лише адміністратори можуть змінювати ролі.",
+		"Only admins are permitted to export users.":                     "Лише адміністратори можуть експортувати користувачів.",
+		"Only admins are permitted to import users.":                     "Лише адміністратори можуть імпортувати користувачів.",
+		"Only admins are permitted to invite moderators and admins.":     "Лише адміністратори можуть запрошувати модераторів та адміністраторів.",
+		"Only admins are permitted to manage attributes schema.":         "Лише адміністратори можуть керувати схемою атрибутів.",
+		"Only admins are permitted to restore profiles.":                 "Лише адміністратори можуть відновлювати профілі.",
+		"Only admins are permitted to view the audit log.":               "Лише адміністратори можуть переглядати журнал аудиту.",
+		"Only admins of the default organization are permitted to create organizations.":                      "Лише адміністратори організації за замовчуванням можуть створювати організації.",
+		"Only admins of the default organization are permitted to list organizations.":                        "Лише адміністратори організації за замовчуванням можуть переглядати організації.",
+		"Only owners of the group are permitted to change it.":                                                "Лише власники групи можуть її змінювати.",
+		"Only owners of the group are permitted to delete it.":                                                "Лише власники групи можуть її видалити.",
+		"Only owners of the group are permitted to manage its members.":                                       "Лише власники групи можуть керувати її учасниками.",
+		"Organization already exists":                                                                         "Організація вже існує",
+		"Organization not found":                                                                              "Організацію не знайдено",
+		"Role should be one of: owner, member":                                                                "Роль повинна бути однією з: owner, member",
+		"Slug should be up to 63 lowercase letters, digits and hyphens, not starting or ending with a hyphen": "Slug повинен містити до 63 малих літер, цифр і дефісів і не починатися чи закінчуватися дефісом",
+		"Too many intervals, narrow the period or use a larger interval":                                      "Забагато інтервалів, звузьте період або виберіть більший інтервал",
+		"Unable to change the rating":                                                                         "Не вдалося змінити рейтинг",
+		"Unable to change the vote":                                                                           "Не вдалося змінити голос",
+		"Unable to check existance of vote":                                                                   "Не вдалося перевірити наявність голосу",
+		"Unable to check whether you are blocked":                                                             "Не вдалося перевірити, чи вас заблоковано",
+		"Unable to existance of vote":                                                                         "Не вдалося перевірити наявність голосу",
+		"Unable to generate hash for password":                                                                "Не вдалося згенерувати хеш пароля",
+		"Unable to get data export":                                                                           "Не вдалося отримати експорт даних",
+		"Unable to get the vote":                                                                              "Не вдалося отримати голос",
+		"Unable to restore profile":                                                                           "Не вдалося відновити профіль",
+		"Unable to start data export":                                                                         "Не вдалося розпочати експорт даних",
+		"User is not permitted to access this export.":                                                        "Користувач не має доступу до цього експорту.",
+		"User is not permitted to export other profiles except his own.":                                      "Користувач може експортувати лише власний профіль.",
+		"Users export is ordered by created_at only":                                                          "Експорт користувачів впорядковується лише за created_at",
+		"Wrong period, from should be before to":                                                              "Некоректний період, from повинен бути раніше за to",
+		"Wrong user role":                                                                                     "Некоректна роль користувача",
+		"You are not permitted to create invites.":                                                            "Ви не можете створювати запрошення.",
+		"You are not permitted to revoke invites of other users.":                                             "Ви не можете відкликати запрошення інших користувачів.",
+		"You are not permitted to update privacy settings of other users.":                                    "Ви не можете змінювати налаштування приватності інших користувачів.",
+		"You are not permitted to view privacy settings of other users.":                                      "Ви не можете переглядати налаштування приватності інших користувачів.",
+		"You are not permitted to view users blocked by other users.":                                         "Ви не можете переглядати користувачів, заблокованих іншими.",
+		"You are not permitted to see group invitations of other users.":                                      "Ви не можете переглядати запрошення до груп інших користувачів.",
+		"Status should be one of: active, invited":                                                            "Статус повинен бути одним з: active, invited",
+		"Group invitation not found":                                                                          "Запрошення до групи не знайдено",
+		"Failed to accept group invitation":                                                                   "Не вдалося прийняти запрошення до групи",
+		"First and last names can't start with \"pii:\"":                                                      "Ім'я та прізвище не можуть починатися з \"pii:\"",
+	},
+	"de": {
+		"Invalid request payload":                     "Ungültige Anfrage",
+		"Wrong UserId":                                "Ungültige Benutzer-ID",
+		"User not found":                              "Benutzer nicht gefunden",
+		"Wrong GroupId":                               "Ungültige Gruppen-ID",
+		"Group not found":                             "Gruppe nicht gefunden",
+		"Registration is closed":                      "Die Registrierung ist geschlossen",
+		"Invite code is required":                     "Ein Einladungscode ist erforderlich",
+		"Invite code is invalid or expired":           "Der Einladungscode ist ungültig oder abgelaufen",
+		"Wrong window":                                "Ungültiger Zeitraum",
+		"Failed to get leaderboard":                   "Bestenliste konnte nicht geladen werden",
+		"Wrong interval":                              "Ungültiges Intervall",
+		"Wrong timezone":                              "Ungültige Zeitzone",
+		"Failed to get rating history":                "Bewertungsverlauf konnte nicht geladen werden",
+		"Nickname not found":                          "Nickname nicht gefunden",
+		"Missing token":                               "Token fehlt",
+		"Invalid token":                               "Ungültiges Token",
+		"Your profile is banned or deleted":           "Ihr Profil ist gesperrt oder gelöscht",
+		"Error with authentication, please re-login.": "Authentifizierungsfehler, bitte melden Sie sich erneut an.",
+		"User is not permitted to change other profiles except his own.": "Benutzer dürfen nur ihr eigenes Profil ändern.",
+		"Failed to create user profile":                                  "Profil konnte nicht erstellt werden",
+		"Failed to update user profile":                                  "Profil konnte nicht aktualisiert werden",
+		"Failed to get user profile":                                     "Profil konnte nicht geladen werden",
+		"Failed to get users list":                                       "Benutzerliste konnte nicht geladen werden",
+		"Failed to search users":                                         "Benutzersuche fehlgeschlagen",
+		"Failed to log in":                                               "Anmeldung fehlgeschlagen",
+		"Empty search query":                                             "Leere Suchanfrage",
+		"Wrong value":                                                    "Ungültiger Wert",
+		"You can't rate yourself":                                        "Sie können sich nicht selbst bewerten",
+		"You already rated this user":                                    "Sie haben diesen Benutzer bereits bewertet",
+		"You already voted in last hour, users can vote only one time per hour": "Sie haben in der letzten Stunde bereits abgestimmt, abstimmen ist nur einmal pro Stunde möglich",
+		"Vote is the same as before":                                     "Die Bewertung ist unverändert",
+		"You are not permitted to rate this user":                        "Sie dürfen diesen Benutzer nicht bewerten",
+		"You can't follow yourself":                                      "Sie können sich nicht selbst folgen",
+		"You can't block yourself":                                       "Sie können sich nicht selbst blockieren",
+		"Failed to get preferences":                                      "Einstellungen konnten nicht geladen werden",
+		"Failed to update preferences":                                   "Einstellungen konnten nicht aktualisiert werden",
+		"You are not permitted to view preferences of other users.":      "Sie dürfen die Einstellungen anderer Benutzer nicht einsehen.",
+		"You are not permitted to update preferences of other users.":    "Sie dürfen die Einstellungen anderer Benutzer nicht ändern.",
+		"Cursor pagination is supported only when sorting by created_at": "Cursor-Paginierung wird nur bei Sortierung nach created_at unterstützt",
+		"Either oids or filter should be set":                            "Entweder oids oder filter muss angegeben werden",
+		"Error happaned, unable to delete profile":                       "Ein Fehler ist aufgetreten, das Profil konnte nicht gelöscht werden",
+		"Export is not available":                                        "Der Export ist nicht verfügbar",
+		"Failed to add group member":                                     "Gruppenmitglied konnte nicht hinzugefügt werden",
+		"Failed to apply batch action":                                   "Stapelaktion konnte nicht ausgeführt werden",
+		"Failed to block user":                                           "Benutzer konnte nicht blockiert werden",
+		"Failed to create group":                                         "Gruppe konnte nicht erstellt werden",
+		"Failed to create invite":                                        "Einladung konnte nicht erstellt werden",
+		"Failed to create organization":                                  "Organisation konnte nicht erstellt werden",
+		"Failed to delete group":                                         "Gruppe konnte nicht gelöscht werden",
+		"Failed to export users":                                         "Benutzer konnten nicht exportiert werden",
+		"Failed to follow user":                                          "Folgen fehlgeschlagen",
+		"Failed to get attributes schema":                                "Attributschema konnte nicht geladen werden",
+		"Failed to get audit log":                                        "Audit-Protokoll konnte nicht geladen werden",
+		"Failed to get blocked users":                                    "Blockierte Benutzer konnten nicht geladen werden",
+		"Failed to get follow relationship":                              "Folgebeziehung konnte nicht geladen werden",
+		"Failed to get group":                                            "Gruppe konnte nicht geladen werden",
+		"Failed to get group members":                                    "Gruppenmitglieder konnten nicht geladen werden",
+		"Failed to get groups":                                           "Gruppen konnten nicht geladen werden",
+		"Failed to get invites":                                          "Einladungen konnten nicht geladen werden",
+		"Failed to get organizations":                                    "Organisationen konnten nicht geladen werden",
+		"Failed to get privacy settings":                                 "Datenschutzeinstellungen konnten nicht geladen werden",
+		"Failed to get user attributes":                                  "Benutzerattribute konnten nicht geladen werden",
+		"Failed to get user groups":                                      "Gruppen des Benutzers konnten nicht geladen werden",
+		"Failed to get users amount":                                     "Anzahl der Benutzer konnte nicht ermittelt werden",
+		"Failed to remove group member":                                  "Gruppenmitglied konnte nicht entfernt werden",
+		"Failed to resolve nickname":                                     "Nickname konnte nicht aufgelöst werden",
+		"Failed to resolve organization":                                 "Organisation konnte nicht ermittelt werden",
+		"Failed to revoke invite":                                        "Einladung konnte nicht widerrufen werden",
+		"Failed to unblock user":                                         "Blockierung konnte nicht aufgehoben werden",
+		"Failed to unfollow user":                                        "Entfolgen fehlgeschlagen",
+		"Failed to update attributes schema":                             "Attributschema konnte nicht aktualisiert werden",
+		"Failed to update group":                                         "Gruppe konnte nicht aktualisiert werden",
+		"Failed to update privacy settings":                              "Datenschutzeinstellungen konnten nicht aktualisiert werden",
+		"Failed to update user attributes":                               "Benutzerattribute konnten nicht aktualisiert werden",
+		"Filter should have at least one condition":                      "Der Filter muss mindestens eine Bedingung enthalten",
+		"Group already exists":                                           "Die Gruppe existiert bereits",
+		"Group should have an owner":                                     "Die Gruppe muss einen Eigentümer haben",
+		"Invalid or expired download link":                               "Der Download-Link ist ungültig oder abgelaufen",
+		"Invite not found":                                               "Einladung nicht gefunden",
+		"Max uses and expiration should be positive":                     "Maximale Nutzungen und Ablaufzeit müssen positiv sein",
+		"Name is required":                                               "Ein Name ist erforderlich",
+		"Only admins and moderators are permitted to run batch actions.": "Nur Administratoren und Moderatoren dürfen Stapelaktionen ausführen.",
+		"Only admins are permitted to change roles.":                     "Nur Administratoren dürfen Rollen ändern.",
+		"Only admins are permitted to export users.":                     "Nur Administratoren dürfen Benutzer exportieren.",
+		"Only admins are permitted to import users.":                     "Nur Administratoren dürfen Benutzer importieren.",
+		"Only admins are permitted to invite moderators and admins.":     "Nur Administratoren dürfen Moderatoren und Administratoren einladen.",
+		"Only admins are permitted to manage attributes schema.":         "Nur Administratoren dürfen das Attributschema verwalten.",
+		"Only admins are permitted to restore profiles.":                 "Nur Administratoren dürfen Profile wiederherstellen.",
+		"Only admins are permitted to view the audit log.":               "Nur Administratoren dürfen das Audit-Protokoll einsehen.",
+		"Only admins of the default organization are permitted to create organizations.":                      "Nur Administratoren der Standardorganisation dürfen Organisationen erstellen.",
+		"Only admins of the default organization are permitted to list organizations.":                        "Nur Administratoren der Standardorganisation dürfen Organisationen auflisten.",
+		"Only owners of the group are permitted to change it.":                                                "Nur Eigentümer der Gruppe dürfen sie ändern.",
+		"Only owners of the group are permitted to delete it.":                                                "Nur Eigentümer der Gruppe dürfen sie löschen.",
+		"Only owners of the group are permitted to manage its members.":                                       "Nur Eigentümer der Gruppe dürfen ihre Mitglieder verwalten.",
+		"Organization already exists":                                                                         "Die Organisation existiert bereits",
+		"Organization not found":                                                                              "Organisation nicht gefunden",
+		"Role should be one of: owner, member":                                                                "Die Rolle muss owner oder member sein",
+		"Slug should be up to 63 lowercase letters, digits and hyphens, not starting or ending with a hyphen": "Der Slug darf aus bis zu 63 Kleinbuchstaben, Ziffern und Bindestrichen bestehen und nicht mit einem Bindestrich beginnen oder enden",
+		"Too many intervals, narrow the period or use a larger interval":                                      "Zu viele Intervalle, verkürzen Sie den Zeitraum oder wählen Sie ein größeres Intervall",
+		"Unable to change the rating":                                                                         "Die Bewertung konnte nicht geändert werden",
+		"Unable to change the vote":                                                                           "Die Stimme konnte nicht geändert werden",
+		"Unable to check existance of vote":                                                                   "Die Stimme konnte nicht überprüft werden",
+		"Unable to check whether you are blocked":                                                             "Es konnte nicht geprüft werden, ob Sie blockiert sind",
+		"Unable to existance of vote":                                                                         "Die Stimme konnte nicht überprüft werden",
+		"Unable to generate hash for password":                                                                "Passwort-Hash konnte nicht erzeugt werden",
+		"Unable to get data export":                                                                           "Datenexport konnte nicht geladen werden",
+		"Unable to get the vote":                                                                              "Die Stimme konnte nicht geladen werden",
+		"Unable to restore profile":                                                                           "Profil konnte nicht wiederhergestellt werden",
+		"Unable to start data export":                                                                         "Datenexport konnte nicht gestartet werden",
+		"User is not permitted to access this export.":                                                        "Benutzer dürfen auf diesen Export nicht zugreifen.",
+		"User is not permitted to export other profiles except his own.":                                      "Benutzer dürfen nur ihr eigenes Profil exportieren.",
+		"Users export is ordered by created_at only":                                                          "Der Benutzerexport wird nur nach created_at sortiert",
+		"Wrong period, from should be before to":                                                              "Ungültiger Zeitraum, from muss vor to liegen",
+		"Wrong user role":                                                                                     "Ungültige Benutzerrolle",
+		"You are not permitted to create invites.":                                                            "Sie dürfen keine Einladungen erstellen.",
+		"You are not permitted to revoke invites of other users.":                                             "Sie dürfen Einladungen anderer Benutzer nicht widerrufen.",
+		"You are not permitted to update privacy settings of other users.":                                    "Sie dürfen die Datenschutzeinstellungen anderer Benutzer nicht ändern.",
+		"You are not permitted to view privacy settings of other users.":                                      "Sie dürfen die Datenschutzeinstellungen anderer Benutzer nicht einsehen.",
+		"You are not permitted to view users blocked by other users.":                                         "Sie dürfen die von anderen Benutzern blockierten Benutzer nicht einsehen.",
+		"You are not permitted to see group invitations of other users.":                                      "Sie dürfen Gruppeneinladungen anderer Benutzer nicht einsehen.",
+		"Status should be one of: active, invited":                                                            "Der Status muss active oder invited sein",
+		"Group invitation not found":                                                                          "Gruppeneinladung nicht gefunden",
+		"Failed to accept group invitation":                                                                   "Gruppeneinladung konnte nicht angenommen werden",
+		"First and last names can't start with \"pii:\"":                                                      "Vor- und Nachname dürfen nicht mit \"pii:\" beginnen",
+	},
+}
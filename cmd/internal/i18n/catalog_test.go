@@ -0,0 +1,59 @@
+package i18n
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+// TestCatalogCoversHandlerErrors fails if an error message that handlers respond with isn't translated into every locale.
+// Only literal messages are checked, messages built from values are returned as they are.
+func TestCatalogCoversHandlerErrors(t *testing.T) {
+	files, err := filepath.Glob(filepath.Join("..", "api", "*.go"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	messages := make(map[string]string)
+	fset := token.NewFileSet()
+	for _, file := range files {
+		parsed, err := parser.ParseFile(fset, file, nil, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		ast.Inspect(parsed, func(node ast.Node) bool {
+			kv, ok := node.(*ast.KeyValueExpr)
+			if !ok {
+				return true
+			}
+			key, ok := kv.Key.(*ast.BasicLit)
+			if !ok || key.Kind != token.STRING || key.Value != `"error"` {
+				return true
+			}
+			value, ok := kv.Value.(*ast.BasicLit)
+			if !ok || value.Kind != token.STRING {
+				return true
+			}
+			msg, err := strconv.Unquote(value.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			messages[msg] = fset.Position(value.Pos()).String()
+			return true
+		})
+	}
+	if len(messages) == 0 {
+		t.Fatal("no error messages found in handlers")
+	}
+
+	for _, locale := range locales[1:] {
+		for msg, pos := range messages {
+			if _, ok := catalog[locale][msg]; !ok {
+				t.Errorf("%s: %q has no %s translation", pos, msg, locale)
+			}
+		}
+	}
+}
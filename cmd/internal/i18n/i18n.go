@@ -0,0 +1,54 @@
+package i18n
+
+import (
+	"golang.org/x/text/language"
+)
+
+const DefaultLocale = "en"
+
+// locales lists supported locales, messages are written in the first one and translated into the rest.
+var locales = []string{DefaultLocale, "uk", "de"}
+
+var matcher = newMatcher()
+
+func newMatcher() language.Matcher {
+	tags := make([]language.Tag, 0, len(locales))
+	for _, locale := range locales {
+		tags = append(tags, language.Make(locale))
+	}
+	return language.NewMatcher(tags)
+}
+
+func Locales() []string {
+	return locales
+}
+
+func IsSupported(locale string) bool {
+	for _, supported := range locales {
+		if supported == locale {
+			return true
+		}
+	}
+	return false
+}
+
+// Match picks the supported locale that fits the Accept-Language header best, or returns fallback if none does.
+func Match(acceptLanguage string, fallback string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return fallback
+	}
+	_, index, confidence := matcher.Match(tags...)
+	if confidence == language.No {
+		return fallback
+	}
+	return locales[index]
+}
+
+// Translate returns msg in locale. Messages without a translation, including the ones with formatted values, are returned as they are.
+func Translate(locale string, msg string) string {
+	if translated, ok := catalog[locale][msg]; ok {
+		return translated
+	}
+	return msg
+}
@@ -0,0 +1,164 @@
+package leaderboard
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/redis/go-redis/v9"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+// windows are durations of leaderboard windows, the all-time window isn't limited.
+var windows = map[domain.LeaderboardWindow]time.Duration{
+	domain.WindowDay:   24 * time.Hour,
+	domain.WindowWeek:  7 * 24 * time.Hour,
+	domain.WindowMonth: 30 * 24 * time.Hour,
+	domain.WindowAll:   0,
+}
+
+// ValidWindow tells whether window is one of the supported leaderboard windows.
+func ValidWindow(window domain.LeaderboardWindow) bool {
+	_, ok := windows[window]
+	return ok
+}
+
+// board is a leaderboard that was requested, it is refreshed in the background until it isn't requested for a while.
+type board struct {
+	tenant        uuid.UUID
+	query         domain.LeaderboardQuery
+	lastRequested time.Time
+}
+
+// Refresher builds leaderboards from ClickHouse aggregates and profiles from PostgreSQL, and keeps them cached.
+// Boards requested within the idle period are rebuilt every interval, so that requests rarely have to wait for them.
+type Refresher struct {
+	DB       domain.UserProfileManager
+	Rating   domain.StatsManager
+	Cache    domain.CacheInterface
+	ttl      time.Duration
+	interval time.Duration
+	idle     time.Duration
+
+	// boards holds requested boards by cache key
+	boards sync.Map
+}
+
+func NewRefresher(cfg *config.Config, db domain.UserProfileManager, rating domain.StatsManager, cache domain.CacheInterface) *Refresher {
+	return &Refresher{
+		DB:       db,
+		Rating:   rating,
+		Cache:    cache,
+		ttl:      time.Duration(cfg.Leaderboard.CacheTTLSeconds) * time.Second,
+		interval: time.Duration(cfg.Leaderboard.RefreshIntervalSeconds) * time.Second,
+		idle:     time.Duration(cfg.Leaderboard.IdleMinutes) * time.Minute,
+	}
+}
+
+func key(query domain.LeaderboardQuery) string {
+	return fmt.Sprintf("leaderboard:%s:%d:%d", query.Window, query.EmojiID, query.Limit)
+}
+
+// Get returns the cached leaderboard, building it if it isn't cached.
+func (r *Refresher) Get(tenant uuid.UUID, query domain.LeaderboardQuery) (domain.Leaderboard, error) {
+	cacheKey := key(query)
+	r.boards.Store(tenant.String()+":"+cacheKey, board{tenant: tenant, query: query, lastRequested: time.Now()})
+
+	leaderboard, err := r.Cache.GetLeaderboard(tenant, cacheKey)
+	if err == nil {
+		return leaderboard, nil
+	}
+	if err != redis.Nil {
+		log.Warnf("Leaderboard: %s", err)
+	}
+	return r.refresh(tenant, query)
+}
+
+func (r *Refresher) Run() {
+	log.Info("Leaderboard refresh job started")
+	for {
+		time.Sleep(r.interval)
+		r.Refresh()
+	}
+}
+
+// Refresh rebuilds boards requested within the idle period and forgets the others.
+func (r *Refresher) Refresh() {
+	r.boards.Range(func(boardKey, value any) bool {
+		b := value.(board)
+		if time.Since(b.lastRequested) > r.idle {
+			r.boards.Delete(boardKey)
+			return true
+		}
+		if _, err := r.refresh(b.tenant, b.query); err != nil {
+			log.Warnf("Leaderboard: %s", err)
+		}
+		return true
+	})
+}
+
+func (r *Refresher) refresh(tenant uuid.UUID, query domain.LeaderboardQuery) (domain.Leaderboard, error) {
+	leaderboard, err := r.Build(tenant, query)
+	if err != nil {
+		return domain.Leaderboard{}, err
+	}
+	if err := r.Cache.SetWithTTL(tenant, key(query), leaderboard, r.ttl); err != nil {
+		log.Warnf("Leaderboard: unable to save cache: %s", err)
+	}
+	return leaderboard, nil
+}
+
+// Build builds the leaderboard, leaving out users that aren't active or don't have public profiles.
+// Top rated users are read page by page until the board is full, as some of them can be left out.
+func (r *Refresher) Build(tenant uuid.UUID, query domain.LeaderboardQuery) (domain.Leaderboard, error) {
+	leaderboard := domain.Leaderboard{LeaderboardQuery: query, Entries: []domain.LeaderboardEntry{}, UpdatedAt: time.Now().UTC()}
+
+	var since *time.Time
+	if window := windows[query.Window]; window > 0 {
+		start := leaderboard.UpdatedAt.Add(-window)
+		since = &start
+	}
+
+	for offset := 0; len(leaderboard.Entries) < query.Limit; offset += query.Limit {
+		rated, err := r.Rating.GetTopRated(tenant, query.EmojiID, since, query.Limit, offset)
+		if err != nil {
+			return domain.Leaderboard{}, fmt.Errorf("Build: %w", err)
+		}
+		if len(rated) == 0 {
+			break
+		}
+
+		oids := make([]uuid.UUID, 0, len(rated))
+		for _, user := range rated {
+			oids = append(oids, user.OID)
+		}
+		users, err := r.DB.GetLeaderboardUsers(tenant, oids)
+		if err != nil {
+			return domain.Leaderboard{}, fmt.Errorf("Build: %w", err)
+		}
+		profiles := make(map[uuid.UUID]domain.UserProfileDTO, len(users))
+		for _, user := range users {
+			profiles[user.OID] = user
+		}
+
+		for _, user := range rated {
+			profile, ok := profiles[user.OID]
+			if !ok || len(leaderboard.Entries) == query.Limit {
+				continue
+			}
+			leaderboard.Entries = append(leaderboard.Entries, domain.LeaderboardEntry{
+				Place: len(leaderboard.Entries) + 1,
+				Votes: user.Votes,
+				User:  profile,
+			})
+		}
+
+		if len(rated) < query.Limit {
+			break
+		}
+	}
+	return leaderboard, nil
+}
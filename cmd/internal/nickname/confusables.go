@@ -0,0 +1,84 @@
+package nickname
+
+// confusables maps characters to the prototype they are visually confusable with.
+// It is a subset of the Unicode TR39 confusables data (confusables.txt) that covers
+// Latin, Cyrillic and Greek lookalikes and digits that are allowed by the nickname charset.
+// Fullwidth and other compatibility forms are not listed, NFKC normalization handles them.
+var confusables = map[rune]string{
+	// Latin and digits
+	'0': "O",
+	'1': "l",
+	'I': "l",
+	'|': "l",
+	'm': "rn",
+	'd': "cl",
+	'ı': "i",
+	'ɑ': "a",
+	'ɡ': "g",
+	'ɩ': "i",
+	'ʏ': "y",
+
+	// Cyrillic
+	'А': "A",
+	'В': "B",
+	'Е': "E",
+	'З': "3",
+	'К': "K",
+	'М': "M",
+	'Н': "H",
+	'О': "O",
+	'Р': "P",
+	'С': "C",
+	'Т': "T",
+	'У': "Y",
+	'Х': "X",
+	'Ѕ': "S",
+	'І': "l",
+	'Ј': "J",
+	'Ԁ': "cl",
+	'Ԛ': "Q",
+	'Ԝ': "W",
+	'а': "a",
+	'е': "e",
+	'о': "o",
+	'р': "p",
+	'с': "c",
+	'у': "y",
+	'х': "x",
+	'ѕ': "s",
+	'і': "i",
+	'ј': "j",
+	'һ': "h",
+	'ӏ': "l",
+	'ԁ': "cl",
+	'ԛ': "q",
+	'ԝ': "w",
+	'ү': "y",
+	'ь': "b",
+
+	// Greek
+	'Α': "A",
+	'Β': "B",
+	'Ε': "E",
+	'Ζ': "Z",
+	'Η': "H",
+	'Ι': "l",
+	'Κ': "K",
+	'Μ': "M",
+	'Ν': "N",
+	'Ο': "O",
+	'Ρ': "P",
+	'Τ': "T",
+	'Υ': "Y",
+	'Χ': "X",
+	'α': "a",
+	'ι': "i",
+	'κ': "k",
+	'ν': "v",
+	'ο': "o",
+	'ρ': "p",
+	'υ': "u",
+	'χ': "x",
+	'ϲ': "c",
+	'ϳ': "j",
+}
@@ -0,0 +1,125 @@
+package nickname
+
+import (
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/sosshik/rest-user-management/pkg/config"
+	"golang.org/x/text/unicode/norm"
+)
+
+// scripts lists the scripts a nickname can be written in. Letters of different scripts
+// can't be mixed, except for Han, Hiragana and Katakana which are used together in Japanese.
+var scripts = []struct {
+	name  string
+	table *unicode.RangeTable
+}{
+	{"Latin", unicode.Latin},
+	{"Cyrillic", unicode.Cyrillic},
+	{"Greek", unicode.Greek},
+	{"Armenian", unicode.Armenian},
+	{"Georgian", unicode.Georgian},
+	{"Hebrew", unicode.Hebrew},
+	{"Arabic", unicode.Arabic},
+	{"Devanagari", unicode.Devanagari},
+	{"Thai", unicode.Thai},
+	{"Hangul", unicode.Hangul},
+	{"Japanese", unicode.Han},
+	{"Japanese", unicode.Hiragana},
+	{"Japanese", unicode.Katakana},
+}
+
+type Validator struct {
+	minLength int
+	maxLength int
+	reserved  map[string]string
+}
+
+func NewValidator(cfg config.NicknameConfig) *Validator {
+	reserved := make(map[string]string, len(cfg.Reserved))
+	for _, word := range cfg.Reserved {
+		word = Normalize(word)
+		if word != "" {
+			reserved[Skeleton(word)] = word
+		}
+	}
+	return &Validator{minLength: cfg.MinLength, maxLength: cfg.MaxLength, reserved: reserved}
+}
+
+// Normalize applies Unicode NFKC normalization and trims surrounding whitespace.
+func Normalize(nickname string) string {
+	return strings.TrimSpace(norm.NFKC.String(nickname))
+}
+
+// Key returns the case-insensitive form of nickname that is used to check uniqueness.
+func Key(nickname string) string {
+	return strings.ToLower(Normalize(nickname))
+}
+
+// Skeleton returns the case-insensitive Unicode TR39 skeleton of nickname.
+// Nicknames with equal skeletons are visually confusable.
+func Skeleton(nickname string) string {
+	// prototypes are mapped before and after lower-casing, so that both "I" and "M"
+	// end up with the same skeleton as their confusable lower-case counterparts
+	skeleton := mapConfusables(norm.NFD.String(Normalize(nickname)))
+	skeleton = mapConfusables(strings.ToLower(skeleton))
+	return norm.NFD.String(skeleton)
+}
+
+func mapConfusables(s string) string {
+	var mapped strings.Builder
+	for _, r := range s {
+		if prototype, ok := confusables[r]; ok {
+			mapped.WriteString(prototype)
+			continue
+		}
+		mapped.WriteRune(r)
+	}
+	return mapped.String()
+}
+
+// Validate checks nickname against the length, charset and reserved words policy
+// and returns its normalized form.
+func (v *Validator) Validate(nickname string) (string, error) {
+	normalized := Normalize(nickname)
+
+	length := utf8.RuneCountInString(normalized)
+	if length < v.minLength || length > v.maxLength {
+		return "", fmt.Errorf("nickname should be from %d to %d symbols long", v.minLength, v.maxLength)
+	}
+
+	script := ""
+	for i, r := range normalized {
+		if i == 0 && !unicode.IsLetter(r) && !unicode.IsDigit(r) {
+			return "", fmt.Errorf("nickname should start with a letter or a number")
+		}
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && !unicode.Is(unicode.Mn, r) && r != '_' && r != '-' && r != '.' {
+			return "", fmt.Errorf("nickname can contain only letters, numbers, '_', '-' and '.'")
+		}
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letterScript := scriptOf(r)
+		if script != "" && letterScript != script {
+			return "", fmt.Errorf("nickname can't mix letters of different scripts")
+		}
+		script = letterScript
+	}
+
+	if word, ok := v.reserved[Skeleton(normalized)]; ok {
+		return "", fmt.Errorf("nickname %q is reserved", word)
+	}
+
+	return normalized, nil
+}
+
+func scriptOf(r rune) string {
+	for _, script := range scripts {
+		if unicode.Is(script.table, r) {
+			return script.name
+		}
+	}
+	return "Other"
+}
@@ -0,0 +1,224 @@
+package pii
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// prefix marks encrypted values, values without it are stored in plaintext and are returned as is.
+const prefix = "pii:v1:"
+
+// reservedPrefix is the part of prefix shared by all versions, queries tell encrypted values by it.
+const reservedPrefix = "pii:"
+
+// ErrReserved is returned for plaintext values that would be taken for encrypted ones.
+var ErrReserved = errors.New("value can't start with \"" + reservedPrefix + "\"")
+
+// Keyring encrypts PII with envelope encryption: every value is encrypted with its own data key,
+// which is wrapped with the active key of the keyring. Older keys are kept to decrypt values
+// that weren't re-encrypted yet. A nil Keyring leaves values in plaintext.
+type Keyring struct {
+	active   string
+	keys     map[string]cipher.AEAD
+	indexKey []byte
+}
+
+// keyringFile is the format of the keyring file, keys are base64 encoded 32 byte AES keys.
+// The index key is used for blind indexes and can't be rotated without re-encryption.
+type keyringFile struct {
+	Active   string            `json:"active"`
+	Keys     map[string]string `json:"keys"`
+	IndexKey string            `json:"index_key"`
+}
+
+// Load reads the keyring from path, it returns a nil Keyring if path is empty.
+func Load(path string) (*Keyring, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("unable to read keyring: %w", err)
+	}
+	var file keyringFile
+	if err := json.Unmarshal(content, &file); err != nil {
+		return nil, fmt.Errorf("unable to decode keyring: %w", err)
+	}
+
+	k := &Keyring{active: file.Active, keys: make(map[string]cipher.AEAD, len(file.Keys))}
+	for id, encoded := range file.Keys {
+		if id == "" || strings.Contains(id, ":") {
+			return nil, fmt.Errorf("wrong key id %q, it should be non-empty and can't contain colons", id)
+		}
+		key, err := decodeKey(encoded)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+		k.keys[id], err = newAEAD(key)
+		if err != nil {
+			return nil, fmt.Errorf("key %q: %w", id, err)
+		}
+	}
+	if _, ok := k.keys[k.active]; !ok {
+		return nil, fmt.Errorf("active key %q is not in the keyring", k.active)
+	}
+
+	k.indexKey, err = decodeKey(file.IndexKey)
+	if err != nil {
+		return nil, fmt.Errorf("index key: %w", err)
+	}
+	return k, nil
+}
+
+func decodeKey(encoded string) ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode key: %w", err)
+	}
+	if len(key) != 32 {
+		return nil, fmt.Errorf("key should be 32 bytes, got %d", len(key))
+	}
+	return key, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("unable to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+func seal(aead cipher.AEAD, plaintext []byte, additional []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, fmt.Errorf("unable to generate nonce: %w", err)
+	}
+	return aead.Seal(nonce, nonce, plaintext, additional), nil
+}
+
+func open(aead cipher.AEAD, sealed []byte, additional []byte) ([]byte, error) {
+	if len(sealed) < aead.NonceSize() {
+		return nil, errors.New("ciphertext is too short")
+	}
+	return aead.Open(nil, sealed[:aead.NonceSize()], sealed[aead.NonceSize():], additional)
+}
+
+// Encrypt encrypts value with a new data key wrapped with the active key.
+func (k *Keyring) Encrypt(value []byte) ([]byte, error) {
+	if k == nil {
+		return value, nil
+	}
+
+	dataKey := make([]byte, 32)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, fmt.Errorf("unable to generate data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	wrapped, err := seal(k.keys[k.active], dataKey, []byte(k.active))
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := seal(dataAEAD, value, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return []byte(prefix + k.active + ":" + base64.RawStdEncoding.EncodeToString(wrapped) + ":" + base64.RawStdEncoding.EncodeToString(sealed)), nil
+}
+
+// Decrypt decrypts a value encrypted with any key of the keyring, plaintext values are returned as is.
+func (k *Keyring) Decrypt(value []byte) ([]byte, error) {
+	if !IsEncrypted(value) {
+		return value, nil
+	}
+	if k == nil {
+		return nil, errors.New("value is encrypted, but no keyring is configured")
+	}
+
+	parts := strings.Split(string(value[len(prefix):]), ":")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed encrypted value")
+	}
+	aead, ok := k.keys[parts[0]]
+	if !ok {
+		return nil, fmt.Errorf("key %q is not in the keyring", parts[0])
+	}
+	wrapped, err := base64.RawStdEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode data key: %w", err)
+	}
+	sealed, err := base64.RawStdEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("unable to decode ciphertext: %w", err)
+	}
+
+	dataKey, err := open(aead, wrapped, []byte(parts[0]))
+	if err != nil {
+		return nil, fmt.Errorf("unable to unwrap data key: %w", err)
+	}
+	dataAEAD, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+	plaintext, err := open(dataAEAD, sealed, nil)
+	if err != nil {
+		return nil, fmt.Errorf("unable to decrypt value: %w", err)
+	}
+	return plaintext, nil
+}
+
+func (k *Keyring) EncryptString(value string) (string, error) {
+	encrypted, err := k.Encrypt([]byte(value))
+	return string(encrypted), err
+}
+
+func (k *Keyring) DecryptString(value string) (string, error) {
+	decrypted, err := k.Decrypt([]byte(value))
+	return string(decrypted), err
+}
+
+// IsEncrypted tells whether value was encrypted by a keyring.
+func IsEncrypted(value []byte) bool {
+	return strings.HasPrefix(string(value), prefix)
+}
+
+// IsReserved tells whether a plaintext value starts like an encrypted one, such values can't be stored,
+// they couldn't be told apart from ciphertext when read back.
+func IsReserved(value string) bool {
+	return strings.HasPrefix(value, reservedPrefix)
+}
+
+// NeedsReencryption tells whether value is stored in plaintext or is encrypted with a key that isn't active anymore.
+func (k *Keyring) NeedsReencryption(value string) bool {
+	if k == nil {
+		return false
+	}
+	return !strings.HasPrefix(value, prefix+k.active+":")
+}
+
+// BlindIndex returns a keyed hash of value that allows exact-match lookups of encrypted values,
+// case and extra spaces are ignored. It's empty if there is no keyring.
+func (k *Keyring) BlindIndex(value string) string {
+	if k == nil {
+		return ""
+	}
+	mac := hmac.New(sha256.New, k.indexKey)
+	mac.Write([]byte(strings.Join(strings.Fields(strings.ToLower(value)), " ")))
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,89 @@
+package purge
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	log "github.com/sirupsen/logrus"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+// Purger hard-deletes profiles that were soft-deleted more than the retention period ago
+// and anonymizes their votes, organization by organization.
+type Purger struct {
+	Orgs      domain.OrganizationManager
+	DB        domain.UserProfileManager
+	Rating    domain.StatsManager
+	Cache     domain.CacheInterface
+	Audit     domain.AuditManager
+	retention time.Duration
+	interval  time.Duration
+}
+
+func NewPurger(cfg *config.Config, orgs domain.OrganizationManager, db domain.UserProfileManager, rating domain.StatsManager, cache domain.CacheInterface, audit domain.AuditManager) *Purger {
+	return &Purger{
+		Orgs:      orgs,
+		DB:        db,
+		Rating:    rating,
+		Cache:     cache,
+		Audit:     audit,
+		retention: time.Duration(cfg.Deletion.RetentionHours) * time.Hour,
+		interval:  time.Duration(cfg.Deletion.PurgeIntervalMinutes) * time.Minute,
+	}
+}
+
+func (p *Purger) Run() {
+	log.Info("Purge job started")
+	for {
+		p.Purge()
+		time.Sleep(p.interval)
+	}
+}
+
+func (p *Purger) Purge() {
+	orgs, err := p.Orgs.GetOrganizations()
+	if err != nil {
+		log.Warnf("Purge: %s", err)
+		return
+	}
+
+	for _, org := range orgs {
+		p.purgeTenant(org.ID)
+	}
+}
+
+func (p *Purger) purgeTenant(tenant uuid.UUID) {
+	oids, err := p.DB.GetUsersToPurge(tenant, time.Now().UTC().Add(-p.retention))
+	if err != nil {
+		log.Warnf("Purge: %s", err)
+		return
+	}
+
+	for _, oid := range oids {
+		if err := p.purgeUser(tenant, oid); err != nil {
+			log.Warnf("Purge: unable to purge user with oid %s: %s", oid, err)
+			continue
+		}
+		log.Infof("Purged deleted user with oid %s", oid)
+	}
+}
+
+func (p *Purger) purgeUser(tenant uuid.UUID, oid uuid.UUID) error {
+	// votes go first, so that a failed run can be retried while the profile still exists
+	if err := p.Rating.AnonymizeVotes(tenant, oid); err != nil {
+		return err
+	}
+	if err := p.DB.PurgeUser(tenant, oid); err != nil {
+		return err
+	}
+	// purges aren't made by anyone, so the record has no actor
+	record := domain.AuditRecord{TargetOID: oid, Action: domain.AuditPurge, Before: map[string]interface{}{"state": int(domain.Deleted)}, CreatedAt: time.Now().UTC()}
+	if err := p.Audit.WriteAudit(tenant, []domain.AuditRecord{record}); err != nil {
+		log.Errorf("Purge: unable to write audit record of user with oid %s: %s", oid, err)
+	}
+	if err := p.Cache.Delete(tenant, oid.String()); err != nil {
+		log.Warnf("Purge: %s", err)
+	}
+	return nil
+}
@@ -1,3 +1,7 @@
-package rating
-
-var emojiStr = map[int]string{1: "❤️", 2: "😂", 3: "😁", 4: "👍", 5: "😍"}
+package rating
+
+// emojis are the emojis users can vote with, in the order they are listed in the rating breakdown.
+var emojis = []struct {
+	ID     int32
+	Symbol string
+}{{1, "❤️"}, {2, "😂"}, {3, "😁"}, {4, "👍"}, {5, "😍"}}
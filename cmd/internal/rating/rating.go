@@ -1,159 +1,479 @@
-package rating
-
-import (
-	"context"
-	"database/sql"
-	"fmt"
-	"strings"
-	"time"
-
-	"github.com/ClickHouse/clickhouse-go/v2"
-	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
-	"github.com/google/uuid"
-	"github.com/sosshik/rest-user-management/cmd/internal/domain"
-	"github.com/sosshik/rest-user-management/pkg/config"
-)
-
-type ClickHouse struct {
-	conn driver.Conn
-}
-
-func NewClickHouse(cfg *config.Config) (*ClickHouse, error) {
-	conn, err := clickhouse.Open(&clickhouse.Options{
-		Addr: []string{cfg.CH.Addr},
-		Auth: clickhouse.Auth{
-			Database: cfg.CH.DB,
-			Username: cfg.CH.User,
-			Password: cfg.CH.Pass,
-		},
-	})
-	if err != nil {
-		return &ClickHouse{}, err
-	}
-
-	return &ClickHouse{conn: conn}, nil
-}
-
-func (c *ClickHouse) RateProfile(vote domain.VoteDTO) error {
-	err := c.conn.Exec(context.Background(), `
-	INSERT INTO rating.emotes (from_oid, to_oid, emoji_id, voted_at)
-	VALUES ($1,$2,$3,$4);
-	`, vote.FromOID, vote.ToOID, vote.EmojiId, vote.VotedAt)
-	if err != nil {
-		return fmt.Errorf("RateProfile: unable to execute query to DB: %w", err)
-	}
-
-	return nil
-}
-
-func (c *ClickHouse) GetVote(vote domain.VoteDTO) (domain.VoteDTO, bool, error) {
-	var dbVote domain.VoteDTO
-	err := c.conn.QueryRow(context.Background(), `
-		SELECT from_oid, to_oid, emoji_id, voted_at FROM rating.emotes
-		WHERE from_oid = $1 AND to_oid = $2;
-	`, vote.FromOID, vote.ToOID).Scan(&dbVote.FromOID, &dbVote.ToOID, &dbVote.EmojiId, &dbVote.VotedAt)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return domain.VoteDTO{}, false, err
-		}
-		return domain.VoteDTO{}, false, fmt.Errorf("GetVote: unable to execute query to DB: %w", err)
-	}
-	return dbVote, true, nil
-}
-
-func (c *ClickHouse) LastVotedAt(vote domain.VoteDTO) (time.Time, error) {
-	var lastVoted time.Time
-	err := c.conn.QueryRow(context.Background(), `
-		SELECT voted_at FROM rating.emotes
-		WHERE from_oid = $1
-		ORDER BY voted_at DESC 
-		LIMIT 1;
-	`, vote.FromOID).Scan(&lastVoted)
-	if err != nil {
-		if err == sql.ErrNoRows {
-			return time.Time{}, nil
-		}
-		return time.Time{}, fmt.Errorf("LastVotedAt: unable to execute query to DB: %w", err)
-	}
-	return lastVoted, nil
-}
-
-func (c *ClickHouse) UpdateProfileRating(vote domain.VoteDTO, oldValue int32) error {
-
-	err := c.conn.Exec(context.Background(), `
-		ALTER TABLE rating.emotes
-		UPDATE emoji_id = $1, voted_at = $2
-		WHERE from_oid = $3 AND to_oid = $4;
-	`, vote.EmojiId, vote.VotedAt, vote.FromOID, vote.ToOID)
-	if err != nil {
-		return fmt.Errorf("UpdateProfileRating: unable to execute query to update votes: %w", err)
-	}
-
-	return nil
-}
-
-func (c *ClickHouse) GetRating(userId uuid.UUID) (int, error) {
-	var rating uint64
-	err := c.conn.QueryRow(context.Background(), `
-	SELECT COUNT(*)
-    FROM rating.emotes
-	WHERE to_oid = $1;
-	`, userId).Scan(&rating)
-	if err != nil {
-		return 0, fmt.Errorf("GetRating: unable to execute query to DB: %w", err)
-	}
-	return int(rating), nil
-}
-
-func (c *ClickHouse) GetRatingForList(oids []uuid.UUID) (map[uuid.UUID]int, error) {
-	query := strings.Builder{}
-	query.WriteString("SELECT to_oid, COUNT(*) FROM rating.emotes WHERE to_oid IN (")
-	for i, oid := range oids {
-		if i > 0 {
-			query.WriteString(",")
-		}
-
-		query.WriteString(fmt.Sprintf("'%s',", oid.String()))
-	}
-	query.WriteString(") GROUP BY to_oid;")
-
-	ratings := make(map[uuid.UUID]int)
-
-	rows, err := c.conn.Query(context.Background(), query.String())
-	if err != nil {
-		return map[uuid.UUID]int{}, fmt.Errorf("GetRatingForList: unable to execute query to DB: %w", err)
-	}
-	defer rows.Close()
-	for rows.Next() {
-		var oid uuid.UUID
-		var rating uint64
-		err := rows.Scan(&oid, &rating)
-		if err != nil {
-			return map[uuid.UUID]int{}, fmt.Errorf("GetRatingForList: scan the row: %w", err)
-		}
-
-		ratings[oid] = int(rating)
-	}
-
-	return ratings, nil
-
-}
-
-func (c *ClickHouse) GetRatingSeparately(userId uuid.UUID) (string, error) {
-	ratingBuilder := strings.Builder{}
-	for emojiId, emoji := range emojiStr {
-		var rating uint64
-		err := c.conn.QueryRow(context.Background(), `
-		SELECT COUNT(*)
-		FROM rating.emotes
-		WHERE to_oid = $1 AND emoji_id = $2;
-		`, userId, emojiId).Scan(&rating)
-		if err != nil {
-			return "", fmt.Errorf("GetRatingSeparately: unable to execute query to DB: %w", err)
-		}
-		ratingBuilder.WriteString(fmt.Sprintf("%s:%d; ", emoji, rating))
-	}
-
-	return ratingBuilder.String(), nil
-
-}
+package rating
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+type ClickHouse struct {
+	conn driver.Conn
+}
+
+func NewClickHouse(cfg *config.Config) (*ClickHouse, error) {
+	conn, err := clickhouse.Open(&clickhouse.Options{
+		Addr: []string{cfg.CH.Addr},
+		Auth: clickhouse.Auth{
+			Database: cfg.CH.DB,
+			Username: cfg.CH.User,
+			Password: cfg.CH.Pass,
+		},
+	})
+	if err != nil {
+		return &ClickHouse{}, err
+	}
+
+	return &ClickHouse{conn: conn}, nil
+}
+
+// voteRow is a row of rating.votes. A row with sign -1 cancels the vote, and the row with the highest version
+// of every pair of users replaces the older ones.
+type voteRow struct {
+	vote    domain.VoteDTO
+	sign    int8
+	version uint64
+}
+
+// newVersion returns a version greater than versions of rows inserted earlier.
+func newVersion() uint64 {
+	return uint64(time.Now().UnixNano())
+}
+
+func (c *ClickHouse) insertVotes(tenant uuid.UUID, rows []voteRow) error {
+	batch, err := c.conn.PrepareBatch(context.Background(), `
+		INSERT INTO rating.votes (tenant_id, to_oid, from_oid, emoji_id, voted_at, sign, version)
+	`)
+	if err != nil {
+		return fmt.Errorf("unable to prepare batch: %w", err)
+	}
+	for _, row := range rows {
+		err := batch.Append(tenant, row.vote.ToOID, row.vote.FromOID, row.vote.EmojiId, row.vote.VotedAt, row.sign, row.version)
+		if err != nil {
+			return fmt.Errorf("unable to append row to batch: %w", err)
+		}
+	}
+	if err := batch.Send(); err != nil {
+		return fmt.Errorf("unable to send batch: %w", err)
+	}
+	return nil
+}
+
+func (c *ClickHouse) RateProfile(tenant uuid.UUID, vote domain.VoteDTO) error {
+	err := c.insertVotes(tenant, []voteRow{{vote: vote, sign: 1, version: newVersion()}})
+	if err != nil {
+		return fmt.Errorf("RateProfile: %w", err)
+	}
+	return nil
+}
+
+func (c *ClickHouse) GetVote(tenant uuid.UUID, vote domain.VoteDTO) (domain.VoteDTO, bool, error) {
+	var dbVote domain.VoteDTO
+	err := c.conn.QueryRow(context.Background(), `
+		SELECT from_oid, to_oid, emoji_id, voted_at FROM rating.votes FINAL
+		WHERE tenant_id = $1 AND to_oid = $2 AND from_oid = $3 AND sign > 0;
+	`, tenant, vote.ToOID, vote.FromOID).Scan(&dbVote.FromOID, &dbVote.ToOID, &dbVote.EmojiId, &dbVote.VotedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return domain.VoteDTO{}, false, err
+		}
+		return domain.VoteDTO{}, false, fmt.Errorf("GetVote: unable to execute query to DB: %w", err)
+	}
+	return dbVote, true, nil
+}
+
+func (c *ClickHouse) LastVotedAt(tenant uuid.UUID, vote domain.VoteDTO) (time.Time, error) {
+	var lastVoted time.Time
+	err := c.conn.QueryRow(context.Background(), `
+		SELECT voted_at FROM rating.votes
+		WHERE tenant_id = $1 AND from_oid = $2 AND sign > 0
+		ORDER BY voted_at DESC
+		LIMIT 1;
+	`, tenant, vote.FromOID).Scan(&lastVoted)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return time.Time{}, nil
+		}
+		return time.Time{}, fmt.Errorf("LastVotedAt: unable to execute query to DB: %w", err)
+	}
+	return lastVoted, nil
+}
+
+// UpdateProfileRating replaces oldVote by the new vote, the old vote is cancelled with the time it was cast
+// so that counts of both emojis are updated, per day as well.
+func (c *ClickHouse) UpdateProfileRating(tenant uuid.UUID, vote domain.VoteDTO, oldVote domain.VoteDTO) error {
+	version := newVersion()
+	err := c.insertVotes(tenant, []voteRow{
+		{vote: oldVote, sign: -1, version: version},
+		{vote: vote, sign: 1, version: version + 1},
+	})
+	if err != nil {
+		return fmt.Errorf("UpdateProfileRating: %w", err)
+	}
+	return nil
+}
+
+func (c *ClickHouse) GetRating(tenant uuid.UUID, userId uuid.UUID) (int, error) {
+	var rating int64
+	err := c.conn.QueryRow(context.Background(), `
+		SELECT sumMerge(votes)
+		FROM rating.votes_by_emoji
+		WHERE tenant_id = $1 AND to_oid = $2;
+	`, tenant, userId).Scan(&rating)
+	if err != nil {
+		return 0, fmt.Errorf("GetRating: unable to execute query to DB: %w", err)
+	}
+	return int(rating), nil
+}
+
+// listChunkSize is the maximum number of users whose ratings are read with a single query,
+// the driver interpolates set parameters into the query text, so it bounds the query size.
+const listChunkSize = 1000
+
+// GetRatingForList returns ratings of the users along with their breakdowns, users without votes have zero ratings.
+func (c *ClickHouse) GetRatingForList(tenant uuid.UUID, oids []uuid.UUID) (map[uuid.UUID]domain.Rating, error) {
+	counts := make(map[uuid.UUID]map[int32]int, len(oids))
+	unique := make([]uuid.UUID, 0, len(oids))
+	for _, oid := range oids {
+		if counts[oid] == nil {
+			counts[oid] = make(map[int32]int)
+			unique = append(unique, oid)
+		}
+	}
+	// votes of a user read in several chunks would be counted several times
+	oids = unique
+
+	for start := 0; start < len(oids); start += listChunkSize {
+		end := min(start+listChunkSize, len(oids))
+		values := make([]any, 0, end-start)
+		for _, oid := range oids[start:end] {
+			values = append(values, oid)
+		}
+
+		err := c.countUsersEmojis(counts, `
+			SELECT to_oid, emoji_id, sumMerge(votes)
+			FROM rating.votes_by_emoji
+			WHERE tenant_id = $1 AND to_oid IN $2
+			GROUP BY to_oid, emoji_id;
+		`, tenant, clickhouse.GroupSet{Value: values})
+		if err != nil {
+			return nil, fmt.Errorf("GetRatingForList: %w", err)
+		}
+	}
+
+	ratings := make(map[uuid.UUID]domain.Rating, len(counts))
+	for oid, emojiCounts := range counts {
+		ratings[oid] = newRating(emojiCounts)
+	}
+	return ratings, nil
+}
+
+// countUsersEmojis runs query returning triples of user oid, emoji id and amount of votes, and adds them to counts.
+func (c *ClickHouse) countUsersEmojis(counts map[uuid.UUID]map[int32]int, query string, args ...interface{}) error {
+	rows, err := c.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var oid uuid.UUID
+		var emojiId int32
+		var count int64
+		if err := rows.Scan(&oid, &emojiId, &count); err != nil {
+			return fmt.Errorf("scan the row: %w", err)
+		}
+		if counts[oid] == nil {
+			counts[oid] = make(map[int32]int)
+		}
+		counts[oid][emojiId] += int(count)
+	}
+	return rows.Err()
+}
+
+// newRating returns the rating with counts of every emoji in the order of emoji ids.
+func newRating(counts map[int32]int) domain.Rating {
+	var rating domain.Rating
+	for _, emoji := range emojis {
+		rating.Total += counts[emoji.ID]
+	}
+	for _, emoji := range emojis {
+		emojiRating := domain.EmojiRating{EmojiID: emoji.ID, Emoji: emoji.Symbol, Count: counts[emoji.ID]}
+		if rating.Total > 0 {
+			emojiRating.Share = float64(emojiRating.Count) / float64(rating.Total)
+		}
+		rating.Emojis = append(rating.Emojis, emojiRating)
+	}
+	return rating
+}
+
+// GetRatingBreakdown returns the amount of votes of every emoji received by the user in the order of emoji ids,
+// leaving out votes from excluded users.
+func (c *ClickHouse) GetRatingBreakdown(tenant uuid.UUID, userId uuid.UUID, excluded []uuid.UUID) (domain.Rating, error) {
+	counts, err := c.countEmojis(`
+		SELECT emoji_id, sumMerge(votes)
+		FROM rating.votes_by_emoji
+		WHERE tenant_id = $1 AND to_oid = $2
+		GROUP BY emoji_id;
+	`, tenant, userId)
+	if err != nil {
+		return domain.Rating{}, fmt.Errorf("GetRatingBreakdown: %w", err)
+	}
+
+	// the aggregate doesn't know who voted, so votes of excluded users are counted separately and subtracted
+	if len(excluded) > 0 {
+		excludedOIDs := make([]string, 0, len(excluded))
+		for _, oid := range excluded {
+			excludedOIDs = append(excludedOIDs, oid.String())
+		}
+		excludedCounts, err := c.countEmojis(`
+			SELECT emoji_id, toInt64(COUNT(*))
+			FROM rating.votes FINAL
+			WHERE tenant_id = $1 AND to_oid = $2 AND sign > 0 AND has($3, toString(from_oid))
+			GROUP BY emoji_id;
+		`, tenant, userId, excludedOIDs)
+		if err != nil {
+			return domain.Rating{}, fmt.Errorf("GetRatingBreakdown: %w", err)
+		}
+		for emojiId, count := range excludedCounts {
+			counts[emojiId] -= count
+		}
+	}
+
+	return newRating(counts), nil
+}
+
+// countEmojis runs query returning pairs of emoji id and amount of votes.
+func (c *ClickHouse) countEmojis(query string, args ...interface{}) (map[int32]int, error) {
+	rows, err := c.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int32]int)
+	for rows.Next() {
+		var emojiId int32
+		var count int64
+		if err := rows.Scan(&emojiId, &count); err != nil {
+			return nil, fmt.Errorf("scan the row: %w", err)
+		}
+		counts[emojiId] = int(count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return counts, nil
+}
+
+// GetTopRated returns users with the most votes of the emoji, or of any emoji if emojiID is 0, most voted first.
+// Only votes cast or changed since the start of the day of the given time are counted, all votes if since is nil.
+func (c *ClickHouse) GetTopRated(tenant uuid.UUID, emojiID int32, since *time.Time, limit int, offset int) ([]domain.RatedUser, error) {
+	args := []interface{}{tenant}
+	var query string
+	if since == nil {
+		query = `
+		SELECT to_oid, sumMerge(votes) AS total
+		FROM rating.votes_by_emoji
+		WHERE tenant_id = $1`
+	} else {
+		args = append(args, *since)
+		query = `
+		SELECT to_oid, sumMerge(votes) AS total
+		FROM rating.votes_by_day
+		WHERE tenant_id = $1 AND day >= toDate($2)`
+	}
+	if emojiID != 0 {
+		args = append(args, emojiID)
+		query += fmt.Sprintf(` AND emoji_id = $%d`, len(args))
+	}
+	args = append(args, limit, offset)
+	query += fmt.Sprintf(`
+		GROUP BY to_oid
+		HAVING total > 0
+		ORDER BY total DESC, to_oid
+		LIMIT $%d OFFSET $%d;`, len(args)-1, len(args))
+
+	rows, err := c.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetTopRated: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var users []domain.RatedUser
+	for rows.Next() {
+		var user domain.RatedUser
+		var votes int64
+		if err := rows.Scan(&user.OID, &votes); err != nil {
+			return nil, fmt.Errorf("GetTopRated: scan the row: %w", err)
+		}
+		user.Votes = int(votes)
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetTopRated: %w", err)
+	}
+	return users, nil
+}
+
+var intervalSQL = map[domain.RatingInterval]string{
+	domain.IntervalHour:  "INTERVAL 1 HOUR",
+	domain.IntervalDay:   "INTERVAL 1 DAY",
+	domain.IntervalWeek:  "INTERVAL 1 WEEK",
+	domain.IntervalMonth: "INTERVAL 1 MONTH",
+}
+
+// GetRatingHistory returns the amount of votes of every emoji received by the user per interval between from and to,
+// leaving out votes from excluded users. Votes are counted by the time they were cast or last changed, intervals
+// are aligned in the location of from and intervals without votes are returned empty.
+func (c *ClickHouse) GetRatingHistory(tenant uuid.UUID, userId uuid.UUID, excluded []uuid.UUID, interval domain.RatingInterval, from time.Time, to time.Time) ([]domain.RatingBucket, error) {
+	unit, ok := intervalSQL[interval]
+	if !ok {
+		return nil, fmt.Errorf("GetRatingHistory: unknown interval %q", interval)
+	}
+	loc := from.Location()
+	start := interval.Start(from)
+
+	args := []interface{}{tenant, userId, loc.String(), start.UTC(), to.UTC()}
+	// day, week and month intervals start on a Date, which is turned back into the time of its midnight in loc
+	query := fmt.Sprintf(`
+		SELECT toDateTime(toStartOfInterval(voted_at, %s, $3), $3) AS bucket, emoji_id, toInt64(COUNT(*))
+		FROM rating.votes FINAL
+		WHERE tenant_id = $1 AND to_oid = $2 AND sign > 0 AND voted_at >= $4 AND voted_at < $5`, unit)
+	if len(excluded) > 0 {
+		excludedOIDs := make([]string, 0, len(excluded))
+		for _, oid := range excluded {
+			excludedOIDs = append(excludedOIDs, oid.String())
+		}
+		args = append(args, excludedOIDs)
+		query += fmt.Sprintf(` AND NOT has($%d, toString(from_oid))`, len(args))
+	}
+	query += `
+		GROUP BY bucket, emoji_id;`
+
+	rows, err := c.conn.Query(context.Background(), query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("GetRatingHistory: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	counts := make(map[int64]map[int32]int)
+	for rows.Next() {
+		var bucket time.Time
+		var emojiId int32
+		var count int64
+		if err := rows.Scan(&bucket, &emojiId, &count); err != nil {
+			return nil, fmt.Errorf("GetRatingHistory: scan the row: %w", err)
+		}
+		if counts[bucket.Unix()] == nil {
+			counts[bucket.Unix()] = make(map[int32]int)
+		}
+		counts[bucket.Unix()][emojiId] = int(count)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("GetRatingHistory: %w", err)
+	}
+
+	var buckets []domain.RatingBucket
+	for bucket := start; bucket.Before(to); bucket = interval.Next(bucket) {
+		buckets = append(buckets, domain.RatingBucket{Start: bucket, Rating: newRating(counts[bucket.Unix()])})
+	}
+	return buckets, nil
+}
+
+// AnonymizeVotes removes votes received by the user and detaches votes given by the user from their oid,
+// so that ratings of other users stay the same.
+func (c *ClickHouse) AnonymizeVotes(tenant uuid.UUID, userId uuid.UUID) error {
+	received, err := c.GetVotesReceived(tenant, userId)
+	if err != nil {
+		return fmt.Errorf("AnonymizeVotes: %w", err)
+	}
+	given, err := c.GetVotesGiven(tenant, userId)
+	if err != nil {
+		return fmt.Errorf("AnonymizeVotes: %w", err)
+	}
+
+	version := newVersion()
+	rows := make([]voteRow, 0, len(received)+2*len(given))
+	for _, vote := range received {
+		rows = append(rows, voteRow{vote: vote, sign: -1, version: version})
+	}
+	// voters are part of the key, so given votes are cancelled and cast again by a random oid that belongs to nobody
+	for _, vote := range given {
+		anonymous := vote
+		anonymous.FromOID = uuid.New()
+		rows = append(rows, voteRow{vote: vote, sign: -1, version: version}, voteRow{vote: anonymous, sign: 1, version: version})
+	}
+	if len(rows) == 0 {
+		return nil
+	}
+
+	if err := c.insertVotes(tenant, rows); err != nil {
+		return fmt.Errorf("AnonymizeVotes: %w", err)
+	}
+	return nil
+}
+
+func (c *ClickHouse) GetVotesGiven(tenant uuid.UUID, userId uuid.UUID) ([]domain.VoteDTO, error) {
+	return c.getVotes(`
+		SELECT from_oid, to_oid, emoji_id, voted_at FROM rating.votes FINAL
+		WHERE tenant_id = $1 AND from_oid = $2 AND sign > 0
+		ORDER BY voted_at;
+	`, tenant, userId)
+}
+
+func (c *ClickHouse) GetVotesReceived(tenant uuid.UUID, userId uuid.UUID) ([]domain.VoteDTO, error) {
+	return c.getVotes(`
+		SELECT from_oid, to_oid, emoji_id, voted_at FROM rating.votes FINAL
+		WHERE tenant_id = $1 AND to_oid = $2 AND sign > 0
+		ORDER BY voted_at;
+	`, tenant, userId)
+}
+
+func (c *ClickHouse) getVotes(query string, tenant uuid.UUID, userId uuid.UUID) ([]domain.VoteDTO, error) {
+	rows, err := c.conn.Query(context.Background(), query, tenant, userId)
+	if err != nil {
+		return nil, fmt.Errorf("getVotes: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	var votes []domain.VoteDTO
+	for rows.Next() {
+		var vote domain.VoteDTO
+		if err := rows.Scan(&vote.FromOID, &vote.ToOID, &vote.EmojiId, &vote.VotedAt); err != nil {
+			return nil, fmt.Errorf("getVotes: scan the row: %w", err)
+		}
+		votes = append(votes, vote)
+	}
+	return votes, nil
+}
+
+func (c *ClickHouse) GetAllRatings(tenant uuid.UUID) (map[uuid.UUID]int, error) {
+	rows, err := c.conn.Query(context.Background(), `
+		SELECT to_oid, sumMerge(votes)
+		FROM rating.votes_by_emoji
+		WHERE tenant_id = $1
+		GROUP BY to_oid;
+	`, tenant)
+	if err != nil {
+		return map[uuid.UUID]int{}, fmt.Errorf("GetAllRatings: unable to execute query to DB: %w", err)
+	}
+	defer rows.Close()
+
+	ratings := make(map[uuid.UUID]int)
+	for rows.Next() {
+		var oid uuid.UUID
+		var rating int64
+		if err := rows.Scan(&oid, &rating); err != nil {
+			return map[uuid.UUID]int{}, fmt.Errorf("GetAllRatings: scan the row: %w", err)
+		}
+		ratings[oid] = int(rating)
+	}
+	return ratings, nil
+}
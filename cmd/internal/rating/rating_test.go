@@ -0,0 +1,414 @@
+package rating
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"os"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ClickHouse/clickhouse-go/v2"
+	"github.com/ClickHouse/clickhouse-go/v2/lib/driver"
+	"github.com/google/uuid"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+)
+
+// statement is a query or an insert sent to fakeConn, rows holds the values appended to a batch.
+type statement struct {
+	query string
+	args  []any
+	rows  [][]any
+}
+
+// fakeConn records statements and answers queries with rows returned by respond.
+// Methods the ClickHouse type doesn't use aren't implemented and panic through the nil embedded interface.
+type fakeConn struct {
+	driver.Conn
+
+	respond func(query string, args []any) [][]any
+
+	mu         sync.Mutex
+	statements []*statement
+}
+
+func (f *fakeConn) record(s *statement) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.statements = append(f.statements, s)
+}
+
+func (f *fakeConn) recorded() []*statement {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return append([]*statement(nil), f.statements...)
+}
+
+func (f *fakeConn) Query(ctx context.Context, query string, args ...any) (driver.Rows, error) {
+	f.record(&statement{query: query, args: args})
+	var values [][]any
+	if f.respond != nil {
+		values = f.respond(query, args)
+	}
+	return &fakeRows{values: values}, nil
+}
+
+func (f *fakeConn) QueryRow(ctx context.Context, query string, args ...any) driver.Row {
+	rows, _ := f.Query(ctx, query, args...)
+	return &fakeRow{rows: rows.(*fakeRows)}
+}
+
+func (f *fakeConn) PrepareBatch(ctx context.Context, query string, opts ...driver.PrepareBatchOption) (driver.Batch, error) {
+	s := &statement{query: query}
+	f.record(s)
+	return &fakeBatch{statement: s, conn: f}, nil
+}
+
+type fakeRows struct {
+	driver.Rows
+
+	values [][]any
+	next   int
+}
+
+func (r *fakeRows) Next() bool {
+	r.next++
+	return r.next <= len(r.values)
+}
+
+// Scan converts the values of the current row to the types of dest, like the driver does for compatible types.
+func (r *fakeRows) Scan(dest ...any) error {
+	row := r.values[r.next-1]
+	if len(row) != len(dest) {
+		return fmt.Errorf("scan %d values into %d destinations", len(row), len(dest))
+	}
+	for i, value := range row {
+		target := reflect.ValueOf(dest[i]).Elem()
+		source := reflect.ValueOf(value)
+		if !source.Type().ConvertibleTo(target.Type()) {
+			return fmt.Errorf("scan %T into %s", value, target.Type())
+		}
+		target.Set(source.Convert(target.Type()))
+	}
+	return nil
+}
+
+func (r *fakeRows) Close() error { return nil }
+func (r *fakeRows) Err() error   { return nil }
+
+type fakeRow struct {
+	driver.Row
+
+	rows *fakeRows
+}
+
+func (r *fakeRow) Scan(dest ...any) error {
+	if !r.rows.Next() {
+		return sql.ErrNoRows
+	}
+	return r.rows.Scan(dest...)
+}
+
+type fakeBatch struct {
+	driver.Batch
+
+	statement *statement
+	conn      *fakeConn
+}
+
+func (b *fakeBatch) Append(v ...any) error {
+	b.conn.mu.Lock()
+	defer b.conn.mu.Unlock()
+	b.statement.rows = append(b.statement.rows, v)
+	return nil
+}
+
+func (b *fakeBatch) Send() error { return nil }
+
+// TestQueriesScopedToTenant checks that every statement reads and writes rows of the given tenant only,
+// rating tables are shared by all organizations.
+func TestQueriesScopedToTenant(t *testing.T) {
+	tenant, user, other := uuid.New(), uuid.New(), uuid.New()
+	vote := domain.VoteDTO{FromOID: other, ToOID: user, EmojiId: 1, VotedAt: time.Now().UTC()}
+	conn := &fakeConn{respond: func(query string, args []any) [][]any {
+		switch {
+		case strings.Contains(query, "SELECT from_oid, to_oid, emoji_id, voted_at"):
+			return [][]any{{vote.FromOID, vote.ToOID, vote.EmojiId, vote.VotedAt}}
+		case strings.Contains(query, "SELECT sumMerge(votes)"):
+			return [][]any{{int64(1)}}
+		}
+		return nil
+	}}
+	c := &ClickHouse{conn: conn}
+	since := time.Now().Add(-24 * time.Hour)
+
+	calls := map[string]func() error{
+		"RateProfile": func() error { return c.RateProfile(tenant, vote) },
+		"UpdateProfileRating": func() error {
+			oldVote := vote
+			oldVote.EmojiId = 2
+			return c.UpdateProfileRating(tenant, vote, oldVote)
+		},
+		"GetVote": func() error {
+			_, _, err := c.GetVote(tenant, vote)
+			return err
+		},
+		"LastVotedAt": func() error {
+			_, err := c.LastVotedAt(tenant, vote)
+			return err
+		},
+		"GetRating": func() error {
+			_, err := c.GetRating(tenant, user)
+			return err
+		},
+		"GetRatingForList": func() error {
+			_, err := c.GetRatingForList(tenant, []uuid.UUID{user, other})
+			return err
+		},
+		"GetRatingBreakdown": func() error {
+			_, err := c.GetRatingBreakdown(tenant, user, []uuid.UUID{other})
+			return err
+		},
+		"GetTopRated": func() error {
+			_, err := c.GetTopRated(tenant, 0, nil, 10, 0)
+			return err
+		},
+		"GetTopRated since": func() error {
+			_, err := c.GetTopRated(tenant, 1, &since, 10, 0)
+			return err
+		},
+		"GetRatingHistory": func() error {
+			_, err := c.GetRatingHistory(tenant, user, []uuid.UUID{other}, domain.IntervalDay, since, time.Now())
+			return err
+		},
+		"AnonymizeVotes": func() error { return c.AnonymizeVotes(tenant, user) },
+		"GetAllRatings": func() error {
+			_, err := c.GetAllRatings(tenant)
+			return err
+		},
+	}
+	for name, call := range calls {
+		conn.statements = nil
+		if err := call(); err != nil {
+			t.Errorf("%s: %s", name, err)
+			continue
+		}
+		statements := conn.recorded()
+		if len(statements) == 0 {
+			t.Errorf("%s: no statements sent", name)
+		}
+		for _, s := range statements {
+			if strings.HasPrefix(strings.TrimSpace(s.query), "INSERT") {
+				if !strings.Contains(s.query, "(tenant_id,") {
+					t.Errorf("%s: insert doesn't set the tenant: %s", name, s.query)
+				}
+				if len(s.rows) == 0 {
+					t.Errorf("%s: nothing inserted", name)
+				}
+				for _, row := range s.rows {
+					if row[0] != tenant {
+						t.Errorf("%s: row inserted into tenant %v, want %s", name, row[0], tenant)
+					}
+				}
+				continue
+			}
+			if !strings.Contains(s.query, "WHERE tenant_id = $1") {
+				t.Errorf("%s: query isn't scoped to the tenant: %s", name, s.query)
+			}
+			if len(s.args) == 0 || s.args[0] != tenant {
+				t.Errorf("%s: query bound to tenant %v, want %s", name, s.args, tenant)
+			}
+		}
+	}
+}
+
+// TestTenantIsolation runs against a migrated ClickHouse server at TEST_CLICKHOUSE_ADDR, it only adds votes of new random
+// organizations and users.
+func TestTenantIsolation(t *testing.T) {
+	addr := os.Getenv("TEST_CLICKHOUSE_ADDR")
+	if addr == "" {
+		t.Skip("TEST_CLICKHOUSE_ADDR is not set")
+	}
+	conn, err := clickhouse.Open(&clickhouse.Options{Addr: []string{addr}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	c := &ClickHouse{conn: conn}
+
+	a, b := uuid.New(), uuid.New()
+	user, voter := uuid.New(), uuid.New()
+	vote := domain.VoteDTO{FromOID: voter, ToOID: user, EmojiId: 1, VotedAt: time.Now().UTC().Truncate(time.Second)}
+	if err := c.RateProfile(a, vote); err != nil {
+		t.Fatal(err)
+	}
+
+	// reads of the other organization don't see the vote
+	if _, exists, _ := c.GetVote(b, vote); exists {
+		t.Error("GetVote: vote of other organization is visible")
+	}
+	if rating, err := c.GetRating(b, user); err != nil || rating != 0 {
+		t.Errorf("GetRating = %d, %v, want 0", rating, err)
+	}
+	if ratings, err := c.GetRatingForList(b, []uuid.UUID{user}); err != nil || ratings[user].Total != 0 {
+		t.Errorf("GetRatingForList = %+v, %v, want zero rating", ratings, err)
+	}
+	if users, err := c.GetTopRated(b, 0, nil, 10, 0); err != nil || len(users) != 0 {
+		t.Errorf("GetTopRated = %+v, %v, want none", users, err)
+	}
+	if votes, err := c.GetVotesReceived(b, user); err != nil || len(votes) != 0 {
+		t.Errorf("GetVotesReceived = %+v, %v, want none", votes, err)
+	}
+	if ratings, err := c.GetAllRatings(b); err != nil || len(ratings) != 0 {
+		t.Errorf("GetAllRatings = %+v, %v, want none", ratings, err)
+	}
+
+	// writes of the other organization don't change the vote
+	if err := c.AnonymizeVotes(b, user); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.AnonymizeVotes(b, voter); err != nil {
+		t.Fatal(err)
+	}
+	if got, exists, err := c.GetVote(a, vote); err != nil || !exists || got.EmojiId != vote.EmojiId {
+		t.Errorf("GetVote after writes of other organization = %+v, %t, %v, want %+v", got, exists, err, vote)
+	}
+	if rating, err := c.GetRating(a, user); err != nil || rating != 1 {
+		t.Errorf("GetRating after writes of other organization = %d, %v, want 1", rating, err)
+	}
+}
+
+// listResponder answers rating queries for a list of users with a vote of every emoji in votes for each requested user.
+func listResponder(votes map[uuid.UUID]map[int32]int64) func(query string, args []any) [][]any {
+	return func(query string, args []any) [][]any {
+		var rows [][]any
+		for _, value := range args[1].(clickhouse.GroupSet).Value {
+			oid := value.(uuid.UUID)
+			for emojiId, count := range votes[oid] {
+				rows = append(rows, []any{oid, emojiId, count})
+			}
+		}
+		return rows
+	}
+}
+
+func TestGetRatingForList(t *testing.T) {
+	tenant := uuid.New()
+	voted, unvoted := uuid.New(), uuid.New()
+	votes := map[uuid.UUID]map[int32]int64{voted: {1: 2, 2: 1}}
+
+	many := make([]uuid.UUID, listChunkSize+1)
+	for i := range many {
+		many[i] = uuid.New()
+		votes[many[i]] = map[int32]int64{1: 1}
+	}
+	// the voted user is both in the first and in the second chunk
+	crossing := append([]uuid.UUID{voted}, many...)
+	crossing[len(crossing)-1] = voted
+
+	for _, tc := range []struct {
+		name    string
+		oids    []uuid.UUID
+		queries int
+		want    map[uuid.UUID]int
+	}{
+		{"empty", nil, 0, map[uuid.UUID]int{}},
+		{"without votes", []uuid.UUID{unvoted}, 1, map[uuid.UUID]int{unvoted: 0}},
+		{"duplicates", []uuid.UUID{voted, unvoted, voted}, 1, map[uuid.UUID]int{voted: 3, unvoted: 0}},
+		{"chunks", many, 2, nil},
+		{"duplicates in different chunks", crossing, 2, map[uuid.UUID]int{voted: 3}},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			conn := &fakeConn{respond: listResponder(votes)}
+			c := &ClickHouse{conn: conn}
+
+			ratings, err := c.GetRatingForList(tenant, tc.oids)
+			if err != nil {
+				t.Fatal(err)
+			}
+			statements := conn.recorded()
+			if len(statements) != tc.queries {
+				t.Errorf("%d queries sent, want %d", len(statements), tc.queries)
+			}
+			for _, s := range statements {
+				if n := len(s.args[1].(clickhouse.GroupSet).Value); n > listChunkSize {
+					t.Errorf("query reads %d users, want at most %d", n, listChunkSize)
+				}
+			}
+
+			unique := make(map[uuid.UUID]bool)
+			for _, oid := range tc.oids {
+				unique[oid] = true
+			}
+			if len(ratings) != len(unique) {
+				t.Errorf("got %d ratings, want %d", len(ratings), len(unique))
+			}
+			for oid := range unique {
+				rating, ok := ratings[oid]
+				if !ok {
+					t.Errorf("no rating of %s", oid)
+					continue
+				}
+				if len(rating.Emojis) != len(emojis) {
+					t.Errorf("rating of %s has %d emojis, want %d", oid, len(rating.Emojis), len(emojis))
+				}
+				want, ok := tc.want[oid]
+				if !ok {
+					want = 1
+				}
+				if rating.Total != want {
+					t.Errorf("rating of %s = %d, want %d", oid, rating.Total, want)
+				}
+			}
+		})
+	}
+}
+
+func TestGetTopRatedWindowReadsDailyVotes(t *testing.T) {
+	tenant := uuid.New()
+	conn := &fakeConn{}
+	c := &ClickHouse{conn: conn}
+	since := time.Now().Add(-7 * 24 * time.Hour)
+
+	if _, err := c.GetTopRated(tenant, 1, &since, 10, 0); err != nil {
+		t.Fatal(err)
+	}
+	statements := conn.recorded()
+	if len(statements) != 1 {
+		t.Fatalf("%d queries sent, want 1", len(statements))
+	}
+	query := statements[0].query
+	if !strings.Contains(query, "FROM rating.votes_by_day") || strings.Contains(query, "FINAL") {
+		t.Errorf("windowed leaderboard doesn't read daily aggregates: %s", query)
+	}
+	if args := statements[0].args; len(args) < 3 || args[1] != since || args[2] != int32(1) {
+		t.Errorf("query args = %v, want tenant, since and emoji", args)
+	}
+}
+
+func TestUpdateProfileRatingCancelsOnVoteDay(t *testing.T) {
+	conn := &fakeConn{}
+	c := &ClickHouse{conn: conn}
+	cast := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	oldVote := domain.VoteDTO{FromOID: uuid.New(), ToOID: uuid.New(), EmojiId: 1, VotedAt: cast}
+	vote := oldVote
+	vote.EmojiId, vote.VotedAt = 2, cast.Add(72*time.Hour)
+
+	if err := c.UpdateProfileRating(uuid.New(), vote, oldVote); err != nil {
+		t.Fatal(err)
+	}
+	rows := conn.recorded()[0].rows
+	if len(rows) != 2 {
+		t.Fatalf("%d rows inserted, want 2", len(rows))
+	}
+	// rows are tenant, to_oid, from_oid, emoji_id, voted_at, sign, version
+	if rows[0][3] != int32(1) || rows[0][4] != cast || rows[0][5] != int8(-1) {
+		t.Errorf("cancelling row = %v, want emoji 1 cast at %s with sign -1", rows[0], cast)
+	}
+	if rows[1][3] != int32(2) || rows[1][4] != vote.VotedAt || rows[1][5] != int8(1) {
+		t.Errorf("new row = %v, want emoji 2 cast at %s with sign 1", rows[1], vote.VotedAt)
+	}
+}
@@ -1,82 +1,168 @@
-package main
-
-import (
-	"fmt"
-
-	"github.com/joho/godotenv"
-	"github.com/labstack/echo/v4"
-	"github.com/labstack/echo/v4/middleware"
-	_ "github.com/lib/pq"
-	log "github.com/sirupsen/logrus"
-	_ "github.com/sosshik/rest-user-management/cmd/docs"
-	"github.com/sosshik/rest-user-management/cmd/internal/api"
-	"github.com/sosshik/rest-user-management/cmd/internal/cache"
-	"github.com/sosshik/rest-user-management/cmd/internal/database"
-	"github.com/sosshik/rest-user-management/cmd/internal/rating"
-	"github.com/sosshik/rest-user-management/pkg/config"
-	echoSwagger "github.com/swaggo/echo-swagger"
-)
-
-// @title User Managment API
-// @version 1.0
-// @description This is User Managment API
-
-// @host localhost:8080
-
-func init() {
-	err := godotenv.Load()
-	if err != nil {
-		log.Warn("No .env file")
-	}
-
-	cfg := config.GetConfig()
-
-	level, err := log.ParseLevel(cfg.LogLevel)
-	if err != nil {
-		fmt.Printf("Error parsing log level: %v, setting log level to info\n", err)
-		log.SetLevel(log.InfoLevel)
-	} else {
-		log.SetLevel(level)
-		fmt.Printf("log level was set to %s\n", cfg.LogLevel)
-	}
-	log.SetFormatter(&log.TextFormatter{
-		FullTimestamp: true,
-	})
-	fmt.Printf("config initialized\n")
-}
-
-func main() {
-
-	cfg := config.GetConfig()
-
-	db, err := database.NewDatabase(cfg)
-	if err != nil {
-		log.Warn(err)
-	}
-	defer db.DB.Close()
-
-	rating, err := rating.NewClickHouse(cfg)
-	if err != nil {
-		log.Warn(err)
-	}
-
-	api := api.API{DB: db, Cache: cache.NewRedis(cfg.Redis.Addr, cfg.Redis.DBIndex, cfg.Redis.ExpTimeSeconds), Rating: rating}
-
-	e := echo.New()
-
-	auth := e.Group("", middleware.BasicAuth(api.BasicAuth))
-
-	e.GET("/swagger/*", echoSwagger.WrapHandler)
-	e.POST("/api/users", api.HandleCreateUserProfile)
-	auth.POST("/api/users/login", api.HandleLogIn)
-	e.PUT("/api/users/:id", api.HandleUpdateUserProfile, api.JWTMiddleware)
-	e.PUT("/api/users/:id/password", api.HandleUpdateUserPassword, api.JWTMiddleware)
-	e.GET("/api/users/:id", api.HandleGetUserById)
-	e.GET("/api/users", api.HandleGetUsersList)
-	e.DELETE("/api/users/:id", api.HandleDeleteUser, api.JWTMiddleware)
-	e.POST("/api/vote", api.HandleVote, api.JWTMiddleware)
-	e.PUT("/api/vote", api.HandleChangeVote, api.JWTMiddleware)
-
-	e.Logger.Fatal(e.Start(":" + cfg.Port))
-
-}
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/joho/godotenv"
+	"github.com/labstack/echo/v4"
+	"github.com/labstack/echo/v4/middleware"
+	_ "github.com/lib/pq"
+	log "github.com/sirupsen/logrus"
+	_ "github.com/sosshik/rest-user-management/cmd/docs"
+	"github.com/sosshik/rest-user-management/cmd/internal/api"
+	"github.com/sosshik/rest-user-management/cmd/internal/cache"
+	"github.com/sosshik/rest-user-management/cmd/internal/database"
+	"github.com/sosshik/rest-user-management/cmd/internal/export"
+	"github.com/sosshik/rest-user-management/cmd/internal/leaderboard"
+	"github.com/sosshik/rest-user-management/cmd/internal/nickname"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+	"github.com/sosshik/rest-user-management/cmd/internal/purge"
+	"github.com/sosshik/rest-user-management/cmd/internal/rating"
+	"github.com/sosshik/rest-user-management/pkg/config"
+	echoSwagger "github.com/swaggo/echo-swagger"
+)
+
+// @title User Managment API
+// @version 1.0
+// @description This is User Managment API
+
+// @host localhost:8080
+
+func init() {
+	err := godotenv.Load()
+	if err != nil {
+		log.Warn("No .env file")
+	}
+
+	cfg := config.GetConfig()
+
+	level, err := log.ParseLevel(cfg.LogLevel)
+	if err != nil {
+		fmt.Printf("Error parsing log level: %v, setting log level to info\n", err)
+		log.SetLevel(log.InfoLevel)
+	} else {
+		log.SetLevel(level)
+		fmt.Printf("log level was set to %s\n", cfg.LogLevel)
+	}
+	log.SetFormatter(&log.TextFormatter{
+		FullTimestamp: true,
+	})
+	fmt.Printf("config initialized\n")
+}
+
+func main() {
+
+	cfg := config.GetConfig()
+
+	if len(os.Args) > 1 && os.Args[1] == "import" {
+		if err := runImport(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "reencrypt" {
+		if err := runReencrypt(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	if len(os.Args) > 1 && os.Args[1] == "sync-ratings" {
+		if err := runSyncRatings(cfg, os.Args[2:]); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
+	keyring, err := pii.Load(cfg.PII.KeyringFile)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		log.Warn(err)
+	}
+	defer db.DB.Close()
+	db.PII = keyring
+
+	rating, err := rating.NewClickHouse(cfg)
+	if err != nil {
+		log.Warn(err)
+	}
+
+	api := api.API{Orgs: db, DB: db, Cache: cache.NewRedis(cfg.Redis.Addr, cfg.Redis.DBIndex, cfg.Redis.ExpTimeSeconds, keyring), Rating: rating, Follows: db, Blocks: db, Prefs: db, Groups: db, Invites: db, Audit: db, Config: cfg, Nicknames: nickname.NewValidator(cfg.Nickname)}
+
+	api.Exporter = export.NewExporter(cfg, db, db, db, rating, db, db, db, db)
+	api.Leaderboard = leaderboard.NewRefresher(cfg, db, rating, api.Cache)
+
+	go purge.NewPurger(cfg, api.Orgs, api.DB, api.Rating, api.Cache, api.Audit).Run()
+	go api.Exporter.Run()
+	go api.Leaderboard.Run()
+
+	e := echo.New()
+	e.JSONSerializer = api.JSONSerializer()
+	e.Use(middleware.RequestID())
+	e.Use(api.TenantMiddleware)
+
+	auth := e.Group("", middleware.BasicAuth(api.BasicAuth))
+
+	e.GET("/swagger/*", echoSwagger.WrapHandler)
+	e.POST("/api/users", api.HandleCreateUserProfile)
+	auth.POST("/api/users/login", api.HandleLogIn)
+	e.PUT("/api/users/:id", api.HandleUpdateUserProfile, api.JWTMiddleware)
+	e.PUT("/api/users/:id/password", api.HandleUpdateUserPassword, api.JWTMiddleware)
+	e.GET("/api/users/:id", api.HandleGetUserById, api.OptionalJWTMiddleware)
+	e.GET("/api/users/nickname/:nickname", api.HandleResolveNickname, api.OptionalJWTMiddleware)
+	e.GET("/api/users/search", api.HandleSearchUsers, api.OptionalJWTMiddleware)
+	e.GET("/api/users", api.HandleGetUsersList, api.OptionalJWTMiddleware)
+	e.DELETE("/api/users/:id", api.HandleDeleteUser, api.JWTMiddleware)
+	e.POST("/api/admin/users/:id/restore", api.HandleRestoreUser, api.JWTMiddleware)
+	e.POST("/api/admin/users/import", api.HandleImportUsers, api.JWTMiddleware)
+	e.GET("/api/admin/users/export", api.HandleExportUsers, api.JWTMiddleware)
+	e.POST("/api/admin/users/batch", api.HandleBatchUsers, api.JWTMiddleware)
+	e.POST("/api/users/:id/export", api.HandleRequestExport, api.JWTMiddleware)
+	e.GET("/api/exports/:id", api.HandleGetExport, api.JWTMiddleware)
+	e.GET("/api/exports/:id/download", api.HandleDownloadExport)
+	e.POST("/api/vote", api.HandleVote, api.JWTMiddleware)
+	e.PUT("/api/vote", api.HandleChangeVote, api.JWTMiddleware)
+	e.GET("/api/leaderboard", api.HandleGetLeaderboard, api.OptionalJWTMiddleware)
+	e.GET("/api/users/:id/rating/history", api.HandleGetRatingHistory, api.OptionalJWTMiddleware)
+	e.GET("/api/users/:id/attributes", api.HandleGetAttributes, api.JWTMiddleware)
+	e.PUT("/api/users/:id/attributes", api.HandleUpdateAttributes, api.JWTMiddleware)
+	e.GET("/api/users/:id/privacy", api.HandleGetPrivacy, api.JWTMiddleware)
+	e.GET("/api/users/:id/preferences", api.HandleGetPreferences, api.JWTMiddleware)
+	e.PUT("/api/users/:id/preferences", api.HandleUpdatePreferences, api.JWTMiddleware)
+	e.POST("/api/users/:id/follow", api.HandleFollow, api.JWTMiddleware)
+	e.DELETE("/api/users/:id/follow", api.HandleUnfollow, api.JWTMiddleware)
+	e.GET("/api/users/:id/followers", api.HandleGetFollowers, api.OptionalJWTMiddleware)
+	e.GET("/api/users/:id/following", api.HandleGetFollowing, api.OptionalJWTMiddleware)
+	e.GET("/api/users/:id/follows/:other_id", api.HandleGetFollowRelation, api.OptionalJWTMiddleware)
+	e.POST("/api/users/:id/block", api.HandleBlock, api.JWTMiddleware)
+	e.DELETE("/api/users/:id/block", api.HandleUnblock, api.JWTMiddleware)
+	e.GET("/api/users/:id/blocks", api.HandleGetBlockedUsers, api.JWTMiddleware)
+	e.PUT("/api/users/:id/privacy", api.HandleUpdatePrivacy, api.JWTMiddleware)
+	e.GET("/api/users/:id/groups", api.HandleGetUserGroups, api.JWTMiddleware)
+	e.POST("/api/groups", api.HandleCreateGroup, api.JWTMiddleware)
+	e.GET("/api/groups", api.HandleGetGroups, api.JWTMiddleware)
+	e.GET("/api/groups/:id", api.HandleGetGroup, api.JWTMiddleware)
+	e.PUT("/api/groups/:id", api.HandleUpdateGroup, api.JWTMiddleware)
+	e.DELETE("/api/groups/:id", api.HandleDeleteGroup, api.JWTMiddleware)
+	e.GET("/api/groups/:id/members", api.HandleGetGroupMembers, api.JWTMiddleware)
+	e.POST("/api/groups/:id/members", api.HandleAddGroupMember, api.JWTMiddleware)
+	e.DELETE("/api/groups/:id/members/:user_id", api.HandleRemoveGroupMember, api.JWTMiddleware)
+	e.POST("/api/groups/:id/accept", api.HandleAcceptGroupInvitation, api.JWTMiddleware)
+	e.POST("/api/invites", api.HandleCreateInvite, api.JWTMiddleware)
+	e.GET("/api/invites", api.HandleGetInvites, api.JWTMiddleware)
+	e.DELETE("/api/invites/:code", api.HandleRevokeInvite, api.JWTMiddleware)
+	e.GET("/api/admin/attributes/schema", api.HandleGetAttributesSchema, api.JWTMiddleware)
+	e.PUT("/api/admin/attributes/schema", api.HandleSetAttributesSchema, api.JWTMiddleware)
+	e.POST("/api/admin/organizations", api.HandleCreateOrganization, api.JWTMiddleware)
+	e.GET("/api/admin/organizations", api.HandleGetOrganizations, api.JWTMiddleware)
+	e.GET("/api/admin/audit", api.HandleGetAudit, api.JWTMiddleware)
+
+	e.Logger.Fatal(e.Start(":" + cfg.Port))
+
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/database"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/rating"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+// runSyncRatings copies ratings counted by ClickHouse to profiles, which lists are sorted by, e.g.
+//
+//	go run ./cmd sync-ratings -tenant acme
+//
+// Votes keep ratings up to date, so it's needed after migrating and if copying a rating failed.
+func runSyncRatings(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("sync-ratings", flag.ExitOnError)
+	tenant := flags.String("tenant", "", "slug of the organization to sync, all organizations by default")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 {
+		return errors.New("usage: sync-ratings [-tenant slug]")
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.DB.Close()
+
+	ch, err := rating.NewClickHouse(cfg)
+	if err != nil {
+		return err
+	}
+
+	var orgs []domain.Organization
+	if *tenant != "" {
+		org, err := db.GetOrganizationBySlug(*tenant)
+		if err != nil {
+			return fmt.Errorf("unable to find organization %q: %w", *tenant, err)
+		}
+		orgs = append(orgs, org)
+	} else {
+		orgs, err = db.GetOrganizations()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, org := range orgs {
+		ratings, err := ch.GetAllRatings(org.ID)
+		if err != nil {
+			return fmt.Errorf("organization %q: %w", org.Slug, err)
+		}
+		updated, err := db.SyncRatings(org.ID, ratings)
+		if err != nil {
+			return fmt.Errorf("organization %q: %w", org.Slug, err)
+		}
+		fmt.Printf("%s: %d users updated\n", org.Slug, updated)
+	}
+	return nil
+}
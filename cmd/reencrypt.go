@@ -0,0 +1,67 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+
+	"github.com/sosshik/rest-user-management/cmd/internal/database"
+	"github.com/sosshik/rest-user-management/cmd/internal/domain"
+	"github.com/sosshik/rest-user-management/cmd/internal/pii"
+	"github.com/sosshik/rest-user-management/pkg/config"
+)
+
+// runReencrypt encrypts names stored in plaintext or with an old key using the active key of the keyring, e.g.
+//
+//	PII_KEYRING_FILE=keyring.json go run ./cmd reencrypt -tenant acme
+//
+// With -decrypt names are stored in plaintext again, so that encryption can be turned off.
+func runReencrypt(cfg *config.Config, args []string) error {
+	flags := flag.NewFlagSet("reencrypt", flag.ExitOnError)
+	tenant := flags.String("tenant", "", "slug of the organization to reencrypt, all organizations by default")
+	chunkSize := flags.Int("chunk-size", 500, "number of users read at once")
+	decrypt := flags.Bool("decrypt", false, "store names in plaintext instead of encrypting them")
+	flags.Parse(args)
+
+	if flags.NArg() != 0 || *chunkSize <= 0 {
+		return errors.New("usage: reencrypt [-tenant slug] [-chunk-size n] [-decrypt]")
+	}
+
+	keyring, err := pii.Load(cfg.PII.KeyringFile)
+	if err != nil {
+		return err
+	}
+	if keyring == nil {
+		return errors.New("PII_KEYRING_FILE is not set")
+	}
+
+	db, err := database.NewDatabase(cfg)
+	if err != nil {
+		return err
+	}
+	defer db.DB.Close()
+	db.PII = keyring
+
+	var orgs []domain.Organization
+	if *tenant != "" {
+		org, err := db.GetOrganizationBySlug(*tenant)
+		if err != nil {
+			return fmt.Errorf("unable to find organization %q: %w", *tenant, err)
+		}
+		orgs = append(orgs, org)
+	} else {
+		orgs, err = db.GetOrganizations()
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, org := range orgs {
+		updated, err := db.ReencryptNames(org.ID, *chunkSize, *decrypt)
+		if err != nil {
+			return fmt.Errorf("organization %q: %w", org.Slug, err)
+		}
+		fmt.Printf("%s: %d users updated\n", org.Slug, updated)
+	}
+	return nil
+}
@@ -0,0 +1,10 @@
+-- +goose Up
+
+-- existing votes belong to the default organization
+ALTER TABLE rating.emotes
+ADD COLUMN IF NOT EXISTS tenant_id UUID DEFAULT toUUID('00000000-0000-0000-0000-000000000001') FIRST;
+
+-- +goose Down
+
+ALTER TABLE rating.emotes
+DROP COLUMN IF EXISTS tenant_id;
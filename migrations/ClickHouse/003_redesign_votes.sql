@@ -0,0 +1,45 @@
+-- +goose Up
+
+-- the latest version of a vote replaces older ones, a vote with sign -1 cancels the previous one
+CREATE TABLE IF NOT EXISTS rating.votes (
+    tenant_id UUID,
+    to_oid UUID,
+    from_oid UUID,
+    emoji_id Int,
+    voted_at DateTime,
+    sign Int8,
+    version UInt64,
+    INDEX from_oid_idx from_oid TYPE bloom_filter GRANULARITY 4
+) ENGINE = ReplacingMergeTree(version)
+ORDER BY (tenant_id, to_oid, from_oid);
+
+CREATE TABLE IF NOT EXISTS rating.votes_by_emoji (
+    tenant_id UUID,
+    to_oid UUID,
+    emoji_id Int,
+    votes AggregateFunction(sum, Int64)
+) ENGINE = AggregatingMergeTree()
+ORDER BY (tenant_id, to_oid, emoji_id);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS rating.votes_by_emoji_mv TO rating.votes_by_emoji AS
+SELECT tenant_id, to_oid, emoji_id, sumState(toInt64(sign)) AS votes
+FROM rating.votes
+GROUP BY tenant_id, to_oid, emoji_id;
+
+-- the view is filled along with the backfill, the old table is left as it is in case of a rollback
+INSERT INTO rating.votes (tenant_id, to_oid, from_oid, emoji_id, voted_at, sign, version)
+SELECT tenant_id, to_oid, from_oid, emoji_id, voted_at, 1, toUInt64(toUnixTimestamp(voted_at)) * 1000000000
+FROM rating.emotes;
+
+-- +goose Down
+
+TRUNCATE TABLE IF EXISTS rating.emotes;
+
+INSERT INTO rating.emotes (tenant_id, from_oid, to_oid, emoji_id, voted_at)
+SELECT tenant_id, from_oid, to_oid, emoji_id, voted_at
+FROM rating.votes FINAL
+WHERE sign > 0;
+
+DROP VIEW IF EXISTS rating.votes_by_emoji_mv;
+DROP TABLE IF EXISTS rating.votes_by_emoji;
+DROP TABLE IF EXISTS rating.votes;
@@ -0,0 +1,31 @@
+-- +goose Up
+
+-- windowed leaderboards sum votes per day instead of reading every vote, a cancelled vote is subtracted
+-- from the day it was cast
+CREATE TABLE IF NOT EXISTS rating.votes_by_day (
+    tenant_id UUID,
+    day Date,
+    to_oid UUID,
+    emoji_id Int,
+    votes AggregateFunction(sum, Int64)
+) ENGINE = AggregatingMergeTree()
+PARTITION BY toYYYYMM(day)
+ORDER BY (tenant_id, day, to_oid, emoji_id);
+
+CREATE MATERIALIZED VIEW IF NOT EXISTS rating.votes_by_day_mv TO rating.votes_by_day AS
+SELECT tenant_id, toDate(voted_at) AS day, to_oid, emoji_id, sumState(toInt64(sign)) AS votes
+FROM rating.votes
+GROUP BY tenant_id, day, to_oid, emoji_id;
+
+-- cancelling rows written so far carry the time of the change rather than of the vote, so current votes are
+-- backfilled instead of every row, votes cast while the migration runs may be counted twice
+INSERT INTO rating.votes_by_day (tenant_id, day, to_oid, emoji_id, votes)
+SELECT tenant_id, toDate(voted_at) AS day, to_oid, emoji_id, sumState(toInt64(1))
+FROM rating.votes FINAL
+WHERE sign > 0
+GROUP BY tenant_id, day, to_oid, emoji_id;
+
+-- +goose Down
+
+DROP VIEW IF EXISTS rating.votes_by_day_mv;
+DROP TABLE IF EXISTS rating.votes_by_day;
@@ -0,0 +1,25 @@
+-- +goose Up
+ALTER TABLE user_profiles
+ADD COLUMN attributes JSONB NOT NULL DEFAULT '{}'::jsonb,
+ADD COLUMN attributes_visibility JSONB NOT NULL DEFAULT '{}'::jsonb;
+
+CREATE INDEX IF NOT EXISTS user_profiles_attributes_idx ON user_profiles USING GIN (attributes jsonb_path_ops);
+
+CREATE TABLE IF NOT EXISTS attributes_schema (
+    id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+    schema JSONB NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+INSERT INTO attributes_schema (id, schema)
+VALUES (1, '{"type": "object"}'::jsonb)
+ON CONFLICT (id) DO NOTHING;
+
+-- +goose Down
+DROP TABLE IF EXISTS attributes_schema;
+
+DROP INDEX IF EXISTS user_profiles_attributes_idx;
+
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS attributes,
+DROP COLUMN IF EXISTS attributes_visibility;
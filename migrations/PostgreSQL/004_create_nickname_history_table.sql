@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS nickname_history (
+    id SERIAL PRIMARY KEY,
+    oid UUID NOT NULL,
+    old_nickname VARCHAR(255) NOT NULL,
+    new_nickname VARCHAR(255) NOT NULL,
+    changed_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS nickname_history_oid_idx ON nickname_history (oid, changed_at DESC);
+-- former nicknames are looked up case-insensitively
+CREATE INDEX IF NOT EXISTS nickname_history_old_nickname_idx ON nickname_history (lower(old_nickname), changed_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS nickname_history;
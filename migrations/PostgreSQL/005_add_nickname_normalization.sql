@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE user_profiles
+ADD COLUMN nickname_normalized VARCHAR(255),
+ADD COLUMN nickname_skeleton VARCHAR(255);
+
+-- nicknames differing only in case would break the unique index, so in every such group the oldest profile
+-- keeps its nickname and the others get a suffix from their oid, e.g. "Bob" becomes "Bob_3f2a9c1e07d4".
+-- Renamed users keep the new nickname until they change it, the rename isn't recorded in nickname history
+-- so it doesn't start the change cooldown. The rename isn't reverted on Down.
+UPDATE user_profiles u
+SET nickname = u.nickname || '_' || left(replace(u.oid::text, '-', ''), 12)
+FROM (
+    SELECT id, row_number() OVER (PARTITION BY lower(nickname) ORDER BY created_at, id) AS n
+    FROM user_profiles
+) dup
+WHERE u.id = dup.id AND dup.n > 1;
+
+UPDATE user_profiles SET nickname_normalized = lower(nickname);
+
+-- skeletons are left NULL, which the unique index doesn't compare, and are computed by the application
+-- on the next profile update. A skeleton colliding with one that is already filled stays empty, so existing
+-- confusable nicknames like "paypal" and "pаypal" (Cyrillic "а") are both kept while new ones are rejected.
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_nickname_normalized_idx ON user_profiles (nickname_normalized);
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_nickname_skeleton_idx ON user_profiles (nickname_skeleton);
+
+-- +goose Down
+DROP INDEX IF EXISTS user_profiles_nickname_skeleton_idx;
+DROP INDEX IF EXISTS user_profiles_nickname_normalized_idx;
+
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS nickname_normalized,
+DROP COLUMN IF EXISTS nickname_skeleton;
@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE user_profiles
+ADD COLUMN deleted_at TIMESTAMPTZ;
+
+CREATE INDEX IF NOT EXISTS user_profiles_deleted_at_idx ON user_profiles (deleted_at) WHERE deleted_at IS NOT NULL;
+
+-- +goose Down
+DROP INDEX IF EXISTS user_profiles_deleted_at_idx;
+
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS deleted_at;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS data_exports (
+    id SERIAL PRIMARY KEY,
+    oid UUID UNIQUE NOT NULL,
+    user_oid UUID NOT NULL,
+    requested_by UUID NOT NULL,
+    status VARCHAR(16) NOT NULL,
+    file_path VARCHAR(1024),
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMPTZ,
+    expires_at TIMESTAMPTZ
+);
+
+CREATE INDEX IF NOT EXISTS data_exports_expires_at_idx ON data_exports (expires_at);
+
+-- +goose Down
+DROP TABLE IF EXISTS data_exports;
@@ -0,0 +1,21 @@
+-- +goose Up
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE user_profiles
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(nickname, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(last_name, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS user_profiles_search_vector_idx ON user_profiles USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS user_profiles_nickname_trgm_idx ON user_profiles USING GIN (nickname gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS user_profiles_first_name_trgm_idx ON user_profiles USING GIN (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS user_profiles_last_name_trgm_idx ON user_profiles USING GIN (last_name gin_trgm_ops);
+
+-- +goose Down
+DROP INDEX IF EXISTS user_profiles_last_name_trgm_idx;
+DROP INDEX IF EXISTS user_profiles_first_name_trgm_idx;
+DROP INDEX IF EXISTS user_profiles_nickname_trgm_idx;
+DROP INDEX IF EXISTS user_profiles_search_vector_idx;
+
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,5 @@
+-- +goose Up
+CREATE INDEX IF NOT EXISTS user_profiles_created_at_oid_idx ON user_profiles (created_at, oid);
+
+-- +goose Down
+DROP INDEX IF EXISTS user_profiles_created_at_oid_idx;
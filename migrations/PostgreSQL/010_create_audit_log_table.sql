@@ -0,0 +1,15 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS audit_log (
+    id BIGSERIAL PRIMARY KEY,
+    actor_oid UUID NOT NULL,
+    target_oid UUID NOT NULL,
+    action VARCHAR(32) NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE INDEX IF NOT EXISTS audit_log_target_oid_idx ON audit_log (target_oid, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS audit_log;
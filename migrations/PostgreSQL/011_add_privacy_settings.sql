@@ -0,0 +1,11 @@
+-- +goose Up
+ALTER TABLE user_profiles
+ADD COLUMN profile_visibility VARCHAR(16) NOT NULL DEFAULT 'public',
+ADD COLUMN real_name_visibility VARCHAR(16) NOT NULL DEFAULT 'everyone',
+ADD COLUMN hide_rating_breakdown BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- +goose Down
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS hide_rating_breakdown,
+DROP COLUMN IF EXISTS real_name_visibility,
+DROP COLUMN IF EXISTS profile_visibility;
@@ -0,0 +1,20 @@
+-- +goose Up
+ALTER TABLE user_profiles
+ADD CONSTRAINT user_profiles_oid_key UNIQUE (oid);
+
+CREATE TABLE IF NOT EXISTS follows (
+    follower_oid UUID NOT NULL REFERENCES user_profiles (oid) ON DELETE CASCADE,
+    followee_oid UUID NOT NULL REFERENCES user_profiles (oid) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (follower_oid, followee_oid),
+    CHECK (follower_oid <> followee_oid)
+);
+
+CREATE INDEX IF NOT EXISTS follows_followee_oid_idx ON follows (followee_oid, created_at DESC);
+CREATE INDEX IF NOT EXISTS follows_follower_oid_idx ON follows (follower_oid, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS follows;
+
+ALTER TABLE user_profiles
+DROP CONSTRAINT IF EXISTS user_profiles_oid_key;
@@ -0,0 +1,13 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS blocks (
+    blocker_oid UUID NOT NULL REFERENCES user_profiles (oid) ON DELETE CASCADE,
+    blocked_oid UUID NOT NULL REFERENCES user_profiles (oid) ON DELETE CASCADE,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (blocker_oid, blocked_oid),
+    CHECK (blocker_oid <> blocked_oid)
+);
+
+CREATE INDEX IF NOT EXISTS blocks_blocker_oid_idx ON blocks (blocker_oid, created_at DESC);
+
+-- +goose Down
+DROP TABLE IF EXISTS blocks;
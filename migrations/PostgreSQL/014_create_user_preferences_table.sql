@@ -0,0 +1,14 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS user_preferences (
+    oid UUID PRIMARY KEY REFERENCES user_profiles (oid) ON DELETE CASCADE,
+    locale VARCHAR(16) NOT NULL,
+    timezone VARCHAR(64) NOT NULL,
+    date_format VARCHAR(16) NOT NULL,
+    notify_votes BOOLEAN NOT NULL,
+    notify_follows BOOLEAN NOT NULL,
+    notify_newsletter BOOLEAN NOT NULL,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- +goose Down
+DROP TABLE IF EXISTS user_preferences;
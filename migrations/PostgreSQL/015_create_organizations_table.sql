@@ -0,0 +1,86 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS organizations (
+    id UUID PRIMARY KEY,
+    slug VARCHAR(63) UNIQUE NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+
+-- existing users, exports, audit records and the attributes schema are moved to the default organization
+INSERT INTO organizations (id, slug, name)
+VALUES ('00000000-0000-0000-0000-000000000001', 'default', 'Default')
+ON CONFLICT DO NOTHING;
+
+ALTER TABLE user_profiles
+ADD COLUMN tenant_id UUID REFERENCES organizations (id);
+UPDATE user_profiles SET tenant_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE user_profiles
+ALTER COLUMN tenant_id SET NOT NULL;
+
+-- nicknames are unique within an organization only
+ALTER TABLE user_profiles
+DROP CONSTRAINT IF EXISTS user_profiles_nickname_key;
+DROP INDEX IF EXISTS user_profiles_nickname_normalized_idx;
+DROP INDEX IF EXISTS user_profiles_nickname_skeleton_idx;
+DROP INDEX IF EXISTS user_profiles_created_at_oid_idx;
+
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_tenant_nickname_idx ON user_profiles (tenant_id, nickname);
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_tenant_nickname_normalized_idx ON user_profiles (tenant_id, nickname_normalized);
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_tenant_nickname_skeleton_idx ON user_profiles (tenant_id, nickname_skeleton);
+CREATE INDEX IF NOT EXISTS user_profiles_tenant_created_at_oid_idx ON user_profiles (tenant_id, created_at, oid);
+
+ALTER TABLE data_exports
+ADD COLUMN tenant_id UUID REFERENCES organizations (id);
+UPDATE data_exports SET tenant_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE data_exports
+ALTER COLUMN tenant_id SET NOT NULL;
+
+ALTER TABLE audit_log
+ADD COLUMN tenant_id UUID REFERENCES organizations (id);
+UPDATE audit_log SET tenant_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE audit_log
+ALTER COLUMN tenant_id SET NOT NULL;
+
+-- every organization has its own attributes schema
+ALTER TABLE attributes_schema
+ADD COLUMN tenant_id UUID REFERENCES organizations (id);
+UPDATE attributes_schema SET tenant_id = '00000000-0000-0000-0000-000000000001';
+ALTER TABLE attributes_schema
+DROP COLUMN id,
+ALTER COLUMN tenant_id SET NOT NULL,
+ADD PRIMARY KEY (tenant_id);
+
+-- +goose Down
+-- only the default organization can be kept once nicknames are unique globally again
+DELETE FROM audit_log WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM data_exports WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM attributes_schema WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM nickname_history h
+USING user_profiles u
+WHERE u.oid = h.oid AND u.tenant_id <> '00000000-0000-0000-0000-000000000001';
+DELETE FROM user_profiles WHERE tenant_id <> '00000000-0000-0000-0000-000000000001';
+
+ALTER TABLE attributes_schema
+DROP CONSTRAINT IF EXISTS attributes_schema_pkey,
+ADD COLUMN id INT PRIMARY KEY DEFAULT 1 CHECK (id = 1),
+DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE audit_log
+DROP COLUMN IF EXISTS tenant_id;
+
+ALTER TABLE data_exports
+DROP COLUMN IF EXISTS tenant_id;
+
+DROP INDEX IF EXISTS user_profiles_tenant_created_at_oid_idx;
+DROP INDEX IF EXISTS user_profiles_tenant_nickname_skeleton_idx;
+DROP INDEX IF EXISTS user_profiles_tenant_nickname_normalized_idx;
+DROP INDEX IF EXISTS user_profiles_tenant_nickname_idx;
+
+CREATE INDEX IF NOT EXISTS user_profiles_created_at_oid_idx ON user_profiles (created_at, oid);
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_nickname_skeleton_idx ON user_profiles (nickname_skeleton);
+CREATE UNIQUE INDEX IF NOT EXISTS user_profiles_nickname_normalized_idx ON user_profiles (nickname_normalized);
+ALTER TABLE user_profiles
+ADD CONSTRAINT user_profiles_nickname_key UNIQUE (nickname),
+DROP COLUMN IF EXISTS tenant_id;
+
+DROP TABLE IF EXISTS organizations;
@@ -0,0 +1,22 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS groups (
+    oid UUID PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES organizations (id),
+    name VARCHAR(255) NOT NULL,
+    description TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP
+);
+CREATE UNIQUE INDEX IF NOT EXISTS groups_tenant_name_idx ON groups (tenant_id, lower(name));
+
+CREATE TABLE IF NOT EXISTS group_members (
+    group_oid UUID NOT NULL REFERENCES groups (oid) ON DELETE CASCADE,
+    user_oid UUID NOT NULL REFERENCES user_profiles (oid) ON DELETE CASCADE,
+    role VARCHAR(16) NOT NULL DEFAULT 'member' CHECK (role IN ('owner', 'member')),
+    joined_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (group_oid, user_oid)
+);
+CREATE INDEX IF NOT EXISTS group_members_user_oid_idx ON group_members (user_oid, joined_at DESC);
+-- +goose Down
+DROP TABLE IF EXISTS group_members;
+DROP TABLE IF EXISTS groups;
@@ -0,0 +1,17 @@
+-- +goose Up
+CREATE TABLE IF NOT EXISTS invites (
+    code VARCHAR(32) PRIMARY KEY,
+    tenant_id UUID NOT NULL REFERENCES organizations (id),
+    created_by UUID REFERENCES user_profiles (oid) ON DELETE SET NULL,
+    user_role INTEGER NOT NULL DEFAULT 1,
+    group_oid UUID REFERENCES groups (oid) ON DELETE SET NULL,
+    max_uses INTEGER NOT NULL DEFAULT 1 CHECK (max_uses > 0),
+    uses INTEGER NOT NULL DEFAULT 0,
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ DEFAULT CURRENT_TIMESTAMP,
+    CHECK (uses <= max_uses)
+);
+CREATE INDEX IF NOT EXISTS invites_tenant_created_at_idx ON invites (tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS invites_created_by_idx ON invites (created_by, created_at DESC);
+-- +goose Down
+DROP TABLE IF EXISTS invites;
@@ -0,0 +1,33 @@
+-- +goose Up
+ALTER TABLE audit_log
+ADD COLUMN ip VARCHAR(64),
+ADD COLUMN request_id VARCHAR(64);
+
+CREATE INDEX IF NOT EXISTS audit_log_tenant_created_at_idx ON audit_log (tenant_id, created_at DESC, id DESC);
+CREATE INDEX IF NOT EXISTS audit_log_actor_oid_idx ON audit_log (actor_oid, created_at DESC);
+
+-- audit records are never changed or removed once written
+-- +goose StatementBegin
+CREATE OR REPLACE FUNCTION audit_log_append_only()
+RETURNS TRIGGER
+AS $$
+BEGIN
+    RAISE EXCEPTION 'audit_log is append-only';
+END;
+$$ LANGUAGE plpgsql;
+-- +goose StatementEnd
+
+CREATE TRIGGER audit_log_append_only
+BEFORE UPDATE OR DELETE ON audit_log
+FOR EACH ROW EXECUTE FUNCTION audit_log_append_only();
+
+-- +goose Down
+DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+DROP FUNCTION IF EXISTS audit_log_append_only();
+
+DROP INDEX IF EXISTS audit_log_actor_oid_idx;
+DROP INDEX IF EXISTS audit_log_tenant_created_at_idx;
+
+ALTER TABLE audit_log
+DROP COLUMN IF EXISTS request_id,
+DROP COLUMN IF EXISTS ip;
@@ -0,0 +1,52 @@
+-- +goose Up
+-- encrypted names don't fit into 255 characters, and aren't worth indexing for full-text search
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE user_profiles
+ALTER COLUMN first_name TYPE VARCHAR,
+ALTER COLUMN last_name TYPE VARCHAR,
+ADD COLUMN first_name_idx VARCHAR(64),
+ADD COLUMN last_name_idx VARCHAR(64);
+
+ALTER TABLE user_profiles
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(nickname, '')
+        || ' ' || (CASE WHEN first_name LIKE 'pii:%' THEN '' ELSE coalesce(first_name, '') END)
+        || ' ' || (CASE WHEN last_name LIKE 'pii:%' THEN '' ELSE coalesce(last_name, '') END))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS user_profiles_search_vector_idx ON user_profiles USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS user_profiles_tenant_first_name_idx_idx ON user_profiles (tenant_id, first_name_idx);
+CREATE INDEX IF NOT EXISTS user_profiles_tenant_last_name_idx_idx ON user_profiles (tenant_id, last_name_idx);
+
+-- trigrams of ciphertext are noise, only names stored in plaintext are indexed for similarity search
+DROP INDEX IF EXISTS user_profiles_first_name_trgm_idx;
+DROP INDEX IF EXISTS user_profiles_last_name_trgm_idx;
+CREATE INDEX IF NOT EXISTS user_profiles_first_name_trgm_idx ON user_profiles USING GIN (first_name gin_trgm_ops) WHERE first_name NOT LIKE 'pii:%';
+CREATE INDEX IF NOT EXISTS user_profiles_last_name_trgm_idx ON user_profiles USING GIN (last_name gin_trgm_ops) WHERE last_name NOT LIKE 'pii:%';
+
+-- +goose Down
+-- names have to be decrypted with the reencrypt command run without a keyring before going down
+DROP INDEX IF EXISTS user_profiles_tenant_last_name_idx_idx;
+DROP INDEX IF EXISTS user_profiles_tenant_first_name_idx_idx;
+DROP INDEX IF EXISTS user_profiles_last_name_trgm_idx;
+DROP INDEX IF EXISTS user_profiles_first_name_trgm_idx;
+
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS search_vector;
+
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS last_name_idx,
+DROP COLUMN IF EXISTS first_name_idx,
+ALTER COLUMN last_name TYPE VARCHAR(255),
+ALTER COLUMN first_name TYPE VARCHAR(255);
+
+ALTER TABLE user_profiles
+ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('simple', coalesce(nickname, '') || ' ' || coalesce(first_name, '') || ' ' || coalesce(last_name, ''))
+) STORED;
+
+CREATE INDEX IF NOT EXISTS user_profiles_search_vector_idx ON user_profiles USING GIN (search_vector);
+CREATE INDEX IF NOT EXISTS user_profiles_first_name_trgm_idx ON user_profiles USING GIN (first_name gin_trgm_ops);
+CREATE INDEX IF NOT EXISTS user_profiles_last_name_trgm_idx ON user_profiles USING GIN (last_name gin_trgm_ops);
@@ -0,0 +1,20 @@
+-- +goose Up
+-- users added to a group by its owners are invited until they accept, and owners edit profiles of members
+-- only if the member accepted or was added by a moderator or an admin
+-- it's unknown who added existing members, so they stay active without granting edit rights
+-- until a moderator or an admin adds them again
+ALTER TABLE group_members
+ADD COLUMN status VARCHAR(16) NOT NULL DEFAULT 'active' CHECK (status IN ('invited', 'active')),
+ADD COLUMN edit_granted BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE INDEX IF NOT EXISTS group_members_user_status_idx ON group_members (user_oid, status, joined_at DESC);
+
+-- +goose Down
+-- invitations weren't memberships before
+DELETE FROM group_members WHERE status = 'invited';
+
+DROP INDEX IF EXISTS group_members_user_status_idx;
+
+ALTER TABLE group_members
+DROP COLUMN IF EXISTS edit_granted,
+DROP COLUMN IF EXISTS status;
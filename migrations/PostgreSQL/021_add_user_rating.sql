@@ -0,0 +1,9 @@
+-- +goose Up
+-- ratings are copied from ClickHouse when users are voted for, so that lists are sorted by rating without reading
+-- every rating of the organization, votes cast before are copied by the sync-ratings command
+ALTER TABLE user_profiles
+ADD COLUMN rating INTEGER NOT NULL DEFAULT 0;
+
+-- +goose Down
+ALTER TABLE user_profiles
+DROP COLUMN IF EXISTS rating;
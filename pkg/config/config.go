@@ -1,65 +1,210 @@
-package config
-
-import (
-	"fmt"
-	"sync"
-
-	"github.com/caarlos0/env"
-	log "github.com/sirupsen/logrus"
-)
-
-type Config struct {
-	Port        string `env:"PORT"`
-	LogLevel    string `env:"LOG_LEVEL" envDefault:"info"`
-	DbUrl       string `env:"DATABASE_URL"`
-	ReconnTime  int    `env:"RECONN_TIME" envDefault:"5"`
-	ConnCheck   bool   `env:"CONN_CHECK" envDefault:"true"`
-	ReconnTries int    `env:"RECONN_TRIES" envDefault:"5"`
-	Redis       Redis
-	CH          ClickHouseConfig
-}
-type Redis struct {
-	Addr           string `env:"REDIS_ADDR" envDefault:"localhost:6379"`
-	ExpTimeSeconds int    `env:"REDIS_EXP_TIME" envDefault:"60"`
-	DBIndex        int    `env:"REDIS_DB_INDEX" envDefault:"0"`
-}
-
-type ClickHouseConfig struct {
-	Addr string `env:"CH_ADDR" envDefault:"localhost:9000"`
-	DB   string `env:"CH_DB"`
-	User string `env:"CH_USER" envDefault:"default"`
-	Pass string `env:"CH_PASS" envDefault:""`
-}
-
-var once sync.Once
-
-var configInstance *Config
-
-func GetConfig() *Config {
-	if configInstance == nil {
-		once.Do(func() {
-			fmt.Println("Creating config instance now.")
-
-			var cfg Config
-			var redis Redis
-			var ch ClickHouseConfig
-
-			if err := env.Parse(&cfg); err != nil {
-				log.Fatal(err)
-			}
-			if err := env.Parse(&redis); err != nil {
-				log.Fatal(err)
-			}
-			if err := env.Parse(&ch); err != nil {
-				log.Fatal(err)
-			}
-			cfg.Redis = redis
-			cfg.CH = ch
-
-			configInstance = &cfg
-		})
-
-	}
-
-	return configInstance
-}
+package config
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/caarlos0/env"
+	log "github.com/sirupsen/logrus"
+)
+
+type Config struct {
+	Port         string `env:"PORT"`
+	LogLevel     string `env:"LOG_LEVEL" envDefault:"info"`
+	DbUrl        string `env:"DATABASE_URL"`
+	ReconnTime   int    `env:"RECONN_TIME" envDefault:"5"`
+	ConnCheck    bool   `env:"CONN_CHECK" envDefault:"true"`
+	ReconnTries  int    `env:"RECONN_TRIES" envDefault:"5"`
+	Redis        Redis
+	CH           ClickHouseConfig
+	Nickname     NicknameConfig
+	Deletion     DeletionConfig
+	Export       ExportConfig
+	Import       ImportConfig
+	Batch        BatchConfig
+	Block        BlockConfig
+	Preferences  PreferencesConfig
+	Tenant       TenantConfig
+	Registration RegistrationConfig
+	PII          PIIConfig
+	Leaderboard  LeaderboardConfig
+}
+type Redis struct {
+	Addr           string `env:"REDIS_ADDR" envDefault:"localhost:6379"`
+	ExpTimeSeconds int    `env:"REDIS_EXP_TIME" envDefault:"60"`
+	DBIndex        int    `env:"REDIS_DB_INDEX" envDefault:"0"`
+}
+
+type ClickHouseConfig struct {
+	Addr string `env:"CH_ADDR" envDefault:"localhost:9000"`
+	DB   string `env:"CH_DB"`
+	User string `env:"CH_USER" envDefault:"default"`
+	Pass string `env:"CH_PASS" envDefault:""`
+}
+
+type NicknameConfig struct {
+	ChangeCooldownHours int      `env:"NICKNAME_CHANGE_COOLDOWN_HOURS" envDefault:"720"`
+	ReservationHours    int      `env:"NICKNAME_RESERVATION_HOURS" envDefault:"2160"`
+	MinLength           int      `env:"NICKNAME_MIN_LENGTH" envDefault:"3"`
+	MaxLength           int      `env:"NICKNAME_MAX_LENGTH" envDefault:"32"`
+	Reserved            []string `env:"NICKNAME_RESERVED" envSeparator:"," envDefault:"admin,administrator,moderator,root,support,system,api,null,undefined"`
+}
+
+type DeletionConfig struct {
+	RetentionHours       int  `env:"DELETED_RETENTION_HOURS" envDefault:"720"`
+	PurgeIntervalMinutes int  `env:"PURGE_INTERVAL_MINUTES" envDefault:"60"`
+	ReleaseNickname      bool `env:"DELETE_RELEASE_NICKNAME" envDefault:"true"`
+}
+
+type ExportConfig struct {
+	Dir            string `env:"EXPORT_DIR" envDefault:"exports"`
+	RetentionHours int    `env:"EXPORT_RETENTION_HOURS" envDefault:"24"`
+	LinkTTLMinutes int    `env:"EXPORT_LINK_TTL_MINUTES" envDefault:"60"`
+	UsersChunkSize int    `env:"EXPORT_USERS_CHUNK_SIZE" envDefault:"1000"`
+}
+
+type ImportConfig struct {
+	BatchSize int `env:"IMPORT_BATCH_SIZE" envDefault:"500"`
+}
+
+type BatchConfig struct {
+	MaxUsers  int `env:"BATCH_MAX_USERS" envDefault:"10000"`
+	ChunkSize int `env:"BATCH_CHUNK_SIZE" envDefault:"500"`
+}
+
+type BlockConfig struct {
+	ExcludeVotes bool `env:"BLOCK_EXCLUDE_VOTES" envDefault:"true"`
+}
+
+// PreferencesConfig holds preferences of users that haven't set their own.
+type PreferencesConfig struct {
+	Locale           string `env:"PREFERENCES_LOCALE" envDefault:"en"`
+	Timezone         string `env:"PREFERENCES_TIMEZONE" envDefault:"UTC"`
+	DateFormat       string `env:"PREFERENCES_DATE_FORMAT" envDefault:"iso"`
+	NotifyVotes      bool   `env:"PREFERENCES_NOTIFY_VOTES" envDefault:"true"`
+	NotifyFollows    bool   `env:"PREFERENCES_NOTIFY_FOLLOWS" envDefault:"true"`
+	NotifyNewsletter bool   `env:"PREFERENCES_NOTIFY_NEWSLETTER" envDefault:"false"`
+}
+
+// TenantConfig tells how the organization of a request is resolved, requests that don't name one belong to Default.
+type TenantConfig struct {
+	Default    string `env:"TENANT_DEFAULT" envDefault:"default"`
+	BaseDomain string `env:"TENANT_BASE_DOMAIN"`
+	Header     string `env:"TENANT_HEADER" envDefault:"X-Tenant"`
+}
+
+// RegistrationConfig tells who can register: anyone ("open"), only users with an invite ("invite_only") or nobody ("closed").
+// Invites are created by admins, and by other users if UserInvites is on.
+type RegistrationConfig struct {
+	Mode           string `env:"REGISTRATION_MODE" envDefault:"open"`
+	UserInvites    bool   `env:"REGISTRATION_USER_INVITES" envDefault:"false"`
+	InviteTTLHours int    `env:"INVITE_TTL_HOURS" envDefault:"168"`
+}
+
+const (
+	RegistrationOpen       = "open"
+	RegistrationInviteOnly = "invite_only"
+	RegistrationClosed     = "closed"
+)
+
+// LeaderboardConfig tells how long leaderboards are cached and how often boards requested
+// within the last IdleMinutes are rebuilt in the background.
+type LeaderboardConfig struct {
+	CacheTTLSeconds        int `env:"LEADERBOARD_CACHE_TTL_SECONDS" envDefault:"60"`
+	RefreshIntervalSeconds int `env:"LEADERBOARD_REFRESH_INTERVAL_SECONDS" envDefault:"30"`
+	IdleMinutes            int `env:"LEADERBOARD_IDLE_MINUTES" envDefault:"10"`
+	MaxLimit               int `env:"LEADERBOARD_MAX_LIMIT" envDefault:"100"`
+}
+
+// PIIConfig points to the keyring used to encrypt first and last names, they are stored in plaintext if it's empty.
+type PIIConfig struct {
+	KeyringFile string `env:"PII_KEYRING_FILE"`
+}
+
+var once sync.Once
+
+var configInstance *Config
+
+func GetConfig() *Config {
+	if configInstance == nil {
+		once.Do(func() {
+			fmt.Println("Creating config instance now.")
+
+			var cfg Config
+			var redis Redis
+			var ch ClickHouseConfig
+			var nickname NicknameConfig
+			var deletion DeletionConfig
+			var export ExportConfig
+			var importCfg ImportConfig
+			var batch BatchConfig
+			var block BlockConfig
+			var preferences PreferencesConfig
+			var tenant TenantConfig
+			var registration RegistrationConfig
+			var piiCfg PIIConfig
+			var leaderboard LeaderboardConfig
+
+			if err := env.Parse(&cfg); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&redis); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&ch); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&nickname); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&deletion); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&export); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&importCfg); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&batch); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&block); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&preferences); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&tenant); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&registration); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&piiCfg); err != nil {
+				log.Fatal(err)
+			}
+			if err := env.Parse(&leaderboard); err != nil {
+				log.Fatal(err)
+			}
+			cfg.Redis = redis
+			cfg.CH = ch
+			cfg.Nickname = nickname
+			cfg.Deletion = deletion
+			cfg.Export = export
+			cfg.Import = importCfg
+			cfg.Batch = batch
+			cfg.Block = block
+			cfg.Preferences = preferences
+			cfg.Tenant = tenant
+			cfg.Registration = registration
+			cfg.PII = piiCfg
+			cfg.Leaderboard = leaderboard
+
+			configInstance = &cfg
+		})
+
+	}
+
+	return configInstance
+}